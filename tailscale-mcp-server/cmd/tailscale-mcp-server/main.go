@@ -0,0 +1,8 @@
+// tailscale-mcp-server/cmd/tailscale-mcp-server/main.go
+package main
+
+import "github.com/hexsleeves/tailscale-mcp-server/internal/cli"
+
+func main() {
+	cli.Execute()
+}
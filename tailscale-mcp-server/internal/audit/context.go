@@ -0,0 +1,57 @@
+package audit
+
+import "context"
+
+type requestIDKey struct{}
+type clientInfoKey struct{}
+type sessionIDKey struct{}
+
+// WithRequestID attaches the in-flight request's ID to ctx, so a Record
+// built deeper in the call stack (e.g. ToolRegistry.ExecuteTool's audit
+// middleware) can be attributed to it without the tools package needing to
+// know about jsonrpc2 or mcp.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID attached via WithRequestID,
+// or "" if none was set (e.g. a notification, which carries no ID).
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// clientInfoValue is the value stored under clientInfoKey.
+type clientInfoValue struct {
+	name    string
+	version string
+}
+
+// WithClientInfo attaches the calling MCP client's name and version (as
+// recorded on the connection during initialize) to ctx.
+func WithClientInfo(ctx context.Context, name, version string) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, clientInfoValue{name: name, version: version})
+}
+
+// ClientInfoFromContext returns the client name and version attached via
+// WithClientInfo, or ("", "") if none was set.
+func ClientInfoFromContext(ctx context.Context) (name, version string) {
+	v, _ := ctx.Value(clientInfoKey{}).(clientInfoValue)
+	return v.name, v.version
+}
+
+// WithSessionID attaches the ID of the HTTP session a call arrived on to
+// ctx, mirroring WithRequestID, so log lines and audit records for a single
+// tool invocation can be correlated to both the MCP request and the
+// transport-level session it rode in on. Stdio mode has no sessions, so ctx
+// carries none there.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, id)
+}
+
+// SessionIDFromContext returns the session ID attached via WithSessionID, or
+// "" if none was set.
+func SessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
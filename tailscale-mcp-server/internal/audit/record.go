@@ -0,0 +1,76 @@
+// Package audit spools a structured JSON record of every tool invocation to
+// a rotating directory on disk, independently of internal/tailscale/audit
+// (which records Tailscale CLI command attempts). A background worker
+// drains a bounded channel so recording never blocks the calling tool
+// execution, mirroring a directory-upload-manager: files are rolled on
+// size/time thresholds and moved to a done/ subdirectory where a pluggable
+// Uploader can ship them off-host.
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Record is a single JSON line written for one tool invocation.
+type Record struct {
+	Timestamp     time.Time       `json:"timestamp"`
+	RequestID     string          `json:"requestId,omitempty"`
+	Tool          string          `json:"tool"`
+	Args          json.RawMessage `json:"args,omitempty"`
+	ResultSize    int             `json:"resultSize"`
+	Error         string          `json:"error,omitempty"`
+	ClientName    string          `json:"clientName,omitempty"`
+	ClientVersion string          `json:"clientVersion,omitempty"`
+}
+
+// sensitiveArgKeys are argument keys redacted by sanitizeArgs before a
+// Record ever reaches disk.
+var sensitiveArgKeys = map[string]bool{
+	"token":      true,
+	"authkey":    true,
+	"auth_key":   true,
+	"password":   true,
+	"secret":     true,
+	"apikey":     true,
+	"api_key":    true,
+	"key":        true,
+	"credential": true,
+}
+
+const redactedPlaceholder = "[redacted]"
+
+// sanitizeArgs redacts values under known-sensitive keys, leaving the rest
+// of args untouched. Arguments that aren't a JSON object (or fail to
+// unmarshal) pass through unchanged: there's nothing to redact by key, and
+// the raw shape is still useful for the audit trail.
+func sanitizeArgs(args json.RawMessage) json.RawMessage {
+	if len(args) == 0 {
+		return args
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(args, &fields); err != nil {
+		return args
+	}
+
+	redacted := false
+	for key := range fields {
+		if sensitiveArgKeys[strings.ToLower(key)] {
+			fields[key] = redactedJSON
+			redacted = true
+		}
+	}
+	if !redacted {
+		return args
+	}
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return args
+	}
+	return out
+}
+
+var redactedJSON = json.RawMessage(`"` + redactedPlaceholder + `"`)
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+)
+
+// defaultMaxBytes is the size a Spooler's active file rolls at when
+// MaxBytes is left unset, mirroring internal/tailscale/audit.FileSink.
+const defaultMaxBytes = 10 * 1024 * 1024
+
+// defaultMaxAge is how long a Spooler keeps writing to the same active
+// file, regardless of size, when MaxAge is left unset.
+const defaultMaxAge = time.Hour
+
+// queueCapacity bounds Record's channel buffer. Record drops (and logs) new
+// records once the queue is full rather than blocking the caller's tool
+// execution on a slow disk.
+const queueCapacity = 1024
+
+// Spooler writes one JSON line per Record to an active file under dir,
+// rolling it to dir/done/ once it exceeds MaxBytes or MaxAge, and handing
+// each rolled file to Uploader. A single background goroutine owns the
+// active file, so callers never need to synchronize writes themselves.
+type Spooler struct {
+	dir      string
+	doneDir  string
+	MaxBytes int64
+	MaxAge   time.Duration
+	uploader Uploader
+
+	queue chan Record
+	wg    sync.WaitGroup
+
+	mu       sync.Mutex
+	file     *os.File
+	path     string
+	size     int64
+	openedAt time.Time
+	seq      int
+}
+
+// NewSpooler creates dir and dir/done (if needed) and starts the background
+// worker. uploader may be nil, in which case rolled files are left in
+// done/ untouched (equivalent to NoopUploader).
+func NewSpooler(dir string, uploader Uploader) (*Spooler, error) {
+	doneDir := filepath.Join(dir, "done")
+	if err := os.MkdirAll(doneDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit spool directory: %w", err)
+	}
+	if uploader == nil {
+		uploader = NoopUploader{}
+	}
+
+	s := &Spooler{
+		dir:      dir,
+		doneDir:  doneDir,
+		MaxBytes: defaultMaxBytes,
+		MaxAge:   defaultMaxAge,
+		uploader: uploader,
+		queue:    make(chan Record, queueCapacity),
+	}
+
+	if err := s.openLocked(); err != nil {
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// Record enqueues rec for the background worker to write, redacting known-
+// sensitive argument keys first. It never blocks: once the queue is full,
+// the record is dropped and logged rather than stalling the tool call that
+// produced it.
+func (s *Spooler) Record(rec Record) {
+	rec.Args = sanitizeArgs(rec.Args)
+	select {
+	case s.queue <- rec:
+	default:
+		logger.Warn("audit spool queue full, dropping record", "tool", rec.Tool)
+	}
+}
+
+// Close drains the queue, flushes and rolls the active file, and stops the
+// background worker. Call it during server shutdown (e.g. from Cleanup) so
+// no audit record queued before SIGTERM is lost.
+func (s *Spooler) Close() error {
+	close(s.queue)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rollLocked()
+}
+
+func (s *Spooler) run() {
+	defer s.wg.Done()
+	for rec := range s.queue {
+		if err := s.write(rec); err != nil {
+			logger.Error("failed to write audit record", "error", err)
+		}
+	}
+}
+
+func (s *Spooler) write(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size+int64(len(data)) > s.maxBytes() || time.Since(s.openedAt) > s.maxAge() {
+		if err := s.rollLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *Spooler) maxBytes() int64 {
+	if s.MaxBytes <= 0 {
+		return defaultMaxBytes
+	}
+	return s.MaxBytes
+}
+
+func (s *Spooler) maxAge() time.Duration {
+	if s.MaxAge <= 0 {
+		return defaultMaxAge
+	}
+	return s.MaxAge
+}
+
+// openLocked opens a fresh active file. Callers must hold s.mu (or be
+// NewSpooler, before the worker goroutine starts).
+func (s *Spooler) openLocked() error {
+	s.seq++
+	name := fmt.Sprintf("audit-%d-%d.jsonl", time.Now().UnixNano(), s.seq)
+	path := filepath.Join(s.dir, name)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit spool file %s: %w", path, err)
+	}
+
+	s.file = f
+	s.path = path
+	s.size = 0
+	s.openedAt = time.Now()
+	return nil
+}
+
+// rollLocked closes the active file, moves it to done/, hands it to
+// uploader, and opens a new active file in its place. Callers must hold
+// s.mu. A no-op if nothing has been written to the current file yet.
+func (s *Spooler) rollLocked() error {
+	if s.file == nil {
+		return nil
+	}
+	if s.size == 0 {
+		return nil
+	}
+
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit spool file: %w", err)
+	}
+
+	rolledPath := filepath.Join(s.doneDir, filepath.Base(s.path))
+	if err := os.Rename(s.path, rolledPath); err != nil {
+		return fmt.Errorf("failed to move audit spool file to done: %w", err)
+	}
+
+	if err := s.uploader.Upload(rolledPath); err != nil {
+		logger.Warn("audit file upload failed, leaving it in done/", "path", rolledPath, "error", err)
+	}
+
+	return s.openLocked()
+}
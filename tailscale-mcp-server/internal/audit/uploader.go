@@ -0,0 +1,40 @@
+package audit
+
+import "errors"
+
+var (
+	errUploaderNotConfigured = errors.New("audit: S3Uploader has no bucket configured")
+	errS3NotImplemented      = errors.New("audit: S3Uploader is a stub; no S3 client is wired up yet")
+)
+
+// Uploader ships a completed (rotated) audit log file off-host. Spooler
+// calls it with the path of each file it moves into done/, after the move
+// succeeds, so a failed upload never loses the file from local disk.
+type Uploader interface {
+	Upload(path string) error
+}
+
+// NoopUploader leaves completed files in done/ and does nothing else; it's
+// the default when no remote destination is configured.
+type NoopUploader struct{}
+
+func (NoopUploader) Upload(path string) error { return nil }
+
+// S3Uploader is a stub for shipping completed audit files to an S3-
+// compatible bucket. It's not wired up to any SDK yet: Upload only
+// validates that it has enough configuration to attempt a real upload, so
+// callers can start threading an S3Uploader through config today without
+// this package taking on an AWS SDK dependency before it's needed.
+type S3Uploader struct {
+	Bucket string
+	Prefix string
+}
+
+// Upload is unimplemented; it exists so callers can wire S3Uploader in now
+// and get a clear error instead of a silent no-op once it's configured.
+func (u S3Uploader) Upload(path string) error {
+	if u.Bucket == "" {
+		return errUploaderNotConfigured
+	}
+	return errS3NotImplemented
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
+)
+
+var migrateOutput string
+
+// configCmd groups config-file-related helper subcommands.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the declarative config file",
+	Long: `Manage the declarative config file and versioned config bundles.
+
+TAILSCALE_MCP_CONFIG points at a single alpha0 config file (see "config
+migrate"), loaded in place of the env-var defaults.
+
+MCP_CONFIG_DIR instead points at a directory of cap-<N>.hujson files, one
+per capability version; Load picks the highest-numbered file whose N does
+not exceed this build's BuiltinCapability and merges it over the env-var
+defaults (explicit env vars still override it). This lets an operator
+ship a forward-compatible config bundle, e.g. via a Kubernetes ConfigMap,
+the way Tailscale's containerboot does with its own versioned config.`,
+}
+
+// configMigrateCmd represents the config migrate command
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Emit an alpha0 config file equivalent to the current environment",
+	Long: `Reads the current environment variables (the same ones serve reads via
+config.Load) and writes the equivalent declarative config file, for
+transitioning from env-var configuration to TAILSCALE_MCP_CONFIG.
+
+By default the file is printed to stdout; use --output to write it to a
+path instead.`,
+	RunE: runConfigMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configMigrateCmd)
+
+	configMigrateCmd.Flags().StringVarP(&migrateOutput, "output", "o", "", "Write the config file here instead of stdout")
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration from environment: %w", err)
+	}
+
+	out, err := config.MarshalFile(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to render config file: %w", err)
+	}
+
+	if migrateOutput == "" {
+		_, err = cmd.OutOrStdout().Write(out)
+		return err
+	}
+
+	if err := os.WriteFile(migrateOutput, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote config file to %s\n", migrateOutput)
+	return nil
+}
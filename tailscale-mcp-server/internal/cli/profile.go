@@ -0,0 +1,169 @@
+// tailscale-mcp-server/internal/cli/profile.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
+)
+
+var profileAddFlags struct {
+	loginServer       string
+	useHeadscale      bool
+	apiKey            string
+	tailnet           string
+	apiBaseURL        string
+	headscaleAddr     string
+	headscaleAPIToken string
+}
+
+// profileCmd groups control-server profile management, letting a single
+// installation switch between Tailscale's own SaaS API and one or more
+// self-hosted Headscale servers without env-var juggling.
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage Tailscale/Headscale control-server profiles",
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Save a new control-server profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileAdd,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a saved profile active",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileUse,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved profiles",
+	RunE:  runProfileList,
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Delete a saved profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileAddCmd, profileUseCmd, profileListCmd, profileRemoveCmd)
+
+	profileAddCmd.Flags().StringVar(&profileAddFlags.loginServer, "login-server", "", "--login-server value to pass to `tailscale up`")
+	profileAddCmd.Flags().BoolVar(&profileAddFlags.useHeadscale, "headscale", false, "manage this profile's nodes through a self-hosted Headscale server instead of the Tailscale API")
+	profileAddCmd.Flags().StringVar(&profileAddFlags.apiKey, "api-key", "", "Tailscale API key (ignored with --headscale)")
+	profileAddCmd.Flags().StringVar(&profileAddFlags.tailnet, "tailnet", "", "Tailnet name (ignored with --headscale)")
+	profileAddCmd.Flags().StringVar(&profileAddFlags.apiBaseURL, "api-base-url", "", "Tailscale API base URL (ignored with --headscale)")
+	profileAddCmd.Flags().StringVar(&profileAddFlags.headscaleAddr, "headscale-addr", "", "Headscale server base URL (requires --headscale)")
+	profileAddCmd.Flags().StringVar(&profileAddFlags.headscaleAPIToken, "headscale-api-token", "", "Headscale API token (requires --headscale)")
+}
+
+func runProfileAdd(cmd *cobra.Command, args []string) error {
+	path, err := config.ProfilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	ps, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+
+	ps.Upsert(config.ControlProfile{
+		Name:              args[0],
+		LoginServer:       profileAddFlags.loginServer,
+		UseHeadscale:      profileAddFlags.useHeadscale,
+		APIKey:            profileAddFlags.apiKey,
+		Tailnet:           profileAddFlags.tailnet,
+		APIBaseURL:        profileAddFlags.apiBaseURL,
+		HeadscaleAddr:     profileAddFlags.headscaleAddr,
+		HeadscaleAPIToken: profileAddFlags.headscaleAPIToken,
+	})
+
+	if err := ps.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved profile %q to %s\n", args[0], path)
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	path, err := config.ProfilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	ps, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+
+	if err := ps.SetActive(args[0]); err != nil {
+		return err
+	}
+	if err := ps.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Active profile is now %q\n", args[0])
+	return nil
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	path, err := config.ProfilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	ps, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+
+	if len(ps.Profiles) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No profiles saved (%s)\n", path)
+		return nil
+	}
+
+	for _, p := range ps.Profiles {
+		marker := "  "
+		if p.Name == ps.Active {
+			marker = "* "
+		}
+		kind := "tailscale"
+		if p.UseHeadscale {
+			kind = "headscale"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s%s (%s)\n", marker, p.Name, kind)
+	}
+	return nil
+}
+
+func runProfileRemove(cmd *cobra.Command, args []string) error {
+	path, err := config.ProfilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	ps, err := config.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+
+	if !ps.Remove(args[0]) {
+		return fmt.Errorf("no such profile: %s", args[0])
+	}
+	if err := ps.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed profile %q\n", args[0])
+	return nil
+}
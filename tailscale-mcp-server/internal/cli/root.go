@@ -26,8 +26,12 @@ Environment Variables:
   TAILSCALE_API_KEY        Tailscale API key (required for API operations)
   TAILSCALE_TAILNET        Tailnet name (required for API operations)
   TAILSCALE_API_BASE_URL   Custom API base URL (optional)
+  TAILSCALE_LOGIN_SERVER   Control server for ` + "`tailscale up --login-server`" + `, e.g. a Headscale address (optional)
   LOG_LEVEL                Logging level: 0=debug, 1=info, 2=warn, 3=error (default: 1)
-  MCP_SERVER_LOG_FILE      Log file path (optional)`,
+  MCP_SERVER_LOG_FILE      Log file path (optional)
+  MCP_CONFIG_DIR           Directory of versioned cap-<N>.hujson config bundles (optional, see "config --help")
+
+Run "tailscale-mcp-server profile --help" to manage saved Tailscale/Headscale control-server profiles.`,
 	// Default behavior: show help if no subcommand
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := cmd.Help(); err != nil {
@@ -36,8 +40,15 @@ Environment Variables:
 	},
 }
 
-// Execute adds all child commands to the root command and sets flags appropriately.
+// Execute adds all child commands to the root command and sets flags
+// appropriately. Cobra's own defaults already send help/usage/version text
+// to os.Stdout and only reserve os.Stderr for logger.Error/Warn below; this
+// just makes that split explicit rather than relying on OutOrStdout's
+// implicit fallback.
 func Execute() {
+	rootCmd.SetOut(os.Stdout)
+	rootCmd.SetErr(os.Stderr)
+
 	if err := rootCmd.Execute(); err != nil {
 		logger.Error("Command execution failed", "error", err)
 		os.Exit(1)
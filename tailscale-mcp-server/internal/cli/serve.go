@@ -14,12 +14,14 @@ import (
 	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/server"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/authz"
 	"github.com/hexsleeves/tailscale-mcp-server/version"
 )
 
 var (
 	serverMode    string
 	httpPort      int
+	backendMode   string
 	cachedVersion string
 )
 
@@ -35,6 +37,8 @@ allowing automated network management through standardized interfaces.
 Modes:
   stdio  - Standard input/output communication (default, for MCP clients)
   http   - HTTP server mode (for testing and development)
+  tsnet  - Serve the MCP transport from an embedded tsnet node's own
+           tailnet-only listener, reachable only by authorized peers
 
 Examples:
   # Start in stdio mode (default)
@@ -54,21 +58,27 @@ func init() {
 	// Command-specific flags
 	serveCmd.Flags().StringVarP(&serverMode, "mode", "m", "stdio", "Server mode (stdio|http)")
 	serveCmd.Flags().IntVarP(&httpPort, "port", "p", 8080, "HTTP server port (only used in http mode)")
+	serveCmd.Flags().StringVar(&backendMode, "backend", "auto", "Tailscale backend (cli|localapi|auto)")
 
 	// Flag validation
 	serveCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		// Validate server mode
-		if serverMode != "stdio" && serverMode != "http" {
-			return fmt.Errorf("invalid server mode: must be 'stdio' or 'http'")
+		if serverMode != "stdio" && serverMode != "http" && serverMode != "tsnet" {
+			return fmt.Errorf("invalid server mode: must be 'stdio', 'http', or 'tsnet'")
 		}
 
 		// Validate port range
-		if serverMode == "http" {
+		if serverMode == "http" || serverMode == "tsnet" {
 			if httpPort < 1 || httpPort > 65535 {
 				return fmt.Errorf("invalid port: must be between 1 and 65535")
 			}
 		}
 
+		// Validate backend mode
+		if backendMode != "cli" && backendMode != "localapi" && backendMode != "auto" {
+			return fmt.Errorf("invalid backend: must be 'cli', 'localapi', or 'auto'")
+		}
+
 		return nil
 	}
 
@@ -84,6 +94,11 @@ func runServer(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Command-line flag overrides configuration/env defaults
+	if cmd.Flags().Changed("backend") {
+		cfg.TailscaleBackend = backendMode
+	}
+
 	// Initialize logger with verbose flag consideration
 	logLevel := cfg.LogLevel
 	if verbose {
@@ -95,8 +110,20 @@ func runServer(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Create server
-	tailscaleMCPServer, err := server.New(cfg)
+	// Create server. Only HTTP and tsnet modes sit behind a tailnet identity
+	// that means anything, so only they ever enforce a tool policy file;
+	// stdio's single local client is implicitly trusted.
+	var serverOpts []server.ServerOption
+	if (serverMode == "http" || serverMode == "tsnet") && cfg.ToolPolicyFile != "" {
+		policy, err := authz.LoadPolicyFile(cfg.ToolPolicyFile)
+		if err != nil {
+			logger.Error("Failed to load tool policy file", "path", cfg.ToolPolicyFile, "error", err)
+			os.Exit(1)
+		}
+		serverOpts = append(serverOpts, server.WithToolAuthorizer(authz.NewPolicyAuthorizer(policy)))
+	}
+
+	tailscaleMCPServer, err := server.New(cfg, serverOpts...)
 	if err != nil {
 		logger.Error("Failed to create server", "error", err)
 		os.Exit(1)
@@ -106,14 +133,54 @@ func runServer(cmd *cobra.Command, args []string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal, 1)
+	// shutdownDone is closed once the graceful shutdown below (draining
+	// in-flight tool calls, then tailscaleMCPServer.Shutdown) has actually
+	// finished, as opposed to ctx merely having been canceled. A second
+	// SIGINT/SIGTERM before that happens escalates to an immediate exit
+	// instead of waiting out ShutdownTimeout.
+	shutdownDone := make(chan struct{})
+
+	sigChan := make(chan os.Signal, 2)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(sigChan)
 
 	go func() {
 		<-sigChan
-		logger.Info("Received shutdown signal")
+		logger.Info("Received shutdown signal, draining in-flight tool calls", "shutdown_timeout", cfg.ShutdownTimeout)
 		cancel()
+
+		select {
+		case <-sigChan:
+			logger.Warn("Received second shutdown signal, forcing immediate exit")
+			os.Exit(1)
+		case <-shutdownDone:
+		}
+	}()
+
+	// SIGHUP reopens log file sinks (logrotate support); SIGUSR1/SIGUSR2
+	// toggle debug verbosity on and off without a restart. Unlike the
+	// shutdown signals above, these can recur for the life of the process.
+	adminSigChan := make(chan os.Signal, 1)
+	signal.Notify(adminSigChan, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(adminSigChan)
+
+	go func() {
+		for sig := range adminSigChan {
+			switch sig {
+			case syscall.SIGHUP:
+				if err := logger.ReopenFileSinks(); err != nil {
+					logger.Error("Failed to reopen log file sinks", "error", err)
+				} else {
+					logger.Info("Reopened log file sinks")
+				}
+			case syscall.SIGUSR1:
+				logger.EnableDebug()
+				logger.Info("Debug logging enabled via SIGUSR1")
+			case syscall.SIGUSR2:
+				logger.RestoreConfiguredLevel()
+				logger.Info("Restored configured log level via SIGUSR2")
+			}
+		}
 	}()
 
 	// Start server
@@ -128,11 +195,20 @@ func runServer(cmd *cobra.Command, args []string) {
 		serverErr = tailscaleMCPServer.StartStdio(ctx)
 	case "http":
 		serverErr = tailscaleMCPServer.StartHTTP(ctx, httpPort)
+	case "tsnet":
+		serverErr = tailscaleMCPServer.StartTsnet(ctx, httpPort)
 	default:
-		logger.Error("Invalid server mode", "mode", serverMode, "valid_modes", []string{"stdio", "http"})
+		logger.Error("Invalid server mode", "mode", serverMode, "valid_modes", []string{"stdio", "http", "tsnet"})
 		os.Exit(1)
 	}
 
+	// Flush the tool registry, audit spooler, and tsnet node regardless of
+	// how the server stopped, so a SIGTERM never drops queued audit records.
+	if err := tailscaleMCPServer.Shutdown(context.Background()); err != nil {
+		logger.Error("Error during server shutdown", "error", err)
+	}
+	close(shutdownDone)
+
 	// Check if the error is due to context cancellation (graceful shutdown)
 	if serverErr != nil {
 		if serverErr == context.Canceled || errors.Is(serverErr, http.ErrServerClosed) {
@@ -21,15 +21,16 @@ var versionCmd = &cobra.Command{
 
 This is useful for debugging and support purposes.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Tailscale MCP Server %s\n", version.Info())
-		fmt.Printf("Built with %s\n", runtime.Version())
-		fmt.Printf("Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Tailscale MCP Server %s\n", version.Info())
+		fmt.Fprintf(out, "Built with %s\n", runtime.Version())
+		fmt.Fprintf(out, "Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
 
 		if verbose {
 			// Additional verbose information
-			fmt.Printf("Go max procs: %d\n", runtime.GOMAXPROCS(0))
-			fmt.Printf("Go routines: %d\n", runtime.NumGoroutine())
-			fmt.Printf("Go compiler: %s\n", runtime.Compiler)
+			fmt.Fprintf(out, "Go max procs: %d\n", runtime.GOMAXPROCS(0))
+			fmt.Fprintf(out, "Go routines: %d\n", runtime.NumGoroutine())
+			fmt.Fprintf(out, "Go compiler: %s\n", runtime.Compiler)
 		}
 	},
 }
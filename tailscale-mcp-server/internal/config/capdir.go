@@ -0,0 +1,195 @@
+// tailscale-mcp-server/internal/config/capdir.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/tailscale/hujson"
+)
+
+// BuiltinCapability is this build's capability version. loadCapabilityDir
+// picks the highest-numbered cap-<N>.hujson file whose N does not exceed
+// this, the same forward-compatibility trick containerboot's versioned
+// config uses: an operator can roll out a config bundle (e.g. a Kubernetes
+// ConfigMap holding several cap-<N>.hujson files) ahead of an upgrade, and
+// older running binaries keep picking the highest file they understand
+// until they're upgraded past it. Bump this when a new cap-<N>.hujson
+// field is added that older builds must not see.
+const BuiltinCapability = 1
+
+// capFilePattern matches a versioned config bundle file: cap-<N>.hujson,
+// where N is the capability version it requires.
+var capFilePattern = regexp.MustCompile(`^cap-(\d+)\.hujson$`)
+
+// loadCapabilityDir reads the highest-numbered cap-<N>.hujson file under
+// dir whose N is <= BuiltinCapability, and returns the fileConfig it
+// contains. It returns (nil, nil), not an error, when dir has no matching
+// file at all or only files this build's capability is too old for - the
+// same "absent means unconfigured" convention LoadProfiles uses for a
+// missing profiles file - so a forward-compatible bundle never breaks an
+// older binary that simply hasn't caught up yet.
+func loadCapabilityDir(dir string) (*fileConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read capability config directory: %w", err)
+	}
+
+	bestCap := -1
+	bestName := ""
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := capFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		capN, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		if capN > BuiltinCapability {
+			continue
+		}
+		if capN > bestCap {
+			bestCap = capN
+			bestName = entry.Name()
+		}
+	}
+
+	if bestName == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, bestName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", bestName, err)
+	}
+
+	standardized, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s as HuJSON: %w", bestName, err)
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(standardized, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", bestName, err)
+	}
+
+	return &fc, nil
+}
+
+// capabilityVersions returns the sorted N values of every cap-<N>.hujson
+// file loadCapabilityDir considered, used only for diagnostics (e.g. the
+// "no compatible version" log message in Load).
+func capabilityVersions(dir string) []int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var versions []int
+	for _, entry := range entries {
+		if m := capFilePattern.FindStringSubmatch(entry.Name()); m != nil {
+			if capN, err := strconv.Atoi(m[1]); err == nil {
+				versions = append(versions, capN)
+			}
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// mergeFileConfig overlays fc's non-zero fields onto cfg, the same "only
+// override what's actually set" semantics applyEnvOverrides and
+// ControlProfile.ApplyTo use. Used to layer a capability config bundle
+// underneath explicit env vars rather than replacing cfg outright the way
+// TAILSCALE_MCP_CONFIG's full file load does. The Tsnet* fields,
+// AutoFailover, ShutdownTimeout and FailoverPollInterval are not merged.
+func mergeFileConfig(cfg *Config, fc fileConfig) {
+	if fc.Tools != (ToolsConfig{}) {
+		cfg.Tools = fc.Tools
+	}
+
+	if fc.TailscaleAPIKey != "" {
+		cfg.TailscaleAPIKey = fc.TailscaleAPIKey
+	}
+	if fc.TailscaleTailnet != "" {
+		cfg.TailscaleTailnet = fc.TailscaleTailnet
+	}
+	if fc.TailscaleAPIBaseURL != "" {
+		cfg.TailscaleAPIBaseURL = fc.TailscaleAPIBaseURL
+	}
+	if fc.TailscaleOAuthClientID != "" {
+		cfg.TailscaleOAuthClientID = fc.TailscaleOAuthClientID
+	}
+	if fc.TailscaleOAuthClientSecret != "" {
+		cfg.TailscaleOAuthClientSecret = fc.TailscaleOAuthClientSecret
+	}
+	if fc.TailscaleOAuthScopes != "" {
+		cfg.TailscaleOAuthScopes = fc.TailscaleOAuthScopes
+	}
+
+	if fc.LogLevel != 0 {
+		cfg.LogLevel = fc.LogLevel
+	}
+	if fc.LogFile != "" {
+		cfg.LogFile = fc.LogFile
+	}
+	if fc.LogFormat != "" {
+		cfg.LogFormat = fc.LogFormat
+	}
+	if fc.AuditDir != "" {
+		cfg.AuditDir = fc.AuditDir
+	}
+	if fc.ToolPolicyFile != "" {
+		cfg.ToolPolicyFile = fc.ToolPolicyFile
+	}
+
+	if fc.ServerMode != "" {
+		cfg.ServerMode = fc.ServerMode
+	}
+	if fc.TailscaleBackend != "" {
+		cfg.TailscaleBackend = fc.TailscaleBackend
+	}
+	if fc.TailscaleLoginServer != "" {
+		cfg.TailscaleLoginServer = fc.TailscaleLoginServer
+	}
+
+	if fc.HTTPBearerToken != "" {
+		cfg.HTTPBearerToken = fc.HTTPBearerToken
+	}
+	if fc.HTTPAPIKeyHeader != "" {
+		cfg.HTTPAPIKeyHeader = fc.HTTPAPIKeyHeader
+	}
+	if fc.HTTPAPIKeyFile != "" {
+		cfg.HTTPAPIKeyFile = fc.HTTPAPIKeyFile
+	}
+	if fc.HTTPClientCAFile != "" {
+		cfg.HTTPClientCAFile = fc.HTTPClientCAFile
+	}
+	if fc.HTTPTLSCertFile != "" {
+		cfg.HTTPTLSCertFile = fc.HTTPTLSCertFile
+	}
+	if fc.HTTPTLSKeyFile != "" {
+		cfg.HTTPTLSKeyFile = fc.HTTPTLSKeyFile
+	}
+
+	if fc.WebUIEnabled {
+		cfg.WebUIEnabled = fc.WebUIEnabled
+	}
+	if fc.WebUIBind != "" {
+		cfg.WebUIBind = fc.WebUIBind
+	}
+	if fc.WebUIMode != "" {
+		cfg.WebUIMode = fc.WebUIMode
+	}
+}
@@ -5,25 +5,194 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration values for the server
 type Config struct {
+	// Version identifies the config file schema this Config was loaded
+	// from (see LoadFile), currently always ConfigFileVersion ("alpha0")
+	// when set. Empty when the config came from env vars only.
+	Version string `json:"version,omitempty"`
+
+	// locked mirrors a config file's top-level Locked: true. Once set,
+	// MustSet refuses further programmatic mutation and Load skips
+	// applying env var overrides on top of the file.
+	locked bool
+
+	// Tools holds per-tool overrides loaded from a config file (e.g.
+	// tools.admin.enabled, tools.exit_node.auto). Zero value means no
+	// overrides were set.
+	Tools ToolsConfig `json:"tools,omitempty"`
+
 	// Tailscale configuration
 	TailscaleAPIKey     string `json:"tailscale_api_key"`
 	TailscaleTailnet    string `json:"tailscale_tailnet"`
 	TailscaleAPIBaseURL string `json:"tailscale_api_base_url"`
 
+	// TailscaleOAuthClientID and TailscaleOAuthClientSecret configure
+	// OAuth2 client-credentials authentication against the Tailscale API,
+	// an alternative to a static TailscaleAPIKey. Tailscale's own operator
+	// tooling uses this flow to mint short-lived, auto-refreshing access
+	// tokens instead of a long-lived personal API key. Both must be set
+	// together; Validate rejects setting them alongside TailscaleAPIKey.
+	TailscaleOAuthClientID     string `json:"-"`
+	TailscaleOAuthClientSecret string `json:"-"`
+
+	// TailscaleOAuthScopes is a comma-separated list of OAuth scopes to
+	// request (e.g. "devices:core,devices:routes"), passed to the token
+	// endpoint alongside the client credentials above.
+	TailscaleOAuthScopes string `json:"tailscale_oauth_scopes"`
+
+	// TailscaleAPIRateLimit and TailscaleAPIRateBurst configure the token
+	// bucket APIClient uses to throttle its own outbound requests to
+	// TailscaleAPIBaseURL, smoothing out bursts from tools that fan out many
+	// calls at once instead of letting them all hit the API back-to-back.
+	TailscaleAPIRateLimit float64 `json:"tailscale_api_rate_limit"`
+	TailscaleAPIRateBurst int     `json:"tailscale_api_rate_burst"`
+
+	// TailscaleAPICircuitThreshold and TailscaleAPICircuitOpenDuration
+	// configure APIClient's circuit breaker: after this many consecutive
+	// 5xx/transport failures, it stops making requests for this long,
+	// failing them immediately with "circuit open" instead of letting every
+	// caller hang on a backend that's already down.
+	TailscaleAPICircuitThreshold    int           `json:"tailscale_api_circuit_threshold"`
+	TailscaleAPICircuitOpenDuration time.Duration `json:"tailscale_api_circuit_open_duration"`
+
+	// WatchDebounce bounds how long Client.Watch waits for a peer's
+	// added/removed/online state to stop changing before emitting an event
+	// for it, so a peer briefly flapping online/offline doesn't produce a
+	// burst of notifications faster than a subscriber could usefully act on
+	// them. Defaults to 2s if zero.
+	WatchDebounce time.Duration `json:"watch_debounce"`
+
 	// Logging configuration
 	LogLevel int    `json:"log_level"`
 	LogFile  string `json:"log_file"`
 
+	// LogFormat selects the structured log encoding: "console" (default,
+	// human-readable) or "json" (one JSON object per line, for log
+	// aggregators). Read directly from LOG_FORMAT by logger.Initialize;
+	// kept here too so Config.SanitizedCopy/logging reflects the whole
+	// configuration an operator set.
+	LogFormat string `json:"log_format"`
+
+	// AuditDir enables the per-tool-call audit spool (internal/audit) when
+	// set, writing one rotating JSON-lines file per active spool period
+	// under this directory. Audit spooling is off when empty.
+	AuditDir string `json:"audit_dir"`
+
+	// ToolPolicyFile points at a tools.policy.hujson file mapping tailnet
+	// identities to the tools they may call (see internal/tools/authz).
+	// Only meaningful in HTTP and tsnet modes; stdio mode never enforces it. Empty
+	// means no policy is enforced (every call is allowed).
+	ToolPolicyFile string `json:"tool_policy_file"`
+
 	// Server configuration
 	ServerMode string `json:"server_mode"`
+
+	// HTTPBearerToken, if set, is compared in constant time against every
+	// HTTP request's Authorization: Bearer header (see server.AuthConfig).
+	// Only meaningful in HTTP and tsnet modes.
+	HTTPBearerToken string `json:"-"`
+
+	// HTTPAPIKeyHeader names the header an API key is read from, when
+	// HTTPAPIKeyFile is set. Defaults to "X-API-Key" if empty.
+	HTTPAPIKeyHeader string `json:"http_api_key_header"`
+
+	// HTTPAPIKeyFile points at a file of valid API keys, one per line,
+	// hot-reloaded whenever its mtime changes.
+	HTTPAPIKeyFile string `json:"http_api_key_file"`
+
+	// HTTPClientCAFile, HTTPTLSCertFile and HTTPTLSKeyFile, if all three
+	// are set, turn on mutual TLS for the HTTP server: the client CA pool
+	// the TLS handshake verifies peers against, and the server's own
+	// certificate and key.
+	HTTPClientCAFile string `json:"http_client_ca_file"`
+	HTTPTLSCertFile  string `json:"http_tls_cert_file"`
+	HTTPTLSKeyFile   string `json:"http_tls_key_file"`
+
+	// TailscaleWebhookSecret, if set, turns on the /webhooks/tailscale HTTP
+	// endpoint (only meaningful in HTTP/tsnet mode): deliveries are
+	// verified against this secret (see internal/webhooks) and republished
+	// as notifications/tailscale/webhook to every connected MCP client.
+	// Empty leaves the endpoint unregistered.
+	TailscaleWebhookSecret string `json:"-"`
+
+	// ShutdownTimeout bounds how long graceful shutdown waits for in-flight
+	// Tool.Execute calls to finish (via ToolRegistry.Drain) before
+	// force-canceling survivors.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+
+	// TailscaleBackend selects how the server talks to the local Tailscale
+	// node: "cli" (shell out to the tailscale binary), "localapi" (speak
+	// HTTP to tailscaled's local socket), or "auto" (prefer localapi when
+	// its socket is reachable, falling back to cli).
+	TailscaleBackend string `json:"tailscale_backend"`
+
+	// TailscaleLoginServer, when set, is auto-injected as --login-server
+	// into every TailscaleCLI.Up call that doesn't specify its own (see
+	// server.New and TailscaleCLI.SetDefaultLoginServer). Normally
+	// populated from an active Headscale ControlProfile (see profile.go)
+	// rather than set directly, so a self-hosted tailnet doesn't need
+	// --login-server passed by hand on every up call.
+	TailscaleLoginServer string `json:"tailscale_login_server,omitempty"`
+
+	// TsnetEnabled turns on the embedded tsnet node, making the server
+	// itself a peer on the tailnet and enabling the tailnet_* tools.
+	TsnetEnabled bool `json:"tsnet_enabled"`
+
+	// TsnetHostname is the name the embedded node advertises on the
+	// tailnet. Required when TsnetEnabled is true.
+	TsnetHostname string `json:"tsnet_hostname"`
+
+	// TsnetAuthKey authenticates the embedded node non-interactively. If
+	// empty, tsnet logs an interactive login URL on first run.
+	TsnetAuthKey string `json:"tsnet_auth_key"`
+
+	// TsnetStateDir is where the embedded node persists state between
+	// restarts. If empty, tsnet picks a default under the user's state
+	// directory.
+	TsnetStateDir string `json:"tsnet_state_dir"`
+
+	// TsnetControlURL overrides the control plane the embedded node joins,
+	// e.g. to point at a Headscale instance or a test fake.
+	TsnetControlURL string `json:"tsnet_control_url"`
+
+	// TsnetEphemeral marks the embedded node for automatic removal from
+	// the tailnet once it disconnects.
+	TsnetEphemeral bool `json:"tsnet_ephemeral"`
+
+	// AutoFailover enables the HA subnet router monitor: when a route
+	// group's primary device goes unhealthy, the server automatically
+	// enables the next healthy peer's routes and disables the failed
+	// primary's, instead of only reporting the condition via the
+	// routing tool's simulate_failover action.
+	AutoFailover bool `json:"auto_failover"`
+
+	// FailoverPollInterval controls how often the HA subnet router
+	// monitor re-checks device health when AutoFailover is enabled.
+	FailoverPollInterval time.Duration `json:"failover_poll_interval"`
+
+	// WebUIEnabled turns on the local browser UI (internal/web) alongside
+	// whichever ServerMode is running.
+	WebUIEnabled bool `json:"web_ui_enabled"`
+
+	// WebUIBind is the address the web UI listens on; defaults to
+	// web.DefaultBind ("127.0.0.1:5252") if empty. When an embedded tsnet
+	// node is running (ServerMode "tsnet" or TsnetEnabled), only its port is
+	// used: the web UI listens on that port of the node's tailnet-only
+	// socket, and the host part is ignored.
+	WebUIBind string `json:"web_ui_bind"`
+
+	// WebUIMode is "read-only" or "manage" (web.Mode); defaults to
+	// "read-only" if empty.
+	WebUIMode string `json:"web_ui_mode"`
 }
 
 // ValidationError represents a configuration validation error
@@ -40,19 +209,69 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables, or from a config
+// file if TAILSCALE_MCP_CONFIG is set (see LoadFile). Env vars only
+// override file values when the file wasn't loaded with locked: true.
 func Load() (*Config, error) {
 	// Try to load .env file if it exists (ignore errors)
 	_ = godotenv.Load()
 
 	cfg := &Config{
 		// Default values
-		TailscaleAPIBaseURL: "https://api.tailscale.com",
-		LogLevel:            1, // INFO level
-		ServerMode:          "stdio",
+		TailscaleAPIBaseURL:             "https://api.tailscale.com",
+		LogLevel:                        1, // INFO level
+		ServerMode:                      "stdio",
+		TailscaleBackend:                "auto",
+		ShutdownTimeout:                 30 * time.Second,
+		FailoverPollInterval:            30 * time.Second,
+		TailscaleAPIRateLimit:           10,
+		TailscaleAPIRateBurst:           20,
+		TailscaleAPICircuitThreshold:    5,
+		TailscaleAPICircuitOpenDuration: 30 * time.Second,
+		WatchDebounce:                   2 * time.Second,
+	}
+
+	if path := strings.TrimSpace(os.Getenv("TAILSCALE_MCP_CONFIG")); path != "" {
+		fileCfg, err := LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file %s: %w", path, err)
+		}
+		cfg = fileCfg
+	} else if dir := strings.TrimSpace(os.Getenv("MCP_CONFIG_DIR")); dir != "" {
+		fc, err := loadCapabilityDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load capability config directory %s: %w", dir, err)
+		}
+		if fc != nil {
+			mergeFileConfig(cfg, *fc)
+		} else if versions := capabilityVersions(dir); len(versions) > 0 {
+			// Files are present, just none this build's BuiltinCapability is
+			// new enough to use - not an error (a newer binary will pick one
+			// up once it's rolled out), but worth a note in the logs.
+			fmt.Fprintf(os.Stderr, "config: %s has no cap-<N>.hujson file with N <= %d (found %v); ignoring\n", dir, BuiltinCapability, versions)
+		}
+	}
+
+	if !cfg.locked {
+		applyEnvOverrides(cfg)
+
+		profile, err := LoadActiveControlProfile()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load active control profile: %w", err)
+		}
+		if profile != nil {
+			profile.ApplyTo(cfg)
+		}
 	}
 
-	// Load from environment variables
+	return cfg, nil
+}
+
+// applyEnvOverrides reads the same environment variables Load has always
+// supported, overwriting any value already set on cfg (by defaults or by
+// a config file loaded via TAILSCALE_MCP_CONFIG) when the corresponding
+// env var is non-empty.
+func applyEnvOverrides(cfg *Config) {
 	if apiKey := strings.TrimSpace(os.Getenv("TAILSCALE_API_KEY")); apiKey != "" {
 		cfg.TailscaleAPIKey = apiKey
 	}
@@ -65,6 +284,48 @@ func Load() (*Config, error) {
 		cfg.TailscaleAPIBaseURL = baseURL
 	}
 
+	if clientID := strings.TrimSpace(os.Getenv("TAILSCALE_OAUTH_CLIENT_ID")); clientID != "" {
+		cfg.TailscaleOAuthClientID = clientID
+	}
+
+	if clientSecret := strings.TrimSpace(os.Getenv("TAILSCALE_OAUTH_CLIENT_SECRET")); clientSecret != "" {
+		cfg.TailscaleOAuthClientSecret = clientSecret
+	}
+
+	if scopes := strings.TrimSpace(os.Getenv("TAILSCALE_OAUTH_SCOPES")); scopes != "" {
+		cfg.TailscaleOAuthScopes = scopes
+	}
+
+	if rateLimit := strings.TrimSpace(os.Getenv("TS_MCP_API_RATE_LIMIT")); rateLimit != "" {
+		if v, err := strconv.ParseFloat(rateLimit, 64); err == nil && v > 0 {
+			cfg.TailscaleAPIRateLimit = v
+		}
+	}
+
+	if rateBurst := strings.TrimSpace(os.Getenv("TS_MCP_API_RATE_BURST")); rateBurst != "" {
+		if v, err := strconv.Atoi(rateBurst); err == nil && v > 0 {
+			cfg.TailscaleAPIRateBurst = v
+		}
+	}
+
+	if circuitThreshold := strings.TrimSpace(os.Getenv("TS_MCP_API_CIRCUIT_THRESHOLD")); circuitThreshold != "" {
+		if v, err := strconv.Atoi(circuitThreshold); err == nil && v > 0 {
+			cfg.TailscaleAPICircuitThreshold = v
+		}
+	}
+
+	if circuitOpen := strings.TrimSpace(os.Getenv("TS_MCP_API_CIRCUIT_OPEN_DURATION")); circuitOpen != "" {
+		if d, err := time.ParseDuration(circuitOpen); err == nil && d > 0 {
+			cfg.TailscaleAPICircuitOpenDuration = d
+		}
+	}
+
+	if debounce := strings.TrimSpace(os.Getenv("TS_MCP_WATCH_DEBOUNCE")); debounce != "" {
+		if d, err := time.ParseDuration(debounce); err == nil && d >= 0 {
+			cfg.WatchDebounce = d
+		}
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		if level, err := strconv.Atoi(logLevel); err == nil {
 			if level >= 0 && level <= 3 {
@@ -77,7 +338,113 @@ func Load() (*Config, error) {
 		cfg.LogFile = logFile
 	}
 
-	return cfg, nil
+	if logFormat := strings.TrimSpace(os.Getenv("LOG_FORMAT")); logFormat != "" {
+		cfg.LogFormat = logFormat
+	}
+
+	if auditDir := strings.TrimSpace(os.Getenv("TS_MCP_AUDIT_DIR")); auditDir != "" {
+		cfg.AuditDir = auditDir
+	}
+
+	if policyFile := strings.TrimSpace(os.Getenv("TS_MCP_TOOL_POLICY_FILE")); policyFile != "" {
+		cfg.ToolPolicyFile = policyFile
+	}
+
+	if backend := strings.TrimSpace(os.Getenv("TAILSCALE_BACKEND")); backend != "" {
+		cfg.TailscaleBackend = backend
+	}
+
+	if loginServer := strings.TrimSpace(os.Getenv("TAILSCALE_LOGIN_SERVER")); loginServer != "" {
+		cfg.TailscaleLoginServer = loginServer
+	}
+
+	if token := strings.TrimSpace(os.Getenv("HTTP_BEARER_TOKEN")); token != "" {
+		cfg.HTTPBearerToken = token
+	}
+
+	if header := strings.TrimSpace(os.Getenv("HTTP_API_KEY_HEADER")); header != "" {
+		cfg.HTTPAPIKeyHeader = header
+	}
+
+	if keyFile := strings.TrimSpace(os.Getenv("HTTP_API_KEY_FILE")); keyFile != "" {
+		cfg.HTTPAPIKeyFile = keyFile
+	}
+
+	if caFile := strings.TrimSpace(os.Getenv("HTTP_CLIENT_CA_FILE")); caFile != "" {
+		cfg.HTTPClientCAFile = caFile
+	}
+
+	if certFile := strings.TrimSpace(os.Getenv("HTTP_TLS_CERT_FILE")); certFile != "" {
+		cfg.HTTPTLSCertFile = certFile
+	}
+
+	if keyFile := strings.TrimSpace(os.Getenv("HTTP_TLS_KEY_FILE")); keyFile != "" {
+		cfg.HTTPTLSKeyFile = keyFile
+	}
+
+	if secret := strings.TrimSpace(os.Getenv("TAILSCALE_WEBHOOK_SECRET")); secret != "" {
+		cfg.TailscaleWebhookSecret = secret
+	}
+
+	if shutdownTimeout := strings.TrimSpace(os.Getenv("SHUTDOWN_TIMEOUT")); shutdownTimeout != "" {
+		if d, err := time.ParseDuration(shutdownTimeout); err == nil && d > 0 {
+			cfg.ShutdownTimeout = d
+		}
+	}
+
+	if enabled := strings.TrimSpace(os.Getenv("TSNET_ENABLED")); enabled != "" {
+		if v, err := strconv.ParseBool(enabled); err == nil {
+			cfg.TsnetEnabled = v
+		}
+	}
+
+	if hostname := strings.TrimSpace(os.Getenv("TSNET_HOSTNAME")); hostname != "" {
+		cfg.TsnetHostname = hostname
+	}
+
+	if authKey := strings.TrimSpace(os.Getenv("TSNET_AUTH_KEY")); authKey != "" {
+		cfg.TsnetAuthKey = authKey
+	}
+
+	if stateDir := strings.TrimSpace(os.Getenv("TSNET_STATE_DIR")); stateDir != "" {
+		cfg.TsnetStateDir = stateDir
+	}
+
+	if controlURL := strings.TrimSpace(os.Getenv("TSNET_CONTROL_URL")); controlURL != "" {
+		cfg.TsnetControlURL = controlURL
+	}
+
+	if ephemeral := strings.TrimSpace(os.Getenv("TSNET_EPHEMERAL")); ephemeral != "" {
+		if v, err := strconv.ParseBool(ephemeral); err == nil {
+			cfg.TsnetEphemeral = v
+		}
+	}
+
+	if autoFailover := strings.TrimSpace(os.Getenv("TS_MCP_AUTO_FAILOVER")); autoFailover != "" {
+		if v, err := strconv.ParseBool(autoFailover); err == nil {
+			cfg.AutoFailover = v
+		}
+	}
+
+	if pollInterval := strings.TrimSpace(os.Getenv("TS_MCP_FAILOVER_POLL_INTERVAL")); pollInterval != "" {
+		if d, err := time.ParseDuration(pollInterval); err == nil && d > 0 {
+			cfg.FailoverPollInterval = d
+		}
+	}
+
+	if enabled := strings.TrimSpace(os.Getenv("TS_MCP_WEB_UI_ENABLED")); enabled != "" {
+		if v, err := strconv.ParseBool(enabled); err == nil {
+			cfg.WebUIEnabled = v
+		}
+	}
+
+	if bind := strings.TrimSpace(os.Getenv("TS_MCP_WEB_UI_BIND")); bind != "" {
+		cfg.WebUIBind = bind
+	}
+
+	if mode := strings.TrimSpace(os.Getenv("TS_MCP_WEB_UI_MODE")); mode != "" {
+		cfg.WebUIMode = mode
+	}
 }
 
 // Validate checks if the configuration is valid
@@ -105,7 +472,7 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate server mode
-	validModes := []string{"stdio", "http"}
+	validModes := []string{"stdio", "http", "tsnet"}
 	if !contains(validModes, c.ServerMode) {
 		errors = append(errors, &ValidationError{
 			Field:   "ServerMode",
@@ -114,6 +481,69 @@ func (c *Config) Validate() error {
 		})
 	}
 
+	// Validate Tailscale backend
+	validBackends := []string{"cli", "localapi", "auto"}
+	if !contains(validBackends, c.TailscaleBackend) {
+		errors = append(errors, &ValidationError{
+			Field:   "TailscaleBackend",
+			Value:   c.TailscaleBackend,
+			Message: fmt.Sprintf("must be one of: %s", strings.Join(validBackends, ", ")),
+		})
+	}
+
+	// Validate tsnet configuration
+	if (c.TsnetEnabled || c.ServerMode == "tsnet") && strings.TrimSpace(c.TsnetHostname) == "" {
+		errors = append(errors, &ValidationError{
+			Field:   "TsnetHostname",
+			Message: "required when TsnetEnabled is true or ServerMode is \"tsnet\"",
+		})
+	}
+
+	// ServerMode "tsnet" serves the MCP transport from the embedded node's
+	// own tailnet-only listener, so it has no use for (and must not depend
+	// on) a system tailscaled CLI the way TailscaleBackend "cli" does.
+	if c.ServerMode == "tsnet" && c.TailscaleBackend == "cli" {
+		errors = append(errors, &ValidationError{
+			Field:   "TailscaleBackend",
+			Value:   c.TailscaleBackend,
+			Message: "cannot be \"cli\" when ServerMode is \"tsnet\"; use \"localapi\" or \"auto\"",
+		})
+	}
+
+	// Validate OAuth configuration: client ID and secret must come as a
+	// pair, and must not be mixed with a static API key.
+	if (c.TailscaleOAuthClientID != "") != (c.TailscaleOAuthClientSecret != "") {
+		errors = append(errors, &ValidationError{
+			Field:   "TailscaleOAuthClientID",
+			Message: "TAILSCALE_OAUTH_CLIENT_ID and TAILSCALE_OAUTH_CLIENT_SECRET must be set together",
+		})
+	}
+	if c.TailscaleAPIKey != "" && c.hasOAuthCredentials() {
+		errors = append(errors, &ValidationError{
+			Field:   "TailscaleAPIKey",
+			Message: "cannot be set together with TAILSCALE_OAUTH_CLIENT_ID/TAILSCALE_OAUTH_CLIENT_SECRET; choose one authentication method",
+		})
+	}
+
+	// Validate web UI mode
+	if c.WebUIMode != "" && c.WebUIMode != "read-only" && c.WebUIMode != "manage" {
+		errors = append(errors, &ValidationError{
+			Field:   "WebUIMode",
+			Value:   c.WebUIMode,
+			Message: "must be one of: read-only, manage",
+		})
+	}
+
+	// Validate config file schema version, if this Config came from one
+	// (see LoadFile).
+	if c.Version != "" && c.Version != ConfigFileVersion {
+		errors = append(errors, &ValidationError{
+			Field:   "Version",
+			Value:   c.Version,
+			Message: fmt.Sprintf("unsupported config file version; this build understands %q", ConfigFileVersion),
+		})
+	}
+
 	// Validate log file path if specified
 	if c.LogFile != "" {
 		if !isValidLogPath(c.LogFile) {
@@ -149,9 +579,51 @@ func (e *MultiValidationError) Error() string {
 	return fmt.Sprintf("multiple validation errors: %s", strings.Join(messages, "; "))
 }
 
-// HasAPICredentials returns true if API credentials are configured
+// IsLocked reports whether this Config was loaded from a config file with
+// Locked: true, making it read-only to MustSet.
+func (c *Config) IsLocked() bool {
+	return c.locked
+}
+
+// MustSet programmatically sets the named exported Config field to value,
+// returning an error instead of panicking on an unknown field, a type
+// mismatch, or a locked config. It exists so callers that build on top of
+// Load (flag overrides, tests) go through one guarded path instead of
+// assigning fields directly, which would silently bypass a file's
+// Locked: true.
+func (c *Config) MustSet(field string, value any) error {
+	if c.locked {
+		return fmt.Errorf("config is locked (loaded with locked: true): cannot set %s", field)
+	}
+
+	rv := reflect.ValueOf(c).Elem()
+	fv := rv.FieldByName(field)
+	if !fv.IsValid() || !fv.CanSet() {
+		return fmt.Errorf("unknown config field %q", field)
+	}
+
+	val := reflect.ValueOf(value)
+	if !val.Type().AssignableTo(fv.Type()) {
+		return fmt.Errorf("cannot assign %T to field %s (%s)", value, field, fv.Type())
+	}
+
+	fv.Set(val)
+	return nil
+}
+
+// HasAPICredentials returns true if API credentials are configured, whether
+// a static TailscaleAPIKey or an OAuth2 client-credentials pair.
 func (c *Config) HasAPICredentials() bool {
-	return c.TailscaleAPIKey != "" && c.TailscaleTailnet != ""
+	if c.TailscaleTailnet == "" {
+		return false
+	}
+	return c.TailscaleAPIKey != "" || c.hasOAuthCredentials()
+}
+
+// hasOAuthCredentials reports whether both halves of the OAuth2
+// client-credentials pair are set.
+func (c *Config) hasOAuthCredentials() bool {
+	return c.TailscaleOAuthClientID != "" && c.TailscaleOAuthClientSecret != ""
 }
 
 // LogLevelString returns the log level as a string
@@ -177,6 +649,18 @@ func (c *Config) SanitizedCopy() *Config {
 	if copy.TailscaleAPIKey != "" {
 		copy.TailscaleAPIKey = redactSecret(copy.TailscaleAPIKey)
 	}
+	if copy.TailscaleOAuthClientSecret != "" {
+		copy.TailscaleOAuthClientSecret = redactSecret(copy.TailscaleOAuthClientSecret)
+	}
+	if copy.TsnetAuthKey != "" {
+		copy.TsnetAuthKey = redactSecret(copy.TsnetAuthKey)
+	}
+	if copy.HTTPBearerToken != "" {
+		copy.HTTPBearerToken = redactSecret(copy.HTTPBearerToken)
+	}
+	if copy.TailscaleWebhookSecret != "" {
+		copy.TailscaleWebhookSecret = redactSecret(copy.TailscaleWebhookSecret)
+	}
 	return &copy
 }
 
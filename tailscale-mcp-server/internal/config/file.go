@@ -0,0 +1,264 @@
+// tailscale-mcp-server/internal/config/file.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileVersion is the only config file schema version this build
+// understands. It follows upstream Tailscale's convention of starting a
+// new declarative config format at "alpha0" and bumping it as the format
+// stabilizes; LoadFile rejects any other value.
+const ConfigFileVersion = "alpha0"
+
+// ToolsConfig holds per-tool overrides settable from a config file, under
+// the top-level "tools" key.
+type ToolsConfig struct {
+	Admin    ToolOverride `yaml:"admin,omitempty" json:"admin,omitempty"`
+	ExitNode ToolOverride `yaml:"exit_node,omitempty" json:"exit_node,omitempty"`
+}
+
+// ToolOverride holds the settable fields for a single tool. Not every tool
+// uses every field; Enabled is presently only consulted by tools that check
+// it explicitly (as of this writing, no tool does, since admin is the only
+// disableable tool in the request and it isn't registered - see
+// internal/tools/admin.go). Pointers distinguish "absent" from "false".
+type ToolOverride struct {
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Auto    *bool `yaml:"auto,omitempty" json:"auto,omitempty"`
+}
+
+// fileConfig is the on-disk shape of a config file, mirroring most of
+// Config's env-var-backed fields; the API rate-limit and circuit-breaker
+// settings, WatchDebounce and TailscaleWebhookSecret have no file field and
+// come only from env vars. Durations are plain strings (e.g.
+// "30s", parsed with time.ParseDuration) rather than time.Duration, since
+// neither encoding/json nor yaml.v3 parse durations natively.
+type fileConfig struct {
+	Version string `yaml:"version" json:"version"`
+	Locked  bool   `yaml:"locked,omitempty" json:"locked,omitempty"`
+
+	Tools ToolsConfig `yaml:"tools,omitempty" json:"tools,omitempty"`
+
+	TailscaleAPIKey            string `yaml:"tailscale_api_key,omitempty" json:"tailscale_api_key,omitempty"`
+	TailscaleTailnet           string `yaml:"tailscale_tailnet,omitempty" json:"tailscale_tailnet,omitempty"`
+	TailscaleAPIBaseURL        string `yaml:"tailscale_api_base_url,omitempty" json:"tailscale_api_base_url,omitempty"`
+	TailscaleOAuthClientID     string `yaml:"tailscale_oauth_client_id,omitempty" json:"tailscale_oauth_client_id,omitempty"`
+	TailscaleOAuthClientSecret string `yaml:"tailscale_oauth_client_secret,omitempty" json:"tailscale_oauth_client_secret,omitempty"`
+	TailscaleOAuthScopes       string `yaml:"tailscale_oauth_scopes,omitempty" json:"tailscale_oauth_scopes,omitempty"`
+
+	LogLevel       int    `yaml:"log_level,omitempty" json:"log_level,omitempty"`
+	LogFile        string `yaml:"log_file,omitempty" json:"log_file,omitempty"`
+	LogFormat      string `yaml:"log_format,omitempty" json:"log_format,omitempty"`
+	AuditDir       string `yaml:"audit_dir,omitempty" json:"audit_dir,omitempty"`
+	ToolPolicyFile string `yaml:"tool_policy_file,omitempty" json:"tool_policy_file,omitempty"`
+
+	ServerMode string `yaml:"server_mode,omitempty" json:"server_mode,omitempty"`
+
+	HTTPBearerToken  string `yaml:"http_bearer_token,omitempty" json:"http_bearer_token,omitempty"`
+	HTTPAPIKeyHeader string `yaml:"http_api_key_header,omitempty" json:"http_api_key_header,omitempty"`
+	HTTPAPIKeyFile   string `yaml:"http_api_key_file,omitempty" json:"http_api_key_file,omitempty"`
+	HTTPClientCAFile string `yaml:"http_client_ca_file,omitempty" json:"http_client_ca_file,omitempty"`
+	HTTPTLSCertFile  string `yaml:"http_tls_cert_file,omitempty" json:"http_tls_cert_file,omitempty"`
+	HTTPTLSKeyFile   string `yaml:"http_tls_key_file,omitempty" json:"http_tls_key_file,omitempty"`
+
+	ShutdownTimeout string `yaml:"shutdown_timeout,omitempty" json:"shutdown_timeout,omitempty"`
+
+	TailscaleBackend     string `yaml:"tailscale_backend,omitempty" json:"tailscale_backend,omitempty"`
+	TailscaleLoginServer string `yaml:"tailscale_login_server,omitempty" json:"tailscale_login_server,omitempty"`
+
+	TsnetEnabled    bool   `yaml:"tsnet_enabled,omitempty" json:"tsnet_enabled,omitempty"`
+	TsnetHostname   string `yaml:"tsnet_hostname,omitempty" json:"tsnet_hostname,omitempty"`
+	TsnetAuthKey    string `yaml:"tsnet_auth_key,omitempty" json:"tsnet_auth_key,omitempty"`
+	TsnetStateDir   string `yaml:"tsnet_state_dir,omitempty" json:"tsnet_state_dir,omitempty"`
+	TsnetControlURL string `yaml:"tsnet_control_url,omitempty" json:"tsnet_control_url,omitempty"`
+	TsnetEphemeral  bool   `yaml:"tsnet_ephemeral,omitempty" json:"tsnet_ephemeral,omitempty"`
+
+	AutoFailover         bool   `yaml:"auto_failover,omitempty" json:"auto_failover,omitempty"`
+	FailoverPollInterval string `yaml:"failover_poll_interval,omitempty" json:"failover_poll_interval,omitempty"`
+
+	WebUIEnabled bool   `yaml:"web_ui_enabled,omitempty" json:"web_ui_enabled,omitempty"`
+	WebUIBind    string `yaml:"web_ui_bind,omitempty" json:"web_ui_bind,omitempty"`
+	WebUIMode    string `yaml:"web_ui_mode,omitempty" json:"web_ui_mode,omitempty"`
+}
+
+// LoadFile reads a declarative config file at path (YAML, or JSON if path
+// ends in .json) and converts it into a *Config. The file's Version must
+// equal ConfigFileVersion. If Locked is true, the returned Config's
+// IsLocked reports true, and Load will not layer env var overrides on top
+// of it.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &fc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config file: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &fc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config file: %w", err)
+		}
+	}
+
+	if fc.Version == "" {
+		return nil, fmt.Errorf("config file is missing required \"version\" field (expected %q)", ConfigFileVersion)
+	}
+	if fc.Version != ConfigFileVersion {
+		return nil, fmt.Errorf("config file has unsupported version %q (expected %q)", fc.Version, ConfigFileVersion)
+	}
+
+	return fileConfigToConfig(fc)
+}
+
+// fileConfigToConfig converts a parsed fileConfig into a *Config, applying
+// the same defaulting LoadFile has always done. loadCapabilityDir (see
+// capdir.go) does not use it: a cap-<N>.hujson file is overlaid onto the
+// env-var defaults by mergeFileConfig instead.
+func fileConfigToConfig(fc fileConfig) (*Config, error) {
+	cfg := &Config{
+		Version: fc.Version,
+		locked:  fc.Locked,
+		Tools:   fc.Tools,
+
+		TailscaleAPIKey:            fc.TailscaleAPIKey,
+		TailscaleTailnet:           fc.TailscaleTailnet,
+		TailscaleAPIBaseURL:        fc.TailscaleAPIBaseURL,
+		TailscaleOAuthClientID:     fc.TailscaleOAuthClientID,
+		TailscaleOAuthClientSecret: fc.TailscaleOAuthClientSecret,
+		TailscaleOAuthScopes:       fc.TailscaleOAuthScopes,
+
+		LogLevel:       fc.LogLevel,
+		LogFile:        fc.LogFile,
+		LogFormat:      fc.LogFormat,
+		AuditDir:       fc.AuditDir,
+		ToolPolicyFile: fc.ToolPolicyFile,
+
+		ServerMode: fc.ServerMode,
+
+		HTTPBearerToken:  fc.HTTPBearerToken,
+		HTTPAPIKeyHeader: fc.HTTPAPIKeyHeader,
+		HTTPAPIKeyFile:   fc.HTTPAPIKeyFile,
+		HTTPClientCAFile: fc.HTTPClientCAFile,
+		HTTPTLSCertFile:  fc.HTTPTLSCertFile,
+		HTTPTLSKeyFile:   fc.HTTPTLSKeyFile,
+
+		TailscaleBackend:     fc.TailscaleBackend,
+		TailscaleLoginServer: fc.TailscaleLoginServer,
+
+		TsnetEnabled:    fc.TsnetEnabled,
+		TsnetHostname:   fc.TsnetHostname,
+		TsnetAuthKey:    fc.TsnetAuthKey,
+		TsnetStateDir:   fc.TsnetStateDir,
+		TsnetControlURL: fc.TsnetControlURL,
+		TsnetEphemeral:  fc.TsnetEphemeral,
+
+		AutoFailover: fc.AutoFailover,
+
+		WebUIEnabled: fc.WebUIEnabled,
+		WebUIBind:    fc.WebUIBind,
+		WebUIMode:    fc.WebUIMode,
+	}
+
+	if fc.ShutdownTimeout != "" {
+		d, err := time.ParseDuration(fc.ShutdownTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shutdown_timeout %q: %w", fc.ShutdownTimeout, err)
+		}
+		cfg.ShutdownTimeout = d
+	} else {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+
+	if fc.FailoverPollInterval != "" {
+		d, err := time.ParseDuration(fc.FailoverPollInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid failover_poll_interval %q: %w", fc.FailoverPollInterval, err)
+		}
+		cfg.FailoverPollInterval = d
+	} else {
+		cfg.FailoverPollInterval = 30 * time.Second
+	}
+
+	if cfg.TailscaleAPIBaseURL == "" {
+		cfg.TailscaleAPIBaseURL = "https://api.tailscale.com"
+	}
+	if cfg.ServerMode == "" {
+		cfg.ServerMode = "stdio"
+	}
+	if cfg.TailscaleBackend == "" {
+		cfg.TailscaleBackend = "auto"
+	}
+
+	return cfg, nil
+}
+
+// MarshalFile renders cfg as an alpha0 config file (YAML), suitable for
+// writing out and later loading back via LoadFile. Used by the
+// `config migrate` CLI command to translate a running env-var
+// configuration into the declarative file format. The emitted file always
+// has locked: false; an operator who wants locking adds it by hand.
+func MarshalFile(cfg *Config) ([]byte, error) {
+	fc := fileConfig{
+		Version: ConfigFileVersion,
+		Locked:  false,
+		Tools:   cfg.Tools,
+
+		TailscaleAPIKey:            cfg.TailscaleAPIKey,
+		TailscaleTailnet:           cfg.TailscaleTailnet,
+		TailscaleAPIBaseURL:        cfg.TailscaleAPIBaseURL,
+		TailscaleOAuthClientID:     cfg.TailscaleOAuthClientID,
+		TailscaleOAuthClientSecret: cfg.TailscaleOAuthClientSecret,
+		TailscaleOAuthScopes:       cfg.TailscaleOAuthScopes,
+
+		LogLevel:       cfg.LogLevel,
+		LogFile:        cfg.LogFile,
+		LogFormat:      cfg.LogFormat,
+		AuditDir:       cfg.AuditDir,
+		ToolPolicyFile: cfg.ToolPolicyFile,
+
+		ServerMode: cfg.ServerMode,
+
+		HTTPBearerToken:  cfg.HTTPBearerToken,
+		HTTPAPIKeyHeader: cfg.HTTPAPIKeyHeader,
+		HTTPAPIKeyFile:   cfg.HTTPAPIKeyFile,
+		HTTPClientCAFile: cfg.HTTPClientCAFile,
+		HTTPTLSCertFile:  cfg.HTTPTLSCertFile,
+		HTTPTLSKeyFile:   cfg.HTTPTLSKeyFile,
+
+		ShutdownTimeout: cfg.ShutdownTimeout.String(),
+
+		TailscaleBackend:     cfg.TailscaleBackend,
+		TailscaleLoginServer: cfg.TailscaleLoginServer,
+
+		TsnetEnabled:    cfg.TsnetEnabled,
+		TsnetHostname:   cfg.TsnetHostname,
+		TsnetAuthKey:    cfg.TsnetAuthKey,
+		TsnetStateDir:   cfg.TsnetStateDir,
+		TsnetControlURL: cfg.TsnetControlURL,
+		TsnetEphemeral:  cfg.TsnetEphemeral,
+
+		AutoFailover:         cfg.AutoFailover,
+		FailoverPollInterval: cfg.FailoverPollInterval.String(),
+
+		WebUIEnabled: cfg.WebUIEnabled,
+		WebUIBind:    cfg.WebUIBind,
+		WebUIMode:    cfg.WebUIMode,
+	}
+
+	out, err := yaml.Marshal(fc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+// tailscale-mcp-server/internal/config/file_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoadFileAcceptsCurrentVersion(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "version: "+ConfigFileVersion+"\n")
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, ConfigFileVersion, cfg.Version)
+}
+
+func TestLoadFileRejectsMissingVersion(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "tailscale_backend: auto\n")
+
+	_, err := LoadFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing required")
+}
+
+func TestLoadFileRejectsUnsupportedVersion(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "version: v2\n")
+
+	_, err := LoadFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported version")
+}
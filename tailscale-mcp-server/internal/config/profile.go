@@ -0,0 +1,205 @@
+// tailscale-mcp-server/internal/config/profile.go
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profilesFileEnvVar overrides ProfilesFilePath's default location,
+// mirroring TAILSCALE_MCP_CONFIG's override of the config file path.
+const profilesFileEnvVar = "TS_MCP_PROFILES_FILE"
+
+// ControlProfile names one control-server configuration the `profile`
+// CLI subtree can switch between: either Tailscale's own SaaS API
+// (APIKey/Tailnet/APIBaseURL, the same shape as Config's own Tailscale*
+// fields) or a self-hosted Headscale server (HeadscaleAddr/
+// HeadscaleAPIToken, consumed by internal/headscale.Client), plus the
+// --login-server value the node itself should use. Exactly one of the
+// two credential shapes is meaningful at a time, selected by
+// UseHeadscale.
+type ControlProfile struct {
+	Name string `json:"name"`
+
+	// LoginServer is the control server URL passed as --login-server to
+	// `tailscale up` (e.g. "https://headscale.example.com"). Required for
+	// a Headscale profile; leave empty to use Tailscale's default SaaS
+	// control server.
+	LoginServer string `json:"login_server,omitempty"`
+
+	// UseHeadscale selects which credential fields below are meaningful:
+	// false (the default) means APIKey/Tailnet/APIBaseURL, read against
+	// Tailscale's own v2 API; true means HeadscaleAddr/HeadscaleAPIToken,
+	// read against a self-hosted Headscale server's HTTP API instead.
+	UseHeadscale bool `json:"use_headscale,omitempty"`
+
+	APIKey     string `json:"api_key,omitempty"`
+	Tailnet    string `json:"tailnet,omitempty"`
+	APIBaseURL string `json:"api_base_url,omitempty"`
+
+	// HeadscaleAddr is the base URL of a self-hosted Headscale server's
+	// HTTP API, used by internal/headscale.Client when UseHeadscale is
+	// true.
+	HeadscaleAddr     string `json:"headscale_addr,omitempty"`
+	HeadscaleAPIToken string `json:"headscale_api_token,omitempty"`
+}
+
+// ApplyTo layers p's non-empty fields onto cfg, the same way
+// applyEnvOverrides layers environment variables on: a profile field only
+// overrides cfg when it's actually set. UseHeadscale profiles leave
+// cfg's Tailscale* API fields untouched, since internal/headscale.Client
+// (not APIClient) is what reads HeadscaleAddr/HeadscaleAPIToken.
+func (p *ControlProfile) ApplyTo(cfg *Config) {
+	if p.LoginServer != "" {
+		cfg.TailscaleLoginServer = p.LoginServer
+	}
+	if p.UseHeadscale {
+		return
+	}
+	if p.APIKey != "" {
+		cfg.TailscaleAPIKey = p.APIKey
+	}
+	if p.Tailnet != "" {
+		cfg.TailscaleTailnet = p.Tailnet
+	}
+	if p.APIBaseURL != "" {
+		cfg.TailscaleAPIBaseURL = p.APIBaseURL
+	}
+}
+
+// ProfileSet is the on-disk shape of the profiles file (see
+// ProfilesFilePath): every saved ControlProfile, plus which one is
+// active.
+type ProfileSet struct {
+	Active   string           `json:"active,omitempty"`
+	Profiles []ControlProfile `json:"profiles,omitempty"`
+}
+
+// ProfilesFilePath returns where the `profile` CLI subtree reads and
+// writes saved ControlProfiles: TS_MCP_PROFILES_FILE if set, otherwise
+// "<os.UserConfigDir()>/tailscale-mcp-server/profiles.json".
+func ProfilesFilePath() (string, error) {
+	if path := strings.TrimSpace(os.Getenv(profilesFileEnvVar)); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user config dir: %w", err)
+	}
+	return filepath.Join(dir, "tailscale-mcp-server", "profiles.json"), nil
+}
+
+// LoadProfiles reads the profiles file at path. A missing file is not an
+// error: it returns an empty ProfileSet, since "no profiles configured
+// yet" is the common case for an install that never touches the
+// `profile` subtree.
+func LoadProfiles(path string) (*ProfileSet, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &ProfileSet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	var ps ProfileSet
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file %s: %w", path, err)
+	}
+	return &ps, nil
+}
+
+// Save writes ps to path as indented JSON, creating parent directories as
+// needed. Permissions are kept tight since profiles may carry API keys
+// or Headscale tokens.
+func (ps *ProfileSet) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write profiles file: %w", err)
+	}
+	return nil
+}
+
+// Get returns the named profile, or nil if no profile by that name
+// exists.
+func (ps *ProfileSet) Get(name string) *ControlProfile {
+	for i := range ps.Profiles {
+		if ps.Profiles[i].Name == name {
+			return &ps.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// Upsert adds p, or replaces the existing profile with the same name.
+func (ps *ProfileSet) Upsert(p ControlProfile) {
+	for i := range ps.Profiles {
+		if ps.Profiles[i].Name == p.Name {
+			ps.Profiles[i] = p
+			return
+		}
+	}
+	ps.Profiles = append(ps.Profiles, p)
+}
+
+// Remove deletes the named profile, clearing Active if it was the active
+// one. Reports whether a profile by that name existed.
+func (ps *ProfileSet) Remove(name string) bool {
+	for i := range ps.Profiles {
+		if ps.Profiles[i].Name == name {
+			ps.Profiles = append(ps.Profiles[:i], ps.Profiles[i+1:]...)
+			if ps.Active == name {
+				ps.Active = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// SetActive marks name as the active profile, failing if no profile by
+// that name exists.
+func (ps *ProfileSet) SetActive(name string) error {
+	if ps.Get(name) == nil {
+		return fmt.Errorf("no such profile: %s", name)
+	}
+	ps.Active = name
+	return nil
+}
+
+// ActiveProfile returns the active profile, or nil if none is set.
+func (ps *ProfileSet) ActiveProfile() *ControlProfile {
+	if ps.Active == "" {
+		return nil
+	}
+	return ps.Get(ps.Active)
+}
+
+// LoadActiveControlProfile reads the profiles file's active
+// ControlProfile, if any. A missing profiles file or no active profile
+// both return (nil, nil) rather than an error, since most installs never
+// touch the `profile` subtree.
+func LoadActiveControlProfile() (*ControlProfile, error) {
+	path, err := ProfilesFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := LoadProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return ps.ActiveProfile(), nil
+}
@@ -0,0 +1,114 @@
+// tailscale-mcp-server/internal/headscale/client.go
+
+// Package headscale talks to a self-hosted Headscale server's HTTP API
+// (https://headscale.net/), the control server a Headscale
+// config.ControlProfile points at instead of Tailscale's own SaaS API.
+//
+// This is a deliberately partial client: it covers node listing and key
+// expiry, the two operations Headscale exposes through a REST endpoint
+// shaped enough like Tailscale's v2 API to translate directly. Routes and
+// ACL policy are Headscale concepts too, but Headscale manages both
+// through its own CLI/policy file rather than a per-device HTTP endpoint,
+// so there's no direct analogue of APIClient's SetDeviceEnabledRoutes or
+// SetACL to implement here. Wiring this client's output into the MCP tool
+// layer alongside APIClient's (so tools work unmodified against either
+// control server) would also require first extracting an interface from
+// APIClient's concrete type, since tools are constructed directly against
+// *tailscale.APIClient throughout internal/tools/registry.go - a larger
+// refactor than this package takes on; that extraction is left as
+// follow-up work.
+package headscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to a Headscale server's HTTP API using a pre-shared API
+// key (minted with `headscale apikeys create`), the Headscale analogue
+// of internal/tailscale.APIClient's Tailscale API key.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client for the Headscale server at addr (e.g.
+// "https://headscale.example.com"), authenticating with apiKey.
+func NewClient(addr, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(addr, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Node is a reduced view of a Headscale node, covering the fields
+// ListNodes callers need today, the Headscale analogue of
+// internal/tailscale's device listing.
+type Node struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"givenName"`
+	User     string   `json:"user"`
+	IPAddrs  []string `json:"ipAddresses"`
+	Online   bool     `json:"online"`
+	Expiry   string   `json:"expiry"`
+	LastSeen string   `json:"lastSeen"`
+}
+
+// ListNodes returns every node Headscale knows about, the Headscale
+// analogue of APIClient.ListDevices.
+func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
+	var out struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/node", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Nodes, nil
+}
+
+// ExpireNodeKey expires a node's machine key immediately, the Headscale
+// analogue of APIClient.ExpireDeviceKey.
+func (c *Client) ExpireNodeKey(ctx context.Context, nodeID string) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/api/v1/node/%s/expire", nodeID), nil, nil)
+}
+
+// do issues an authenticated request against path and, when out is
+// non-nil, decodes the JSON response body into it.
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build headscale request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("headscale request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read headscale response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("headscale returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode headscale response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,557 @@
+// Package jsonrpc2 implements a bidirectional JSON-RPC 2.0 connection, in the
+// spirit of x/tools/internal/jsonrpc2: a Conn owns a Stream and can both
+// answer inbound requests (via a Handler) and issue outbound Call/Notify
+// requests of its own, with pending calls and in-flight inbound handlers
+// tracked so a "$/cancelRequest" (or MCP's "notifications/cancelled")
+// notification can cancel a tool call that's still running. Requests run
+// concurrently on their own goroutines, bounded by a configurable limit, so
+// one slow tool call doesn't stall the rest.
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+)
+
+// Version is the JSON-RPC protocol version every message on the wire carries.
+const Version = "2.0"
+
+// MethodCancelRequest is the notification a client (or this Conn) sends to
+// ask the peer to cancel an in-flight request, LSP-style.
+const MethodCancelRequest = "$/cancelRequest"
+
+// MethodCancelled is the MCP-spec name for the same notification; Conn
+// treats it identically to MethodCancelRequest so either a strict LSP client
+// or a strict MCP client can cancel an in-flight call.
+const MethodCancelled = "notifications/cancelled"
+
+// CodeRequestCancelled is the error code returned to a request whose
+// handler observed its context being canceled, mirroring LSP's
+// RequestCancelled.
+const CodeRequestCancelled = -32800
+
+// defaultConcurrency bounds how many inbound requests Conn will run their
+// Handler for at once when no WithConcurrency option is given.
+const defaultConcurrency = 32
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError builds an *Error from an arbitrary Go error, preserving it
+// unchanged if it already is one.
+func NewError(code int, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if rpcErr, ok := err.(*Error); ok {
+		return rpcErr
+	}
+	return &Error{Code: code, Message: err.Error()}
+}
+
+// wireMessage is the on-the-wire shape of a single JSON-RPC message. Params
+// and Result stay as raw JSON so Conn never needs to know the MCP-specific
+// payload types riding on top of it.
+type wireMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+func (m *wireMessage) isRequest() bool  { return m.Method != "" }
+func (m *wireMessage) isResponse() bool { return m.Method == "" && len(m.ID) > 0 }
+
+// cancelParams is the payload of a $/cancelRequest or notifications/cancelled
+// notification. The two methods spell the ID field differently (LSP's "id"
+// vs MCP's "requestId"), so both are accepted.
+type cancelParams struct {
+	ID        json.RawMessage `json:"id"`
+	RequestID json.RawMessage `json:"requestId"`
+}
+
+func (p *cancelParams) requestID() json.RawMessage {
+	if len(p.RequestID) > 0 {
+		return p.RequestID
+	}
+	return p.ID
+}
+
+// Request is an inbound call or notification handed to a Handler. ID is nil
+// for notifications.
+type Request struct {
+	ID     json.RawMessage
+	Method string
+	Params json.RawMessage
+}
+
+// IsNotification reports whether this request expects no reply.
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// Replier sends the outcome of handling a Request back to the peer. It must
+// be called exactly once per request, and not at all for notifications.
+type Replier func(ctx context.Context, result any, err error) error
+
+// Handler processes a single inbound Request, invoking reply with the
+// result (or error) once it's ready.
+type Handler func(ctx context.Context, reply Replier, req *Request) error
+
+// Stream reads and writes whole JSON-RPC messages (or batches, as a raw JSON
+// array) as opaque bytes; framing is its concern, not Conn's.
+type Stream interface {
+	Read() (json.RawMessage, error)
+	Write(json.RawMessage) error
+}
+
+// Conn is a bidirectional JSON-RPC connection: it answers inbound requests
+// via Handler while also being able to issue its own Call/Notify requests to
+// the peer, matching the x/tools jsonrpc2.Conn shape.
+type Conn struct {
+	stream  Stream
+	handler Handler
+
+	seq atomic.Int64
+
+	writeMu sync.Mutex
+
+	pendingMu sync.Mutex
+	pending   map[string]chan *wireMessage
+
+	handlingMu sync.Mutex
+	handling   map[string]context.CancelFunc
+
+	sem chan struct{}
+
+	version    atomic.Value // string, set via SetProtocolVersion
+	logLevel   atomic.Int32 // zapcore.Level, set via SetLogLevel
+	clientInfo atomic.Value // clientInfo, set via SetClientInfo
+}
+
+// clientInfo is the value stored in Conn.clientInfo.
+type clientInfo struct {
+	name    string
+	version string
+}
+
+// connContextKey is the context key Conn uses to expose itself to a
+// Handler, so a handler can look up (or record) per-connection state like
+// the negotiated protocol version without Conn needing to know about MCP.
+type connContextKey struct{}
+
+// ConnFromContext returns the Conn dispatching the current request, as
+// injected by dispatchRequest.
+func ConnFromContext(ctx context.Context) (*Conn, bool) {
+	c, ok := ctx.Value(connContextKey{}).(*Conn)
+	return c, ok
+}
+
+// SetProtocolVersion records a value (e.g. the protocol version negotiated
+// during an MCP initialize handshake) on this Conn, readable by later
+// requests via ProtocolVersion.
+func (c *Conn) SetProtocolVersion(v string) { c.version.Store(v) }
+
+// ProtocolVersion returns the value last recorded by SetProtocolVersion, or
+// "" if none has been set yet.
+func (c *Conn) ProtocolVersion() string {
+	v, _ := c.version.Load().(string)
+	return v
+}
+
+// SetLogLevel records the minimum zap level (as its numeric zapcore.Level
+// value) this Conn wants log records forwarded at, e.g. following an MCP
+// logging/setLevel request. Conn itself has no opinion on what "forwarded"
+// means; it just stores the value for whatever sink the transport wired up
+// to read it back via LogLevel.
+func (c *Conn) SetLogLevel(level int32) { c.logLevel.Store(level) }
+
+// LogLevel returns the level last recorded by SetLogLevel. Its zero value
+// (before any call) is 0, which is zapcore.InfoLevel.
+func (c *Conn) LogLevel() int32 { return c.logLevel.Load() }
+
+// SetClientInfo records the MCP client's name and version (from
+// InitializeRequest.ClientInfo) on this Conn, readable by later requests on
+// the same connection via ClientInfo, e.g. to attribute an audit record to
+// the client that issued it.
+func (c *Conn) SetClientInfo(name, version string) {
+	c.clientInfo.Store(clientInfo{name: name, version: version})
+}
+
+// ClientInfo returns the name and version last recorded by SetClientInfo,
+// or ("", "") if none has been set yet.
+func (c *Conn) ClientInfo() (name, version string) {
+	info, _ := c.clientInfo.Load().(clientInfo)
+	return info.name, info.version
+}
+
+// ConnOption configures a Conn at construction time.
+type ConnOption func(*connConfig)
+
+type connConfig struct {
+	concurrency int
+}
+
+// WithConcurrency bounds how many inbound requests Conn runs its Handler for
+// concurrently; further requests wait on their own goroutines, without
+// blocking the read loop, until a slot frees up. The default is 32.
+func WithConcurrency(n int) ConnOption {
+	return func(c *connConfig) { c.concurrency = n }
+}
+
+// NewConn creates a Conn around stream. handler is invoked for every inbound
+// request and notification except $/cancelRequest (or its MCP spelling,
+// notifications/cancelled), which Conn handles itself by canceling the
+// matching in-flight handler's context. Requests run concurrently, up to the
+// limit set by WithConcurrency.
+func NewConn(stream Stream, handler Handler, opts ...ConnOption) *Conn {
+	cfg := connConfig{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.concurrency <= 0 {
+		cfg.concurrency = defaultConcurrency
+	}
+
+	return &Conn{
+		stream:   stream,
+		handler:  handler,
+		pending:  make(map[string]chan *wireMessage),
+		handling: make(map[string]context.CancelFunc),
+		sem:      make(chan struct{}, cfg.concurrency),
+	}
+}
+
+// Run reads messages from the stream until it errors or ctx is done,
+// dispatching each to the handler (or to a pending Call) as it arrives.
+// Requests run on their own goroutine, up to the Conn's concurrency limit,
+// so a slow tool call doesn't delay reading (and cancelling) others.
+func (c *Conn) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		raw, err := c.stream.Read()
+		if err != nil {
+			return err
+		}
+
+		c.dispatch(ctx, raw)
+	}
+}
+
+// dispatch decodes one line of input, which is either a single message or a
+// JSON-RPC batch (an array of messages), and routes each to the right place.
+func (c *Conn) dispatch(ctx context.Context, raw json.RawMessage) {
+	trimmed := trimLeadingSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			c.writeMessage(&wireMessage{JSONRPC: Version, ID: nullID, Error: &Error{Code: -32700, Message: "Parse error: " + err.Error()}})
+			return
+		}
+		c.dispatchBatch(ctx, batch)
+		return
+	}
+
+	c.dispatchOne(ctx, raw, nil)
+}
+
+// nullID is the explicit JSON "null" this package writes into a response's
+// id field whenever the spec requires Null rather than an omitted field,
+// e.g. for a malformed request whose own id could never be determined.
+var nullID = json.RawMessage("null")
+
+// dispatchBatch handles a JSON-RPC batch: requests within it run
+// concurrently (subject to the same concurrency limit as top-level
+// requests), and the whole batch is written back as a single JSON array,
+// in submission order, once every member has replied (notifications
+// contribute no entry). Per spec, an empty batch is itself an invalid
+// request and gets a single error object, not an empty array.
+func (c *Conn) dispatchBatch(ctx context.Context, batch []json.RawMessage) {
+	if len(batch) == 0 {
+		c.writeMessage(&wireMessage{JSONRPC: Version, ID: nullID, Error: &Error{Code: -32600, Message: "Invalid Request: empty batch"}})
+		return
+	}
+
+	replies := make([]*wireMessage, len(batch))
+	var wg sync.WaitGroup
+	for i, raw := range batch {
+		wg.Add(1)
+		i, raw := i, raw
+		go func() {
+			defer wg.Done()
+			c.dispatchOne(ctx, raw, func(msg *wireMessage) { replies[i] = msg })
+		}()
+	}
+	wg.Wait()
+
+	out := make([]*wireMessage, 0, len(replies))
+	for _, msg := range replies {
+		if msg != nil {
+			out = append(out, msg)
+		}
+	}
+
+	if len(out) == 0 {
+		return
+	}
+	c.writeBatch(out)
+}
+
+// dispatchOne decodes and routes a single (non-batch) message. If collect is
+// non-nil the reply is handed to it instead of being written directly to the
+// stream, so dispatchBatch can gather a whole batch's replies first.
+func (c *Conn) dispatchOne(ctx context.Context, raw json.RawMessage, collect func(*wireMessage)) {
+	var msg wireMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		c.reply(collect, &wireMessage{JSONRPC: Version, ID: nullID, Error: &Error{Code: -32700, Message: "Parse error: " + err.Error()}})
+		return
+	}
+
+	switch {
+	case msg.isResponse():
+		c.completeCall(&msg)
+
+	case msg.Method == MethodCancelRequest || msg.Method == MethodCancelled:
+		c.handleCancel(msg.Params)
+
+	case msg.isRequest():
+		c.dispatchRequest(ctx, &msg, collect)
+
+	default:
+		id := msg.ID
+		if len(id) == 0 {
+			id = nullID
+		}
+		c.reply(collect, &wireMessage{JSONRPC: Version, ID: id, Error: &Error{Code: -32600, Message: "Invalid Request"}})
+	}
+}
+
+// dispatchRequest runs req's handler on its own goroutine, admitted through
+// c.sem so at most cfg.concurrency handlers run at once; outside a batch the
+// goroutine is spawned before waiting for a slot, so a caller stuck waiting
+// for a free slot never blocks Run's read loop (and so a queued call can
+// still be canceled before it starts).
+func (c *Conn) dispatchRequest(ctx context.Context, msg *wireMessage, collect func(*wireMessage)) {
+	req := &Request{ID: msg.ID, Method: msg.Method, Params: msg.Params}
+
+	handlerCtx := context.WithValue(ctx, connContextKey{}, c)
+	var key string
+	var cancel context.CancelFunc
+	if !req.IsNotification() {
+		key = string(msg.ID)
+		handlerCtx, cancel = context.WithCancel(handlerCtx)
+		c.handlingMu.Lock()
+		c.handling[key] = cancel
+		c.handlingMu.Unlock()
+	}
+
+	reply := func(_ context.Context, result any, err error) error {
+		if key != "" {
+			c.handlingMu.Lock()
+			delete(c.handling, key)
+			c.handlingMu.Unlock()
+		}
+		if req.IsNotification() {
+			return nil
+		}
+		c.reply(collect, c.responseMessage(msg.ID, result, err))
+		return nil
+	}
+
+	run := func() {
+		if cancel != nil {
+			defer cancel()
+		}
+
+		select {
+		case c.sem <- struct{}{}:
+		case <-handlerCtx.Done():
+			_ = reply(handlerCtx, nil, handlerCtx.Err())
+			return
+		}
+		defer func() { <-c.sem }()
+
+		if err := c.handler(handlerCtx, reply, req); err != nil {
+			_ = reply(handlerCtx, nil, err)
+		}
+	}
+
+	// Inside a batch, dispatchBatch has already given this entry its own
+	// goroutine and waits on it, so run inline: its reply is then collected
+	// before the batch response is written.
+	if collect != nil {
+		run()
+		return
+	}
+	go run()
+}
+
+func (c *Conn) handleCancel(params json.RawMessage) {
+	var p cancelParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		logger.Warn("jsonrpc2: malformed cancel notification", "error", err)
+		return
+	}
+
+	key := string(p.requestID())
+	c.handlingMu.Lock()
+	cancel, ok := c.handling[key]
+	c.handlingMu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (c *Conn) responseMessage(id json.RawMessage, result any, err error) *wireMessage {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return &wireMessage{JSONRPC: Version, ID: id, Error: &Error{Code: CodeRequestCancelled, Message: "Request cancelled"}}
+		}
+		return &wireMessage{JSONRPC: Version, ID: id, Error: NewError(-32603, err)}
+	}
+
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return &wireMessage{JSONRPC: Version, ID: id, Error: &Error{Code: -32603, Message: "Internal error: " + marshalErr.Error()}}
+	}
+	return &wireMessage{JSONRPC: Version, ID: id, Result: data}
+}
+
+// reply either hands msg to collect (inside a batch) or writes it straight
+// to the stream (outside of one).
+func (c *Conn) reply(collect func(*wireMessage), msg *wireMessage) {
+	if collect != nil {
+		collect(msg)
+		return
+	}
+	c.writeMessage(msg)
+}
+
+// completeCall delivers a response to the Call that's waiting on it.
+func (c *Conn) completeCall(msg *wireMessage) {
+	key := string(msg.ID)
+
+	c.pendingMu.Lock()
+	ch, ok := c.pending[key]
+	if ok {
+		delete(c.pending, key)
+	}
+	c.pendingMu.Unlock()
+
+	if !ok {
+		logger.Warn("jsonrpc2: response for unknown call", "id", key)
+		return
+	}
+	ch <- msg
+}
+
+// Call issues an outbound request to the peer and blocks until its response
+// arrives, ctx is canceled, or the Conn is closed.
+func (c *Conn) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	id := []byte(fmt.Sprintf("%d", c.seq.Add(1)))
+
+	paramsRaw, err := marshalParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal call params: %w", err)
+	}
+
+	ch := make(chan *wireMessage, 1)
+	key := string(id)
+	c.pendingMu.Lock()
+	c.pending[key] = ch
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, key)
+		c.pendingMu.Unlock()
+	}()
+
+	if err := c.writeMessage(&wireMessage{JSONRPC: Version, ID: id, Method: method, Params: paramsRaw}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = c.Notify(context.Background(), MethodCancelRequest, cancelParams{ID: id})
+		return nil, ctx.Err()
+	case msg := <-ch:
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+		return msg.Result, nil
+	}
+}
+
+// Notify sends a one-way notification to the peer; it never waits for (and
+// the peer must never send) a reply.
+func (c *Conn) Notify(ctx context.Context, method string, params any) error {
+	paramsRaw, err := marshalParams(params)
+	if err != nil {
+		return fmt.Errorf("marshal notify params: %w", err)
+	}
+	return c.writeMessage(&wireMessage{JSONRPC: Version, Method: method, Params: paramsRaw})
+}
+
+func (c *Conn) writeMessage(msg *wireMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.stream.Write(data)
+}
+
+func (c *Conn) writeBatch(msgs []*wireMessage) error {
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		return fmt.Errorf("marshal batch: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.stream.Write(data)
+}
+
+func marshalParams(params any) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(params)
+}
+
+func trimLeadingSpace(raw json.RawMessage) json.RawMessage {
+	i := 0
+	for i < len(raw) {
+		switch raw[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+			continue
+		}
+		break
+	}
+	return raw[i:]
+}
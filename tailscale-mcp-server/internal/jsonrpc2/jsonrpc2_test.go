@@ -0,0 +1,113 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// recordingStream is a Stream whose Write calls are captured for assertions;
+// Read is unused by these tests since they call dispatch directly.
+type recordingStream struct {
+	written []json.RawMessage
+}
+
+func (s *recordingStream) Read() (json.RawMessage, error) {
+	select {}
+}
+
+func (s *recordingStream) Write(msg json.RawMessage) error {
+	s.written = append(s.written, append(json.RawMessage(nil), msg...))
+	return nil
+}
+
+// echoHandler replies with whatever params it was given, for requests only.
+func echoHandler(ctx context.Context, reply Replier, req *Request) error {
+	return reply(ctx, req.Params, nil)
+}
+
+func newTestConn(stream *recordingStream) *Conn {
+	return NewConn(stream, echoHandler)
+}
+
+func TestDispatchBatch_Empty(t *testing.T) {
+	stream := &recordingStream{}
+	c := newTestConn(stream)
+
+	c.dispatch(context.Background(), json.RawMessage(`[]`))
+
+	if len(stream.written) != 1 {
+		t.Fatalf("expected exactly one write for an empty batch, got %d", len(stream.written))
+	}
+
+	var resp wireMessage
+	if err := json.Unmarshal(stream.written[0], &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Error == nil {
+		t.Fatal("expected an error object for an empty batch")
+	}
+	if resp.Error.Code != -32600 {
+		t.Errorf("expected code -32600, got %d", resp.Error.Code)
+	}
+	if string(resp.ID) != "null" {
+		t.Errorf("expected id to be explicit null, got %q", resp.ID)
+	}
+}
+
+func TestDispatchBatch_AllNotifications(t *testing.T) {
+	stream := &recordingStream{}
+	c := newTestConn(stream)
+
+	batch := `[{"jsonrpc":"2.0","method":"ping","params":1},{"jsonrpc":"2.0","method":"ping","params":2}]`
+	c.dispatch(context.Background(), json.RawMessage(batch))
+
+	if len(stream.written) != 0 {
+		t.Fatalf("expected no response for an all-notification batch, got %d writes", len(stream.written))
+	}
+}
+
+func TestDispatchBatch_SubmissionOrderAndMalformedEntry(t *testing.T) {
+	stream := &recordingStream{}
+	c := newTestConn(stream)
+
+	// The middle entry (a bare number) is valid JSON but not a valid
+	// request object; the other two are valid requests, so the reply array
+	// should still contain all three, in submission order.
+	batch := `[{"jsonrpc":"2.0","id":"1","method":"ping","params":1},42,{"jsonrpc":"2.0","id":"3","method":"ping","params":3}]`
+
+	c.dispatch(context.Background(), json.RawMessage(batch))
+
+	if len(stream.written) != 1 {
+		t.Fatalf("expected exactly one batch write, got %d", len(stream.written))
+	}
+
+	var replies []wireMessage
+	if err := json.Unmarshal(stream.written[0], &replies); err != nil {
+		t.Fatalf("failed to unmarshal batch response: %v", err)
+	}
+	if len(replies) != 3 {
+		t.Fatalf("expected 3 replies (1 success, 1 parse error, 1 success), got %d", len(replies))
+	}
+
+	if string(replies[0].ID) != `"1"` {
+		t.Errorf("expected first reply id \"1\", got %q", replies[0].ID)
+	}
+	if replies[0].Error != nil {
+		t.Errorf("expected first reply to succeed, got error: %v", replies[0].Error)
+	}
+
+	if replies[1].Error == nil || replies[1].Error.Code != -32700 {
+		t.Errorf("expected second reply to be a parse error, got %+v", replies[1])
+	}
+	if string(replies[1].ID) != "null" {
+		t.Errorf("expected malformed entry's id to be explicit null, got %q", replies[1].ID)
+	}
+
+	if string(replies[2].ID) != `"3"` {
+		t.Errorf("expected third reply id \"3\", got %q", replies[2].ID)
+	}
+	if replies[2].Error != nil {
+		t.Errorf("expected third reply to succeed, got error: %v", replies[2].Error)
+	}
+}
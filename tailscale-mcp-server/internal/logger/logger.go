@@ -2,7 +2,9 @@ package logger
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -12,11 +14,27 @@ import (
 )
 
 var (
-	initialized  bool
-	globalLogger *zap.Logger
-	loggerMutex  sync.RWMutex
+	initialized    bool
+	globalLogger   *zap.Logger
+	defaultService Service
+	loggerMutex    sync.RWMutex
 )
 
+// Default returns the process-wide Service every component should depend on
+// unless it has one injected explicitly. Its concrete backend (zapService
+// or slogService) is chosen by Initialize via LOG_BACKEND.
+func Default() Service {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	if defaultService != nil {
+		return defaultService
+	}
+	// Mirrors the "not initialized yet" fallback the free functions below
+	// use, so a Service obtained before Initialize still logs to stderr
+	// instead of panicking.
+	return newZapService(zap.NewExample(), zap.NewAtomicLevel())
+}
+
 // Initialize sets up the global logger with the specified level and optional file output
 func Initialize(level int, logFile string) error {
 	loggerMutex.Lock()
@@ -96,15 +114,32 @@ func Initialize(level int, logFile string) error {
 	config.OutputPaths = []string{"stderr"}
 	config.ErrorOutputPaths = []string{"stderr"}
 
+	// Forget sinks registered by a previous Initialize call before building
+	// the new logger, so ReopenFileSinks never tries to reopen a sink that
+	// belonged to a now-discarded logger (e.g. one under a test's
+	// already-removed TempDir).
+	resetFileSinks()
+
 	if logFile != "" {
-		config.OutputPaths = append(config.OutputPaths, logFile)
-		config.ErrorOutputPaths = append(config.ErrorOutputPaths, logFile)
+		absPath, err := filepath.Abs(logFile)
+		if err != nil {
+			return fmt.Errorf("resolve log file path: %w", err)
+		}
+		// A "reopen://" sink (registered in reopen.go) instead of the bare
+		// path, so ReopenFileSinks can pick up a logrotate-renamed file
+		// without restarting the process.
+		sinkURL := reopenScheme + "://" + absPath
+		config.OutputPaths = append(config.OutputPaths, sinkURL)
+		config.ErrorOutputPaths = append(config.ErrorOutputPaths, sinkURL)
 	}
 
 	// ---------------------------------------------------------------------
 	// Build the final logger
 	// ---------------------------------------------------------------------
-	logger, err := config.Build(zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zap.ErrorLevel))
+	logger, err := config.Build(
+		zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zap.ErrorLevel),
+		zap.WrapCore(newFanoutCore),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to build logger: %w", err)
 	}
@@ -112,9 +147,98 @@ func Initialize(level int, logFile string) error {
 	globalLogger = logger
 	initialized = true
 
+	switch backendFromEnv() {
+	case "slog":
+		// The log-forwarding sink (logger.AddSink) taps zapcore.Core
+		// directly, so it only sees records logged through the zap
+		// backend above; a client connected while LOG_BACKEND=slog won't
+		// receive notifications/message notifications.
+		defaultService = newSlogService(os.Stderr, levelVarFor(config.Level))
+	default:
+		defaultService = newZapService(globalLogger, config.Level)
+	}
+	configuredLevel = zapLevelToLogLevel(config.Level.Level())
+
 	return nil
 }
 
+// configuredLevel is the level Initialize was called with, so EnableDebug
+// and RestoreConfiguredLevel (wired to SIGUSR1/SIGUSR2 in cli.runServer)
+// know what "restore" means rather than hardcoding info.
+var configuredLevel LogLevel
+
+func zapLevelToLogLevel(level zapcore.Level) LogLevel {
+	switch level {
+	case zapcore.DebugLevel:
+		return LogLevelDebug
+	case zapcore.WarnLevel:
+		return LogLevelWarn
+	case zapcore.ErrorLevel:
+		return LogLevelError
+	default:
+		return LogLevelInfo
+	}
+}
+
+// SetLevel changes the verbosity of the default Service at runtime, without
+// rebuilding it — e.g. from a logging/setLevel MCP request, the PUT
+// /debug/loglevel HTTP admin endpoint, or a SIGUSR1/SIGUSR2 handler.
+func SetLevel(level LogLevel) {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	if defaultService != nil {
+		defaultService.SetLevel(level)
+	}
+}
+
+// EnableDebug switches the default Service to debug verbosity, e.g. from a
+// SIGUSR1 handler, until RestoreConfiguredLevel undoes it.
+func EnableDebug() {
+	SetLevel(LogLevelDebug)
+}
+
+// RestoreConfiguredLevel switches the default Service back to the level
+// Initialize was called with, undoing EnableDebug, e.g. from a SIGUSR2
+// handler.
+func RestoreConfiguredLevel() {
+	SetLevel(configuredLevel)
+}
+
+// ParseLevel maps a level name (as accepted by the PUT /debug/loglevel
+// admin endpoint) onto a LogLevel, reporting ok=false for anything else.
+func ParseLevel(name string) (level LogLevel, ok bool) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LogLevelDebug, true
+	case "info":
+		return LogLevelInfo, true
+	case "warn", "warning":
+		return LogLevelWarn, true
+	case "error":
+		return LogLevelError, true
+	default:
+		return 0, false
+	}
+}
+
+// levelVarFor mirrors a zap AtomicLevel's starting point into a fresh
+// *slog.LevelVar, so LOG_LEVEL/the verbose flag apply the same way
+// regardless of which Service backend LOG_BACKEND selects.
+func levelVarFor(zapLevel zap.AtomicLevel) *slog.LevelVar {
+	var v slog.LevelVar
+	switch zapLevel.Level() {
+	case zap.DebugLevel:
+		v.Set(slog.LevelDebug)
+	case zap.WarnLevel:
+		v.Set(slog.LevelWarn)
+	case zap.ErrorLevel:
+		v.Set(slog.LevelError)
+	default:
+		v.Set(slog.LevelInfo)
+	}
+	return &v
+}
+
 // Cleanup properly closes the logger and flushes any buffered log entries
 func Cleanup() error {
 	loggerMutex.RLock()
@@ -133,47 +257,48 @@ func isInitialized() bool {
 	return initialized && globalLogger != nil
 }
 
-// Debug logs a debug message
+// Debug logs a debug message via Default().
 func Debug(msg string, args ...any) {
 	if !isInitialized() {
 		fmt.Fprintf(os.Stderr, "DEBUG (logger not initialized): %s\n", msg)
 		return
 	}
-	globalLogger.Debug(msg, convertArgsToZapFields(args)...)
+	Default().Debug(msg, args...)
 }
 
-// Info logs an info message
+// Info logs an info message via Default().
 func Info(msg string, args ...any) {
 	if !isInitialized() {
 		fmt.Fprintf(os.Stderr, "INFO (logger not initialized): %s\n", msg)
 		return
 	}
-	globalLogger.Info(msg, convertArgsToZapFields(args)...)
+	Default().Info(msg, args...)
 }
 
-// Warn logs a warning message
+// Warn logs a warning message via Default().
 func Warn(msg string, args ...any) {
 	if !isInitialized() {
 		fmt.Fprintf(os.Stderr, "WARN (logger not initialized): %s\n", msg)
 		return
 	}
-	globalLogger.Warn(msg, convertArgsToZapFields(args)...)
+	Default().Warn(msg, args...)
 }
 
-// Error logs an error message
+// Error logs an error message via Default().
 func Error(msg string, args ...any) {
 	if !isInitialized() {
 		fmt.Fprintf(os.Stderr, "ERROR (logger not initialized): %s\n", msg)
 		return
 	}
-	globalLogger.Error(msg, convertArgsToZapFields(args)...)
+	Default().Error(msg, args...)
 }
 
-// Fatal logs a fatal error message and exits
+// Fatal logs a fatal error message via Default() and exits.
 func Fatal(msg string, args ...any) {
 	if isInitialized() {
-		globalLogger.Fatal(msg, convertArgsToZapFields(args)...)
-		// Fatal calls os.Exit(1) internally
+		Default().Fatal(msg, args...)
+		// Fatal exits the process internally (os.Exit(1) for zap and slog
+		// backends alike).
 	} else {
 		// Fallback if logger is nil
 		fmt.Fprintf(os.Stderr, "FATAL (logger not initialized): %s\n", msg)
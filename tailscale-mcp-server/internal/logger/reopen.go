@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// reopenScheme is the zap.Sink scheme Initialize uses for its file output,
+// in place of a bare path, so the file can be closed and reopened in place
+// (e.g. after logrotate renames it out from under the running process)
+// without restarting.
+const reopenScheme = "reopen"
+
+func init() {
+	// Returns an error (ignored here) if the scheme is already registered,
+	// but this runs exactly once per process via init().
+	_ = zap.RegisterSink(reopenScheme, func(u *url.URL) (zap.Sink, error) {
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		f, err := newReopenableFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileSinksMu.Lock()
+		fileSinks = append(fileSinks, f)
+		fileSinksMu.Unlock()
+		return f, nil
+	})
+}
+
+var (
+	fileSinksMu sync.Mutex
+	fileSinks   []*reopenableFile
+)
+
+// reopenableFile is a mutex-guarded *os.File that can be closed and
+// reopened in place, similar to the client9/reopen pattern: a SIGHUP
+// handler calls ReopenFileSinks after logrotate has renamed the file out
+// from under this process, so the next write lands in the newly created
+// file instead of the renamed (and eventually deleted) one, with no writes
+// lost in between.
+type reopenableFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+func newReopenableFile(path string) (*reopenableFile, error) {
+	f := &reopenableFile{path: path}
+	if err := f.reopen(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *reopenableFile) reopen() error {
+	next, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	prev := f.file
+	f.file = next
+	f.mu.Unlock()
+
+	if prev != nil {
+		_ = prev.Close()
+	}
+	return nil
+}
+
+// Write implements zap.Sink (via io.Writer). It holds the lock for the
+// whole call so a concurrent reopen can't swap the file out from under a
+// write that's already in flight.
+func (f *reopenableFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+func (f *reopenableFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+func (f *reopenableFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// resetFileSinks forgets every previously registered file sink, called at
+// the start of Initialize so a re-Initialize (tests routinely call it more
+// than once) doesn't leave ReopenFileSinks trying to reopen a sink from a
+// now-discarded logger, e.g. one under a test's already-removed TempDir.
+func resetFileSinks() {
+	fileSinksMu.Lock()
+	fileSinks = nil
+	fileSinksMu.Unlock()
+}
+
+// ReopenFileSinks closes and reopens every file sink Initialize created
+// (there's normally at most one, logFile), picking up whatever now exists
+// at the same path. Call it from a SIGHUP handler to support logrotate
+// without restarting the server.
+func ReopenFileSinks() error {
+	fileSinksMu.Lock()
+	sinks := append([]*reopenableFile(nil), fileSinks...)
+	fileSinksMu.Unlock()
+
+	var firstErr error
+	for _, f := range sinks {
+		if err := f.reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogLevel is the coarse verbosity level a Service is configured at,
+// matching the int convention Initialize has always accepted (0=debug,
+// 1=info, 2=warn, 3=error).
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (l LogLevel) zapLevel() zapcore.Level {
+	switch l {
+	case LogLevelDebug:
+		return zapcore.DebugLevel
+	case LogLevelWarn:
+		return zapcore.WarnLevel
+	case LogLevelError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+func (l LogLevel) slogLevel() slog.Level {
+	switch l {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Service is the logging interface every component in this server should
+// depend on instead of reaching for the package-level Debug/Info/... shims.
+// It's implemented by both zapService (the default) and slogService
+// (selected via LOG_BACKEND=slog), so swapping backends never touches a
+// call site.
+type Service interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+	Fatal(msg string, kv ...any)
+	With(kv ...any) Service
+	SetLevel(LogLevel)
+	Sync() error
+}
+
+// zapService is the default Service, backed by the same *zap.Logger the
+// package-level shims and internal/server's log-forwarding sink use.
+type zapService struct {
+	logger *zap.Logger
+	level  zap.AtomicLevel
+}
+
+func newZapService(logger *zap.Logger, level zap.AtomicLevel) *zapService {
+	return &zapService{logger: logger, level: level}
+}
+
+func (s *zapService) Debug(msg string, kv ...any) { s.logger.Debug(msg, convertArgsToZapFields(kv)...) }
+func (s *zapService) Info(msg string, kv ...any)  { s.logger.Info(msg, convertArgsToZapFields(kv)...) }
+func (s *zapService) Warn(msg string, kv ...any)  { s.logger.Warn(msg, convertArgsToZapFields(kv)...) }
+func (s *zapService) Error(msg string, kv ...any) { s.logger.Error(msg, convertArgsToZapFields(kv)...) }
+func (s *zapService) Fatal(msg string, kv ...any) { s.logger.Fatal(msg, convertArgsToZapFields(kv)...) }
+
+func (s *zapService) With(kv ...any) Service {
+	return &zapService{logger: s.logger.With(convertArgsToZapFields(kv)...), level: s.level}
+}
+
+func (s *zapService) SetLevel(level LogLevel) { s.level.SetLevel(level.zapLevel()) }
+func (s *zapService) Sync() error             { return s.logger.Sync() }
+
+// slogService is the log/slog-backed Service, selected via LOG_BACKEND=slog
+// for environments that would rather not pull in zap's structured output.
+type slogService struct {
+	logger *slog.Logger
+	level  *slog.LevelVar
+}
+
+func newSlogService(w *os.File, level *slog.LevelVar) *slogService {
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	return &slogService{logger: slog.New(handler), level: level}
+}
+
+func (s *slogService) Debug(msg string, kv ...any) { s.logger.Debug(msg, kv...) }
+func (s *slogService) Info(msg string, kv ...any)  { s.logger.Info(msg, kv...) }
+func (s *slogService) Warn(msg string, kv ...any)  { s.logger.Warn(msg, kv...) }
+func (s *slogService) Error(msg string, kv ...any) { s.logger.Error(msg, kv...) }
+
+func (s *slogService) Fatal(msg string, kv ...any) {
+	s.logger.Error(msg, kv...)
+	os.Exit(1)
+}
+
+func (s *slogService) With(kv ...any) Service {
+	return &slogService{logger: s.logger.With(kv...), level: s.level}
+}
+
+func (s *slogService) SetLevel(level LogLevel) { s.level.Set(level.slogLevel()) }
+func (s *slogService) Sync() error             { return nil }
+
+// backendFromEnv reports the Service implementation LOG_BACKEND selects,
+// defaulting to zap when unset or unrecognized.
+func backendFromEnv() string {
+	switch strings.ToLower(os.Getenv("LOG_BACKEND")) {
+	case "slog":
+		return "slog"
+	default:
+		return "zap"
+	}
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Level is one of the eight syslog-style severities the MCP logging
+// capability uses (RFC 5424), as sent in a logging/setLevel request or a
+// notifications/message notification's "level" field.
+type Level string
+
+const (
+	LevelDebug     Level = "debug"
+	LevelInfo      Level = "info"
+	LevelNotice    Level = "notice"
+	LevelWarning   Level = "warning"
+	LevelError     Level = "error"
+	LevelCritical  Level = "critical"
+	LevelAlert     Level = "alert"
+	LevelEmergency Level = "emergency"
+)
+
+// ZapLevel maps l onto the nearest zap level this logger actually
+// distinguishes between, reporting ok=false if l isn't one of the eight MCP
+// levels. zap has fewer levels than MCP's syslog-derived scale, so notice
+// collapses into info and critical/alert/emergency spread across
+// DPanic/Panic/Fatal.
+func (l Level) ZapLevel() (level zapcore.Level, ok bool) {
+	switch l {
+	case LevelDebug:
+		return zapcore.DebugLevel, true
+	case LevelInfo, LevelNotice:
+		return zapcore.InfoLevel, true
+	case LevelWarning:
+		return zapcore.WarnLevel, true
+	case LevelError:
+		return zapcore.ErrorLevel, true
+	case LevelCritical:
+		return zapcore.DPanicLevel, true
+	case LevelAlert:
+		return zapcore.PanicLevel, true
+	case LevelEmergency:
+		return zapcore.FatalLevel, true
+	default:
+		return 0, false
+	}
+}
+
+// MCPLevel reports the MCP level name a zap level should be forwarded under.
+func MCPLevel(level zapcore.Level) Level {
+	switch {
+	case level < zapcore.InfoLevel:
+		return LevelDebug
+	case level < zapcore.WarnLevel:
+		return LevelInfo
+	case level < zapcore.ErrorLevel:
+		return LevelWarning
+	case level < zapcore.DPanicLevel:
+		return LevelError
+	case level < zapcore.FatalLevel:
+		return LevelCritical
+	default:
+		return LevelEmergency
+	}
+}
+
+// sinkRegistry tracks the extra zapcore.Cores fanoutCore writes every
+// accepted record to, alongside the logger's own stderr/file output.
+type sinkRegistry struct {
+	mu    sync.RWMutex
+	next  int64
+	cores map[int64]zapcore.Core
+}
+
+func newSinkRegistry() *sinkRegistry {
+	return &sinkRegistry{cores: make(map[int64]zapcore.Core)}
+}
+
+func (r *sinkRegistry) add(core zapcore.Core) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	id := r.next
+	r.cores[id] = core
+	return id
+}
+
+func (r *sinkRegistry) remove(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cores, id)
+}
+
+func (r *sinkRegistry) snapshot() []zapcore.Core {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]zapcore.Core, 0, len(r.cores))
+	for _, core := range r.cores {
+		out = append(out, core)
+	}
+	return out
+}
+
+// globalSinks holds every sink added via AddSink, independent of whatever
+// *zap.Logger Initialize builds; it outlives any single Initialize call so a
+// sink registered before a re-Initialize (e.g. in tests) keeps working.
+var globalSinks = newSinkRegistry()
+
+// fanoutCore wraps the logger's real output core and additionally forwards
+// every record it accepts to whichever sinks are currently registered via
+// AddSink. Sinks are consulted dynamically (not baked in via zapcore.NewTee)
+// so one can be added or removed over the life of the process, e.g. as an
+// MCP client connects and disconnects.
+//
+// A With-derived child keeps its attached fields and hands them to every
+// sink registered at write time, so a sink added after the child was
+// created still sees them.
+type fanoutCore struct {
+	zapcore.Core
+	sinks  *sinkRegistry
+	fields []zapcore.Field
+}
+
+// newFanoutCore wraps core so logs it accepts also reach sinks registered
+// with AddSink.
+func newFanoutCore(core zapcore.Core) zapcore.Core {
+	return &fanoutCore{Core: core, sinks: globalSinks}
+}
+
+func (c *fanoutCore) Enabled(level zapcore.Level) bool {
+	if c.Core.Enabled(level) {
+		return true
+	}
+	for _, sink := range c.sinks.snapshot() {
+		if sink.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fanoutCore) With(fields []zapcore.Field) zapcore.Core {
+	return &fanoutCore{
+		Core:   c.Core.With(fields),
+		sinks:  c.sinks,
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+func (c *fanoutCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *fanoutCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var firstErr error
+
+	if c.Core.Enabled(entry.Level) {
+		if err := c.Core.Write(entry, fields); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	all := append(append([]zapcore.Field{}, c.fields...), fields...)
+	for _, sink := range c.sinks.snapshot() {
+		if sink.Enabled(entry.Level) {
+			if err := sink.Write(entry, all); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// AddSink attaches an extra zapcore.Core that receives a copy of every log
+// record the global logger accepts, independent of its configured
+// stderr/file output — e.g. one that forwards records to an MCP client as
+// notifications/message notifications. It returns a handle for RemoveSink.
+func AddSink(core zapcore.Core) int64 {
+	return globalSinks.add(core)
+}
+
+// RemoveSink detaches a sink previously returned by AddSink.
+func RemoveSink(id int64) {
+	globalSinks.remove(id)
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Notification methods the server can push to a connected client.
+const (
+	NotificationToolsListChanged = "notifications/tools/list_changed"
+	NotificationProgress         = "notifications/progress"
+	NotificationMessage          = "notifications/message"
+
+	// NotificationResourcesUpdated tells a client that subscribed to a
+	// resource (resources/subscribe) that its contents have changed.
+	NotificationResourcesUpdated = "notifications/resources/updated"
+
+	// RequestTypeSamplingCreateMessage is a server-initiated request asking
+	// the client to sample from its own model.
+	RequestTypeSamplingCreateMessage = "sampling/createMessage"
+)
+
+// Notifier lets an MCPServer push server-initiated notifications
+// (notifications/tools/list_changed, notifications/progress) and requests
+// (sampling/createMessage) back to whatever transport it's connected over.
+// *jsonrpc2.Conn satisfies this interface without mcp needing to import it.
+type Notifier interface {
+	Notify(ctx context.Context, method string, params any) error
+	Call(ctx context.Context, method string, params any) (json.RawMessage, error)
+}
+
+// ProgressParams is the payload of a notifications/progress notification.
+type ProgressParams struct {
+	ProgressToken string  `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+}
+
+// LogMessageParams is the payload of a notifications/message notification,
+// forwarding one record from the server's logger to the client.
+type LogMessageParams struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger,omitempty"`
+	Data   any    `json:"data"`
+}
+
+// ResourceUpdatedParams is the payload of a notifications/resources/updated
+// notification.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// NotificationTailscaleWebhook forwards one verified tailnet webhook event
+// (internal/webhooks) to every connected client, so an agent hears about
+// device/policy changes in real time instead of polling for them.
+const NotificationTailscaleWebhook = "notifications/tailscale/webhook"
+
+// TailscaleWebhookEventParams is the payload of a
+// notifications/tailscale/webhook notification.
+type TailscaleWebhookEventParams struct {
+	Type      string          `json:"type"`
+	Tailnet   string          `json:"tailnet,omitempty"`
+	Timestamp string          `json:"timestamp,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
@@ -2,24 +2,55 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"sync"
 
+	"github.com/hexsleeves/tailscale-mcp-server/internal/jsonrpc2"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tools"
 )
 
+// eventsResourceURI is the only resource this server exposes today: a live
+// feed of tailscale.Event notifications off the configured Client's Watch
+// stream.
+const eventsResourceURI = "tailscale://events"
+
 // MCPServer implements the MCP server interface, handling tool registration and execution.
 type MCPServer struct {
 	registry *tools.ToolRegistry
+	client   tailscale.Client
 	name     string
 	version  string
+	logger   logger.Service
+
+	// notifier, if set, lets this server push notifications and requests
+	// back to the connected client. It is nil until the transport (e.g.
+	// StdioServer) wires up its Conn via SetNotifier.
+	notifier Notifier
+
+	// resourceMu guards the tailscale://events resource's subscriber set
+	// and the last Event observed for it, and the one-time start of
+	// watchEvents below.
+	resourceMu   sync.Mutex
+	subscribers  map[Notifier]struct{}
+	watchStarted bool
+	watchCancel  context.CancelFunc
+	lastEvent    *tailscale.Event
 }
 
-// NewMCPServer creates a new MCP server instance.
-func NewMCPServer(registry *tools.ToolRegistry, name, version string) Server {
+// NewMCPServer creates a new MCP server instance, logging through svc
+// instead of the package-level logger shims. client, if non-nil, backs the
+// tailscale://events resource; a nil client leaves resources/list empty and
+// resources/read, resources/subscribe, and resources/unsubscribe erroring.
+func NewMCPServer(registry *tools.ToolRegistry, client tailscale.Client, name, version string, svc logger.Service) Server {
 	return &MCPServer{
-		registry: registry,
-		name:     name,
-		version:  version,
+		registry:    registry,
+		client:      client,
+		name:        name,
+		version:     version,
+		logger:      svc,
+		subscribers: make(map[Notifier]struct{}),
 	}
 }
 
@@ -29,17 +60,23 @@ func (s *MCPServer) Initialize(ctx context.Context, req *InitializeRequest) (*In
 		return nil, err
 	}
 
-	logger.Info("MCP server initialized", "client_info", req.ClientInfo)
+	// ValidateInitializeRequest already confirmed a version exists; if
+	// negotiation somehow fails anyway, fall back to our own default rather
+	// than echoing back something neither side agreed on.
+	negotiated, ok := NegotiateProtocolVersion(req.ProtocolVersion)
+	if !ok {
+		negotiated = ProtocolVersion
+	}
+
+	s.logger.Info("MCP server initialized", "client_info", req.ClientInfo, "protocol_version", negotiated)
 
 	return &InitializeResponse{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: negotiated,
 		ServerInfo: ServerInfo{
 			Name:    s.name,
 			Version: s.version,
 		},
-		Capabilities: ServerCapabilities{
-			Tools: &ToolsCapability{},
-		},
+		Capabilities: CapabilitiesForVersion(negotiated),
 	}, nil
 }
 
@@ -61,18 +98,32 @@ func (s *MCPServer) ListTools(ctx context.Context, req *ListToolsRequest) (*List
 	}, nil
 }
 
-// CallTool executes a tool from the tool registry.
+// CallTool executes a tool from the tool registry, through
+// ToolRegistry.ExecuteTool so its middleware chain (audit logging, metrics,
+// timeout, rate limiting, plus anything wired in via registry.Use) applies
+// the same way here as to any other caller of ExecuteTool. A request
+// carrying a progress token gets a tools.ProgressReporter plumbed into ctx,
+// so long-running tools can emit notifications/progress frames back over
+// whichever transport (stdio, or one HTTP session among several) actually
+// dispatched this call.
 func (s *MCPServer) CallTool(ctx context.Context, req *CallToolRequest) (*CallToolResponse, error) {
 	tool, ok := s.registry.GetTool(req.Name)
 	if !ok {
 		return nil, NewToolNotFoundError(req.Name)
 	}
 
-	// Create a new tool context
-	toolCtx := tools.NewContext(ctx, s.registry)
+	if err := ValidateToolArguments(tool.InputSchema(), req.Arguments); err != nil {
+		return nil, err
+	}
 
-	// Execute the tool
-	result, err := tool.Execute(toolCtx, req.Arguments)
+	if req.Meta != nil && req.Meta.ProgressToken != "" {
+		token := req.Meta.ProgressToken
+		ctx = tools.WithProgressReporter(ctx, func(ctx context.Context, progress, total float64) error {
+			return s.NotifyProgress(ctx, ProgressParams{ProgressToken: token, Progress: progress, Total: total})
+		})
+	}
+
+	result, err := s.registry.ExecuteTool(ctx, req.Name, req.Arguments)
 	if err != nil {
 		return nil, NewToolExecutionError(req.Name, err)
 	}
@@ -86,6 +137,178 @@ func (s *MCPServer) CallTool(ctx context.Context, req *CallToolRequest) (*CallTo
 
 // Shutdown handles server shutdown.
 func (s *MCPServer) Shutdown(ctx context.Context, req *ShutdownRequest) error {
-	logger.Info("MCP server shutting down")
+	s.logger.Info("MCP server shutting down")
+
+	s.resourceMu.Lock()
+	if s.watchCancel != nil {
+		s.watchCancel()
+	}
+	s.resourceMu.Unlock()
+
+	return nil
+}
+
+// ListResources returns the resources this server exposes: just
+// tailscale://events, and only when it was constructed with a Client.
+func (s *MCPServer) ListResources(ctx context.Context, req *ListResourcesRequest) (*ListResourcesResponse, error) {
+	if s.client == nil {
+		return &ListResourcesResponse{Resources: []Resource{}}, nil
+	}
+
+	return &ListResourcesResponse{
+		Resources: []Resource{
+			{
+				URI:         eventsResourceURI,
+				Description: "Streams tailscale.Event notifications (peers joining/leaving/going online or offline, netmap updates, exit node changes) as they happen; subscribe to receive notifications/resources/updated pushes.",
+				ContentType: "application/json",
+			},
+		},
+	}, nil
+}
+
+// ReadResource returns the last Event observed off the client's Watch
+// stream, or an empty NetMapUpdated snapshot of the current status if
+// nothing has been observed yet.
+func (s *MCPServer) ReadResource(ctx context.Context, req *ReadResourceRequest) (*ReadResourceResponse, error) {
+	if req.URI != eventsResourceURI || s.client == nil {
+		return nil, NewResourceNotFoundError(req.URI)
+	}
+
+	s.resourceMu.Lock()
+	event := s.lastEvent
+	s.resourceMu.Unlock()
+
+	if event == nil {
+		status, err := s.client.Status(ctx)
+		if err != nil {
+			return nil, NewInternalError(err.Error())
+		}
+		event = &tailscale.Event{Kind: tailscale.EventNetMapUpdated, Status: status}
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, NewInternalError(err.Error())
+	}
+
+	return &ReadResourceResponse{
+		Contents: []ResourceContents{
+			{URI: eventsResourceURI, MimeType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// SubscribeResource registers the calling connection to receive
+// notifications/resources/updated pushes for tailscale://events, starting
+// the background watch loop on the first subscriber.
+func (s *MCPServer) SubscribeResource(ctx context.Context, req *SubscribeResourceRequest) error {
+	if req.URI != eventsResourceURI || s.client == nil {
+		return NewResourceNotFoundError(req.URI)
+	}
+
+	notifier := s.notifierFromContext(ctx)
+	if notifier == nil {
+		return NewInternalError("no connection to subscribe on")
+	}
+
+	s.resourceMu.Lock()
+	s.subscribers[notifier] = struct{}{}
+	needsStart := !s.watchStarted
+	if needsStart {
+		s.watchStarted = true
+	}
+	s.resourceMu.Unlock()
+
+	if needsStart {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		s.resourceMu.Lock()
+		s.watchCancel = cancel
+		s.resourceMu.Unlock()
+		go s.watchEvents(watchCtx)
+	}
+
+	return nil
+}
+
+// UnsubscribeResource stops pushing notifications/resources/updated to the
+// calling connection for tailscale://events.
+func (s *MCPServer) UnsubscribeResource(ctx context.Context, req *UnsubscribeResourceRequest) error {
+	if req.URI != eventsResourceURI || s.client == nil {
+		return NewResourceNotFoundError(req.URI)
+	}
+
+	notifier := s.notifierFromContext(ctx)
+	s.resourceMu.Lock()
+	delete(s.subscribers, notifier)
+	s.resourceMu.Unlock()
+
 	return nil
 }
+
+// watchEvents runs for as long as this server is up once at least one
+// subscriber has ever subscribed, caching every Event off s.client.Watch as
+// lastEvent and pushing notifications/resources/updated to every currently
+// subscribed connection.
+func (s *MCPServer) watchEvents(ctx context.Context) {
+	eventCh, err := s.client.Watch(ctx)
+	if err != nil {
+		s.logger.Error("failed to start tailscale://events watch", "error", err)
+		return
+	}
+
+	for event := range eventCh {
+		event := event
+		s.resourceMu.Lock()
+		s.lastEvent = &event
+		notifiers := make([]Notifier, 0, len(s.subscribers))
+		for n := range s.subscribers {
+			notifiers = append(notifiers, n)
+		}
+		s.resourceMu.Unlock()
+
+		for _, n := range notifiers {
+			if err := n.Notify(ctx, NotificationResourcesUpdated, ResourceUpdatedParams{URI: eventsResourceURI}); err != nil {
+				s.logger.Debug("failed to push resources/updated", "error", err)
+			}
+		}
+	}
+}
+
+// SetNotifier wires up a fallback transport this server should push
+// server-initiated notifications and requests over when a call's own ctx
+// doesn't carry one (see notifierFromContext). Safe to leave unset; calls
+// to NotifyToolsListChanged etc. are then no-ops for any such call.
+func (s *MCPServer) SetNotifier(n Notifier) {
+	s.notifier = n
+}
+
+// notifierFromContext resolves the Notifier to push a server-initiated
+// message through for this call: the jsonrpc2.Conn that dispatched it, if
+// any, so each concurrent HTTP session's notifications reach that session
+// specifically rather than whichever connection happened to call
+// SetNotifier. Falls back to s.notifier (stdio's single connection, wired
+// once at startup) when ctx carries none.
+func (s *MCPServer) notifierFromContext(ctx context.Context) Notifier {
+	if conn, ok := jsonrpc2.ConnFromContext(ctx); ok {
+		return conn
+	}
+	return s.notifier
+}
+
+// NotifyToolsListChanged tells the client its cached tools/list is stale.
+func (s *MCPServer) NotifyToolsListChanged(ctx context.Context) error {
+	notifier := s.notifierFromContext(ctx)
+	if notifier == nil {
+		return nil
+	}
+	return notifier.Notify(ctx, NotificationToolsListChanged, nil)
+}
+
+// NotifyProgress reports progress on a long-running tool call.
+func (s *MCPServer) NotifyProgress(ctx context.Context, progress ProgressParams) error {
+	notifier := s.notifierFromContext(ctx)
+	if notifier == nil {
+		return nil
+	}
+	return notifier.Notify(ctx, NotificationProgress, progress)
+}
@@ -1,19 +1,35 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
 )
 
 // Protocol constants
 const (
+	// ProtocolVersion is the newest MCP revision this server speaks; it's
+	// also the last entry of SupportedProtocolVersions.
 	ProtocolVersion = "2024-11-05"
 
 	// Request types
-	RequestTypeInitialize = "initialize"
-	RequestTypeListTools  = "tools/list"
-	RequestTypeCallTool   = "tools/call"
-	RequestTypeShutdown   = "shutdown"
+	RequestTypeInitialize  = "initialize"
+	RequestTypeListTools   = "tools/list"
+	RequestTypeCallTool    = "tools/call"
+	RequestTypeShutdown    = "shutdown"
+	RequestTypeSetLogLevel = "logging/setLevel"
+
+	RequestTypeListResources       = "resources/list"
+	RequestTypeReadResource        = "resources/read"
+	RequestTypeSubscribeResource   = "resources/subscribe"
+	RequestTypeUnsubscribeResource = "resources/unsubscribe"
+
+	// NotificationTypeInitialized is the notification a client sends once it
+	// has finished processing the initialize response, per the MCP spec. It
+	// carries no params and expects no reply.
+	NotificationTypeInitialized = "notifications/initialized"
 
 	// Response types
 	ResponseTypeInitialized = "initialized"
@@ -31,18 +47,134 @@ const (
 	ErrorCodeUnsupportedProtocol = -32000
 	ErrorCodeToolNotFound        = -32001
 	ErrorCodeToolExecutionError  = -32002
+
+	// ErrorCodeUnauthorized is returned when a transport-level auth check
+	// (see server.AuthConfig) rejects a request before it ever reaches a
+	// handler, e.g. a missing or invalid bearer token/API key, or a TLS
+	// handshake without a client certificate when mTLS is required.
+	ErrorCodeUnauthorized = -32003
+
+	// ErrorCodeRequestCancelled mirrors LSP's RequestCancelled: returned for
+	// a request whose context was canceled (e.g. via $/cancelRequest or
+	// notifications/cancelled) before its handler finished.
+	ErrorCodeRequestCancelled = -32800
+
+	// ErrorCodeSessionNotFound is returned when a Streamable HTTP request
+	// carries an Mcp-Session-Id that the server doesn't recognize, either
+	// because it was never issued or the session has since been torn down.
+	// The HTTP transport maps this to a 404, per the Streamable HTTP spec.
+	ErrorCodeSessionNotFound = -32004
+
+	// ErrorCodeResourceNotFound is returned for resources/read,
+	// resources/subscribe, or resources/unsubscribe naming a uri this
+	// server doesn't expose.
+	ErrorCodeResourceNotFound = -32005
 )
 
-// Base message types
-type Message[T any, R any] struct {
+// SupportedProtocolVersions lists every MCP revision this server can
+// negotiate, oldest first. A client's initialize request is matched against
+// this list by NegotiateProtocolVersion rather than by exact equality, so a
+// client on a newer revision still gets a version the server understands
+// instead of an outright rejection.
+var SupportedProtocolVersions = []string{ProtocolVersion}
+
+// Message is implemented by Call, Notification, and Response: the three
+// concrete shapes a decoded JSON-RPC 2.0 message can take. The method is
+// unexported so nothing outside this package can add a fourth variant,
+// which keeps a type-switch over Message exhaustive.
+type Message interface {
+	isMessage()
+}
+
+// Call is an inbound or outbound JSON-RPC request that expects a Response.
+// Params stays raw JSON; callers unmarshal it into the struct for their
+// specific method (InitializeRequest, CallToolRequest, ...) after the
+// type-switch, instead of every handler re-parsing a generic envelope.
+type Call struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"` // number, string, or null
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+func (*Call) isMessage() {}
+
+// Notification is a one-way JSON-RPC message: no ID, and no Response is
+// ever sent back for it.
+type Notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+func (*Notification) isMessage() {}
+
+// Response answers a Call, carrying exactly one of Result or Error.
+type Response struct {
 	JSONRPC string          `json:"jsonrpc"`
-	ID      json.RawMessage `json:"id,omitempty"` // number, string or null
+	ID      json.RawMessage `json:"id"` // number, string, or null
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+func (*Response) isMessage() {}
+
+// wireEnvelope is the superset shape used only to classify a raw message;
+// DecodeMessage always returns one of Call, Notification, or Response, never
+// the envelope itself.
+type wireEnvelope struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
 	Method  string          `json:"method,omitempty"`
-	Params  *T              `json:"params,omitempty"`
-	Result  *R              `json:"result,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *Error          `json:"error,omitempty"`
 }
 
+// DecodeMessage parses a single JSON-RPC 2.0 message and returns the
+// concrete Message variant it represents, so a dispatcher can type-switch
+// on the result instead of unmarshaling a generic envelope itself and
+// branching on which fields happen to be set.
+func DecodeMessage(data []byte) (Message, error) {
+	var env wireEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, NewParseError(err.Error())
+	}
+
+	if !isValidID(env.ID) {
+		return nil, NewInvalidRequestError("id must be a string, number, or null")
+	}
+
+	switch {
+	case env.Method != "" && len(env.ID) == 0:
+		return &Notification{JSONRPC: Version, Method: env.Method, Params: env.Params}, nil
+	case env.Method != "":
+		return &Call{JSONRPC: Version, ID: env.ID, Method: env.Method, Params: env.Params}, nil
+	case len(env.ID) > 0:
+		return &Response{JSONRPC: Version, ID: env.ID, Result: env.Result, Error: env.Error}, nil
+	default:
+		return nil, NewInvalidRequestError("message has neither method nor id")
+	}
+}
+
+// isValidID reports whether raw is a JSON-RPC 2.0 id: absent, or a JSON
+// string, number, or null. An object or array id is invalid.
+func isValidID(raw json.RawMessage) bool {
+	if len(raw) == 0 {
+		return true
+	}
+	switch raw[0] {
+	case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	default:
+		return string(raw) == "null"
+	}
+}
+
+// Version is the JSON-RPC protocol version every Call, Notification, and
+// Response carries on the wire.
+const Version = "2.0"
+
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -78,18 +210,73 @@ type ServerInfo struct {
 }
 
 type ServerCapabilities struct {
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Tools     *ToolsCapability    `json:"tools,omitempty"`
 	Resources *ResourceCapability `json:"resources,omitempty"`
+	Prompts   *PromptsCapability  `json:"prompts,omitempty"`
+	Sampling  *SamplingCapability `json:"sampling,omitempty"`
+	Logging   *LoggingCapability  `json:"logging,omitempty"`
 }
 
 type ToolsCapability struct {
 	ListChanged *bool `json:"listChanged,omitempty"`
 }
 
+// ResourceCapability advertises which resource operations this server
+// supports beyond the baseline resources/list and resources/read: Subscribe
+// for resources/subscribe + notifications/resources/updated.
 type ResourceCapability struct {
+	Subscribe bool `json:"subscribe,omitempty"`
+}
+
+type PromptsCapability struct {
+	// No specific capabilities defined yet
+}
+
+type SamplingCapability struct {
+	// No specific capabilities defined yet
+}
+
+// LoggingCapability indicates the server accepts logging/setLevel requests
+// and pushes notifications/message notifications for its own log records.
+type LoggingCapability struct {
 	// No specific capabilities defined yet
 }
 
+// protocolFeatures gates which optional capabilities ServerCapabilities
+// advertises for a given negotiated protocol version, so adding a new MCP
+// revision's resources/prompts/sampling support is a table entry here
+// rather than a change to every Initialize call site.
+var protocolFeatures = map[string]struct {
+	resources bool
+	prompts   bool
+	sampling  bool
+	logging   bool
+}{
+	ProtocolVersion: {logging: true, resources: true},
+}
+
+// CapabilitiesForVersion builds the ServerCapabilities this server
+// advertises for a negotiated protocol version. Tools are always on; the
+// rest follow protocolFeatures.
+func CapabilitiesForVersion(version string) ServerCapabilities {
+	caps := ServerCapabilities{Tools: &ToolsCapability{}}
+
+	features := protocolFeatures[version]
+	if features.resources {
+		caps.Resources = &ResourceCapability{Subscribe: true}
+	}
+	if features.prompts {
+		caps.Prompts = &PromptsCapability{}
+	}
+	if features.sampling {
+		caps.Sampling = &SamplingCapability{}
+	}
+	if features.logging {
+		caps.Logging = &LoggingCapability{}
+	}
+	return caps
+}
+
 // Tools
 type Tool struct {
 	Name        string `json:"name"`
@@ -106,6 +293,16 @@ type ListToolsResponse struct {
 type CallToolRequest struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Meta      *RequestMeta    `json:"_meta,omitempty"`
+}
+
+// RequestMeta carries the MCP spec's generic per-request "_meta" fields.
+// Only ProgressToken is used so far.
+type RequestMeta struct {
+	// ProgressToken, if set, asks the server to emit notifications/progress
+	// frames as CallTool runs, carrying this same token back so the client
+	// can match them to the request (see MCPServer.CallTool).
+	ProgressToken string `json:"progressToken,omitempty"`
 }
 
 type CallToolResponse struct {
@@ -126,14 +323,61 @@ type Resource struct {
 	Content     any    `json:"content,omitempty"`
 }
 
+// ListResourcesRequest is the (currently empty) payload of a resources/list
+// request.
+type ListResourcesRequest struct{}
+
+type ListResourcesResponse struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ReadResourceRequest is the payload of a resources/read request.
+type ReadResourceRequest struct {
+	URI string `json:"uri"`
+}
+
+type ReadResourceResponse struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents is one item of a resources/read response, mirroring the
+// MCP spec's resource content shape (a resource may answer with several,
+// though this server's resources only ever return one).
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// SubscribeResourceRequest is the payload of a resources/subscribe request.
+type SubscribeResourceRequest struct {
+	URI string `json:"uri"`
+}
+
+// UnsubscribeResourceRequest is the payload of a resources/unsubscribe
+// request.
+type UnsubscribeResourceRequest struct {
+	URI string `json:"uri"`
+}
+
 // Shutdown
 type ShutdownRequest struct{}
 
+// SetLevelRequest is the payload of a logging/setLevel request: the
+// minimum severity the client wants forwarded as notifications/message.
+type SetLevelRequest struct {
+	Level string `json:"level"`
+}
+
 // Server interface
 type Server interface {
 	Initialize(ctx context.Context, req *InitializeRequest) (*InitializeResponse, error)
 	ListTools(ctx context.Context, req *ListToolsRequest) (*ListToolsResponse, error)
 	CallTool(ctx context.Context, req *CallToolRequest) (*CallToolResponse, error)
+	ListResources(ctx context.Context, req *ListResourcesRequest) (*ListResourcesResponse, error)
+	ReadResource(ctx context.Context, req *ReadResourceRequest) (*ReadResourceResponse, error)
+	SubscribeResource(ctx context.Context, req *SubscribeResourceRequest) error
+	UnsubscribeResource(ctx context.Context, req *UnsubscribeResourceRequest) error
 	Shutdown(ctx context.Context, req *ShutdownRequest) error
 }
 
@@ -161,45 +405,58 @@ func NewSuccessResponse(text string) *CallToolResponse {
 
 // Message factory functions for type-safe construction
 
-// NewRequest creates a new request message with proper JSONRPC version and method.
-// Only the Params field should be set for requests.
-func NewRequest[T any](id json.RawMessage, method string, params *T) *Message[T, any] {
-	return &Message[T, any]{
-		JSONRPC: "2.0",
-		ID:      id,
-		Method:  method,
-		Params:  params,
+// NewCall builds a *Call, marshaling params (which may be nil) to the raw
+// JSON its Params field carries.
+func NewCall(id json.RawMessage, method string, params any) (*Call, error) {
+	raw, err := marshalParams(params)
+	if err != nil {
+		return nil, err
 	}
+	return &Call{JSONRPC: Version, ID: id, Method: method, Params: raw}, nil
 }
 
-// NewResponse creates a new response message with proper JSONRPC version.
-// Only the Result field should be set for successful responses.
-func NewResponse[R any](id json.RawMessage, result *R) *Message[any, R] {
-	return &Message[any, R]{
-		JSONRPC: "2.0",
-		ID:      id,
-		Result:  result,
+// NewResponseMessage builds a *Response carrying result as its Result field.
+func NewResponseMessage(id json.RawMessage, result any) (*Response, error) {
+	raw, err := marshalParams(result)
+	if err != nil {
+		return nil, err
 	}
+	return &Response{JSONRPC: Version, ID: id, Result: raw}, nil
 }
 
-// NewErrorMessage creates a new error response message with proper JSONRPC version.
-// Only the Error field should be set for error responses.
-func NewErrorMessage(id json.RawMessage, err *Error) *Message[any, any] {
-	return &Message[any, any]{
-		JSONRPC: "2.0",
-		ID:      id,
-		Error:   err,
+// NewErrorMessage builds a *Response carrying err as its Error field.
+func NewErrorMessage(id json.RawMessage, err *Error) *Response {
+	return &Response{JSONRPC: Version, ID: id, Error: err}
+}
+
+// NewNotificationMessage builds a *Notification, marshaling params (which
+// may be nil) to the raw JSON its Params field carries.
+func NewNotificationMessage(method string, params any) (*Notification, error) {
+	raw, err := marshalParams(params)
+	if err != nil {
+		return nil, err
 	}
+	return &Notification{JSONRPC: Version, Method: method, Params: raw}, nil
+}
+
+// NewProgressNotification builds a *Notification for a notifications/progress
+// message, the wire form of a ProgressParams a long-running tool call emits
+// while it still has a progressToken to report against.
+func NewProgressNotification(params ProgressParams) (*Notification, error) {
+	return NewNotificationMessage(NotificationProgress, params)
 }
 
-// NewNotification creates a new notification message (request without ID).
-// Notifications don't expect a response.
-func NewNotification[T any](method string, params *T) *Message[T, any] {
-	return &Message[T, any]{
-		JSONRPC: "2.0",
-		Method:  method,
-		Params:  params,
+// marshalParams marshals v to raw JSON, leaving a nil v as nil raw JSON
+// rather than the literal "null".
+func marshalParams(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal params: %w", err)
 	}
+	return raw, nil
 }
 
 // Error constructors for common MCP errors
@@ -282,14 +539,71 @@ func NewToolExecutionError(toolName string, err error) *Error {
 	}
 }
 
+// NewUnauthorizedError creates an error for a request a transport-level auth
+// check rejected, reason being a short human-readable explanation (e.g.
+// "missing bearer token") that is safe to return to the caller.
+func NewUnauthorizedError(reason string) *Error {
+	return &Error{
+		Code:    ErrorCodeUnauthorized,
+		Message: "Unauthorized",
+		Data:    map[string]string{"reason": reason},
+	}
+}
+
+// NewSessionNotFoundError creates an error for a Streamable HTTP request
+// whose Mcp-Session-Id header named a session the server doesn't recognize.
+func NewSessionNotFoundError(sessionID string) *Error {
+	return &Error{
+		Code:    ErrorCodeSessionNotFound,
+		Message: "Session not found",
+		Data:    map[string]string{"sessionId": sessionID},
+	}
+}
+
+// NewResourceNotFoundError creates an error for a resources/read,
+// resources/subscribe, or resources/unsubscribe request naming an unknown
+// uri.
+func NewResourceNotFoundError(uri string) *Error {
+	return &Error{
+		Code:    ErrorCodeResourceNotFound,
+		Message: "Resource not found",
+		Data:    map[string]string{"uri": uri},
+	}
+}
+
+// NewRequestCancelledError creates a request-cancelled error for a tool call
+// whose context was canceled before it finished.
+func NewRequestCancelledError(toolName string) *Error {
+	return &Error{
+		Code:    ErrorCodeRequestCancelled,
+		Message: "Request cancelled",
+		Data:    map[string]string{"tool": toolName},
+	}
+}
+
 // Protocol version compatibility checking
 
-// IsCompatibleProtocolVersion checks if the client protocol version is compatible
-// with the server's supported version
+// NegotiateProtocolVersion picks the highest entry of
+// SupportedProtocolVersions that is lexicographically <= clientVersion
+// (MCP versions are dated strings, e.g. "2024-11-05", so string comparison
+// orders them correctly). It reports ok=false only when even the oldest
+// supported version is newer than what the client asked for, i.e. there's
+// no version both sides can speak.
+func NegotiateProtocolVersion(clientVersion string) (version string, ok bool) {
+	for _, v := range SupportedProtocolVersions {
+		if v <= clientVersion {
+			version = v
+			ok = true
+		}
+	}
+	return version, ok
+}
+
+// IsCompatibleProtocolVersion reports whether NegotiateProtocolVersion can
+// find a version to speak with clientVersion.
 func IsCompatibleProtocolVersion(clientVersion string) bool {
-	// For now, we only support the exact version
-	// In the future, this could be enhanced to support version ranges
-	return clientVersion == ProtocolVersion
+	_, ok := NegotiateProtocolVersion(clientVersion)
+	return ok
 }
 
 // ValidateInitializeRequest validates an initialize request and returns an error if invalid
@@ -316,3 +630,24 @@ func ValidateInitializeRequest(req *InitializeRequest) *Error {
 
 	return nil
 }
+
+// ValidateToolArguments decodes args against a fresh value of schema's type
+// (as returned by Tool.InputSchema), rejecting unknown fields and type
+// mismatches before a tool's Execute ever sees them. A nil/empty args is
+// treated as an empty object, so tools whose input schema has no required
+// fields can still be called with no arguments at all.
+func ValidateToolArguments(schema any, args json.RawMessage) *Error {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	target := reflect.New(reflect.TypeOf(schema)).Interface()
+
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return NewInvalidParamsError(err.Error())
+	}
+
+	return nil
+}
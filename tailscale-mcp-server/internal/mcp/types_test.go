@@ -6,8 +6,8 @@ import (
 )
 
 func TestMessageFactoryFunctions(t *testing.T) {
-	// Test NewRequest
-	t.Run("NewRequest", func(t *testing.T) {
+	// Test NewCall
+	t.Run("NewCall", func(t *testing.T) {
 		id := json.RawMessage(`"test-id"`)
 		method := "test/method"
 		params := &InitializeRequest{
@@ -16,7 +16,10 @@ func TestMessageFactoryFunctions(t *testing.T) {
 			ClientInfo:      ClientInfo{Name: "test", Version: "1.0"},
 		}
 
-		msg := NewRequest(id, method, params)
+		msg, err := NewCall(id, method, params)
+		if err != nil {
+			t.Fatalf("NewCall returned an error: %v", err)
+		}
 
 		if msg.JSONRPC != "2.0" {
 			t.Errorf("Expected JSONRPC to be '2.0', got '%s'", msg.JSONRPC)
@@ -30,16 +33,10 @@ func TestMessageFactoryFunctions(t *testing.T) {
 		if msg.Params == nil {
 			t.Error("Expected Params to be set")
 		}
-		if msg.Result != nil {
-			t.Error("Expected Result to be nil for request")
-		}
-		if msg.Error != nil {
-			t.Error("Expected Error to be nil for request")
-		}
 	})
 
-	// Test NewResponse
-	t.Run("NewResponse", func(t *testing.T) {
+	// Test NewResponseMessage
+	t.Run("NewResponseMessage", func(t *testing.T) {
 		id := json.RawMessage(`123`)
 		result := &InitializeResponse{
 			ProtocolVersion: "2024-11-05",
@@ -47,7 +44,10 @@ func TestMessageFactoryFunctions(t *testing.T) {
 			ServerInfo:      ServerInfo{Name: "test-server", Version: "1.0"},
 		}
 
-		msg := NewResponse(id, result)
+		msg, err := NewResponseMessage(id, result)
+		if err != nil {
+			t.Fatalf("NewResponseMessage returned an error: %v", err)
+		}
 
 		if msg.JSONRPC != "2.0" {
 			t.Errorf("Expected JSONRPC to be '2.0', got '%s'", msg.JSONRPC)
@@ -55,12 +55,6 @@ func TestMessageFactoryFunctions(t *testing.T) {
 		if string(msg.ID) != "123" {
 			t.Errorf("Expected ID to be '123', got '%s'", string(msg.ID))
 		}
-		if msg.Method != "" {
-			t.Errorf("Expected Method to be empty for response, got '%s'", msg.Method)
-		}
-		if msg.Params != nil {
-			t.Error("Expected Params to be nil for response")
-		}
 		if msg.Result == nil {
 			t.Error("Expected Result to be set")
 		}
@@ -86,12 +80,6 @@ func TestMessageFactoryFunctions(t *testing.T) {
 		if string(msg.ID) != "null" {
 			t.Errorf("Expected ID to be 'null', got '%s'", string(msg.ID))
 		}
-		if msg.Method != "" {
-			t.Errorf("Expected Method to be empty for error response, got '%s'", msg.Method)
-		}
-		if msg.Params != nil {
-			t.Error("Expected Params to be nil for error response")
-		}
 		if msg.Result != nil {
 			t.Error("Expected Result to be nil for error response")
 		}
@@ -103,8 +91,8 @@ func TestMessageFactoryFunctions(t *testing.T) {
 		}
 	})
 
-	// Test NewNotification
-	t.Run("NewNotification", func(t *testing.T) {
+	// Test NewNotificationMessage
+	t.Run("NewNotificationMessage", func(t *testing.T) {
 		method := "notification/method"
 		params := &struct {
 			Message string `json:"message"`
@@ -112,32 +100,26 @@ func TestMessageFactoryFunctions(t *testing.T) {
 			Message: "test notification",
 		}
 
-		msg := NewNotification(method, params)
+		msg, err := NewNotificationMessage(method, params)
+		if err != nil {
+			t.Fatalf("NewNotificationMessage returned an error: %v", err)
+		}
 
 		if msg.JSONRPC != "2.0" {
 			t.Errorf("Expected JSONRPC to be '2.0', got '%s'", msg.JSONRPC)
 		}
-		if msg.ID != nil {
-			t.Errorf("Expected ID to be nil for notification, got '%s'", string(msg.ID))
-		}
 		if msg.Method != method {
 			t.Errorf("Expected Method to be '%s', got '%s'", method, msg.Method)
 		}
 		if msg.Params == nil {
 			t.Error("Expected Params to be set")
 		}
-		if msg.Result != nil {
-			t.Error("Expected Result to be nil for notification")
-		}
-		if msg.Error != nil {
-			t.Error("Expected Error to be nil for notification")
-		}
 	})
 }
 
 func TestMessageSerialization(t *testing.T) {
 	// Test that factory-created messages serialize correctly
-	t.Run("Request serialization", func(t *testing.T) {
+	t.Run("Call serialization", func(t *testing.T) {
 		id := json.RawMessage(`"req-1"`)
 		method := RequestTypeInitialize
 		params := &InitializeRequest{
@@ -146,11 +128,14 @@ func TestMessageSerialization(t *testing.T) {
 			ClientInfo:      ClientInfo{Name: "test-client", Version: "1.0.0"},
 		}
 
-		msg := NewRequest(id, method, params)
+		msg, err := NewCall(id, method, params)
+		if err != nil {
+			t.Fatalf("NewCall returned an error: %v", err)
+		}
 
 		data, err := json.Marshal(msg)
 		if err != nil {
-			t.Fatalf("Failed to marshal request: %v", err)
+			t.Fatalf("Failed to marshal call: %v", err)
 		}
 
 		// Verify the JSON contains expected fields
@@ -181,7 +166,10 @@ func TestMessageSerialization(t *testing.T) {
 			ServerInfo:      ServerInfo{Name: "test-server", Version: "1.0.0"},
 		}
 
-		msg := NewResponse(id, result)
+		msg, err := NewResponseMessage(id, result)
+		if err != nil {
+			t.Fatalf("NewResponseMessage returned an error: %v", err)
+		}
 
 		data, err := json.Marshal(msg)
 		if err != nil {
@@ -213,6 +201,54 @@ func TestMessageSerialization(t *testing.T) {
 	})
 }
 
+func TestDecodeMessage(t *testing.T) {
+	t.Run("decodes a call", func(t *testing.T) {
+		msg, err := DecodeMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		if err != nil {
+			t.Fatalf("DecodeMessage returned an error: %v", err)
+		}
+		call, ok := msg.(*Call)
+		if !ok {
+			t.Fatalf("Expected *Call, got %T", msg)
+		}
+		if call.Method != "tools/list" {
+			t.Errorf("Expected method 'tools/list', got '%s'", call.Method)
+		}
+	})
+
+	t.Run("decodes a notification", func(t *testing.T) {
+		msg, err := DecodeMessage([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled"}`))
+		if err != nil {
+			t.Fatalf("DecodeMessage returned an error: %v", err)
+		}
+		if _, ok := msg.(*Notification); !ok {
+			t.Fatalf("Expected *Notification, got %T", msg)
+		}
+	})
+
+	t.Run("decodes a response", func(t *testing.T) {
+		msg, err := DecodeMessage([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		if err != nil {
+			t.Fatalf("DecodeMessage returned an error: %v", err)
+		}
+		if _, ok := msg.(*Response); !ok {
+			t.Fatalf("Expected *Response, got %T", msg)
+		}
+	})
+
+	t.Run("rejects a non-scalar id", func(t *testing.T) {
+		if _, err := DecodeMessage([]byte(`{"jsonrpc":"2.0","id":{"bad":true},"method":"tools/list"}`)); err == nil {
+			t.Error("Expected an error for an object id")
+		}
+	})
+
+	t.Run("rejects malformed JSON", func(t *testing.T) {
+		if _, err := DecodeMessage([]byte(`not json`)); err == nil {
+			t.Error("Expected an error for malformed JSON")
+		}
+	})
+}
+
 func TestErrorConstructors(t *testing.T) {
 	t.Run("NewParseError", func(t *testing.T) {
 		err := NewParseError("invalid json")
@@ -379,3 +415,44 @@ func TestValidateInitializeRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateToolArguments(t *testing.T) {
+	type schema struct {
+		Name  string `json:"name"`
+		Count int    `json:"count,omitempty"`
+	}
+
+	t.Run("Valid arguments", func(t *testing.T) {
+		err := ValidateToolArguments(schema{}, json.RawMessage(`{"name":"foo","count":3}`))
+		if err != nil {
+			t.Errorf("Expected valid arguments to pass validation, got error: %v", err)
+		}
+	})
+
+	t.Run("Empty arguments", func(t *testing.T) {
+		err := ValidateToolArguments(schema{}, nil)
+		if err != nil {
+			t.Errorf("Expected empty arguments to pass validation, got error: %v", err)
+		}
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		err := ValidateToolArguments(schema{}, json.RawMessage(`{"name":"foo","bogus":true}`))
+		if err == nil {
+			t.Fatal("expected arguments with an unknown field to fail validation")
+		}
+		if err.Code != ErrorCodeInvalidParams {
+			t.Errorf("Expected error code %d, got %d", ErrorCodeInvalidParams, err.Code)
+		}
+	})
+
+	t.Run("Type mismatch", func(t *testing.T) {
+		err := ValidateToolArguments(schema{}, json.RawMessage(`{"name":"foo","count":"not a number"}`))
+		if err == nil {
+			t.Fatal("expected arguments with a type mismatch to fail validation")
+		}
+		if err.Code != ErrorCodeInvalidParams {
+			t.Errorf("Expected error code %d, got %d", ErrorCodeInvalidParams, err.Code)
+		}
+	})
+}
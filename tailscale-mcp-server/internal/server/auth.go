@@ -0,0 +1,250 @@
+// tailscale-mcp-server/internal/server/auth.go
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
+)
+
+// defaultAPIKeyHeader is the header an API key is read from when AuthConfig
+// doesn't name one explicitly.
+const defaultAPIKeyHeader = "X-API-Key"
+
+// AuthConfig configures transport-level authentication for HTTPServer.
+// Every mechanism is independent and optional; a request is accepted if it
+// satisfies any one of the mechanisms that are configured (an operator who
+// wants either a bearer token or an API key to work sets both). A zero-value
+// AuthConfig, or a nil *AuthConfig passed to NewHTTPServer, accepts every
+// request, matching the server's original behavior. /health is never
+// gated by any of this, since a load balancer or container liveness probe
+// has no way to present credentials.
+type AuthConfig struct {
+	// BearerToken, if set, must match the "Authorization: Bearer <token>"
+	// header exactly. The comparison is constant-time so response latency
+	// can't be used to guess the token byte by byte.
+	BearerToken string
+
+	// APIKeyHeader names the header an API key arrives in. Defaults to
+	// defaultAPIKeyHeader when APIKeyFile is set but this is empty.
+	APIKeyHeader string
+
+	// APIKeyFile points at a file of valid API keys, one per line ("#"
+	// lines and blank lines ignored). It is re-read whenever its mtime
+	// changes, so an operator can rotate keys by rewriting the file
+	// without restarting the server.
+	APIKeyFile string
+
+	// ClientCAFile, TLSCertFile and TLSKeyFile, if all three are set, turn
+	// on mutual TLS: Start serves over TLS using the server certificate at
+	// TLSCertFile/TLSKeyFile, and the TLS handshake itself rejects any
+	// client that doesn't present a certificate signed by a CA in
+	// ClientCAFile (http.Server.TLSConfig.ClientAuth =
+	// tls.RequireAndVerifyClientCert), so authMiddleware never even sees a
+	// request that failed it. The verified peer's Common Name is attached
+	// to the request context; see WithPeerCommonName.
+	ClientCAFile string
+	TLSCertFile  string
+	TLSKeyFile   string
+}
+
+// required reports whether any app-level credential check (bearer token or
+// API key) is configured; mTLS is enforced separately, at the TLS
+// handshake, so it isn't part of this.
+func (c *AuthConfig) required() bool {
+	return c != nil && (c.BearerToken != "" || c.APIKeyFile != "")
+}
+
+// mTLSEnabled reports whether c has everything needed to serve mutual TLS.
+func (c *AuthConfig) mTLSEnabled() bool {
+	return c != nil && c.ClientCAFile != "" && c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// tlsConfig builds the *tls.Config Start passes to http.Server.TLSConfig
+// for mutual TLS: the server's own certificate plus a client CA pool that
+// ClientAuth verifies every connecting peer against.
+func (c *AuthConfig) tlsConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", c.ClientCAFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, nil
+}
+
+// authConfigFromConfig builds an AuthConfig from cfg's HTTP auth fields, or
+// returns nil if none of them are set, so NewHTTPServer callers that never
+// configured any of this keep its original open-access behavior.
+func authConfigFromConfig(cfg *config.Config) *AuthConfig {
+	auth := &AuthConfig{
+		BearerToken:  cfg.HTTPBearerToken,
+		APIKeyHeader: cfg.HTTPAPIKeyHeader,
+		APIKeyFile:   cfg.HTTPAPIKeyFile,
+		ClientCAFile: cfg.HTTPClientCAFile,
+		TLSCertFile:  cfg.HTTPTLSCertFile,
+		TLSKeyFile:   cfg.HTTPTLSKeyFile,
+	}
+	if *auth == (AuthConfig{}) {
+		return nil
+	}
+	return auth
+}
+
+// apiKeyStore holds the set of valid API keys loaded from a file, reloading
+// it whenever its mtime changes so keys can be rotated without a restart.
+type apiKeyStore struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	keys    map[string]struct{}
+}
+
+// newAPIKeyStore loads path once up front, so a missing or unreadable file
+// fails NewHTTPServer immediately rather than silently rejecting every
+// request later.
+func newAPIKeyStore(path string) (*apiKeyStore, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat api key file: %w", err)
+	}
+	keys, err := loadAPIKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKeyStore{path: path, modTime: info.ModTime(), keys: keys}, nil
+}
+
+// valid reports whether key appears in the file, reloading first if the
+// file has changed since it was last read.
+func (s *apiKeyStore) valid(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info, err := os.Stat(s.path); err == nil && info.ModTime().After(s.modTime) {
+		if keys, err := loadAPIKeys(s.path); err != nil {
+			logger.Error("Failed to reload API key file, keeping previous keys", "path", s.path, "error", err)
+		} else {
+			s.keys = keys
+			s.modTime = info.ModTime()
+		}
+	}
+
+	_, ok := s.keys[key]
+	return ok
+}
+
+func loadAPIKeys(path string) (map[string]struct{}, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading api key file: %w", err)
+	}
+
+	keys := make(map[string]struct{})
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys[line] = struct{}{}
+	}
+	return keys, nil
+}
+
+// authMiddleware enforces s.auth (bearer token and/or API key) and, for an
+// mTLS connection, attaches the verified peer's Common Name to the request
+// context. It sits in front of every route except /health and
+// /webhooks/tailscale, which verifies its own signature (see setupRoutes).
+func (s *HTTPServer) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			r = r.WithContext(WithPeerCommonName(r.Context(), r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+
+		if err := s.authenticate(r); err != nil {
+			s.sendError(w, nil, mcp.NewUnauthorizedError(err.Error()))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate accepts r if it satisfies any credential mechanism s.auth
+// has configured (bearer token or API key), or unconditionally if neither
+// is configured. mTLS client certificates are verified by the TLS
+// handshake itself, before the request ever reaches here.
+func (s *HTTPServer) authenticate(r *http.Request) error {
+	if !s.auth.required() {
+		return nil
+	}
+
+	if s.auth.BearerToken != "" {
+		if token, ok := bearerToken(r); ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.auth.BearerToken)) == 1 {
+			return nil
+		}
+	}
+
+	if s.apiKeys != nil {
+		header := s.auth.APIKeyHeader
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		if key := r.Header.Get(header); key != "" && s.apiKeys.valid(key) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("missing or invalid credentials")
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// header, reporting ok=false if the header is absent or a different scheme.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(h, prefix), true
+}
+
+type peerCommonNameKey struct{}
+
+// WithPeerCommonName attaches the Common Name a client certificate's
+// subject presented over mTLS to ctx.
+func WithPeerCommonName(ctx context.Context, cn string) context.Context {
+	return context.WithValue(ctx, peerCommonNameKey{}, cn)
+}
+
+// PeerCommonNameFromContext returns the Common Name attached via
+// WithPeerCommonName, or "" if the request didn't arrive over mTLS (or its
+// certificate had an empty Common Name).
+func PeerCommonNameFromContext(ctx context.Context) string {
+	cn, _ := ctx.Value(peerCommonNameKey{}).(string)
+	return cn
+}
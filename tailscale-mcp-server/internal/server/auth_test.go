@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthConfigRequired(t *testing.T) {
+	assert.False(t, (*AuthConfig)(nil).required())
+	assert.False(t, (&AuthConfig{}).required())
+	assert.True(t, (&AuthConfig{BearerToken: "secret"}).required())
+	assert.True(t, (&AuthConfig{APIKeyFile: "/tmp/keys"}).required())
+}
+
+func TestBearerToken(t *testing.T) {
+	req := httpRequestWithHeader(t, "Authorization", "Bearer abc123")
+	token, ok := bearerToken(req)
+	require.True(t, ok)
+	assert.Equal(t, "abc123", token)
+
+	req = httpRequestWithHeader(t, "Authorization", "Basic abc123")
+	_, ok = bearerToken(req)
+	assert.False(t, ok)
+
+	req = httpRequestWithHeader(t, "", "")
+	_, ok = bearerToken(req)
+	assert.False(t, ok)
+}
+
+func TestAuthenticateNoConfigAcceptsEverything(t *testing.T) {
+	s := &HTTPServer{}
+	req := httpRequestWithHeader(t, "", "")
+	assert.NoError(t, s.authenticate(req))
+}
+
+func TestAuthenticateBearerToken(t *testing.T) {
+	s := &HTTPServer{auth: &AuthConfig{BearerToken: "correct-token"}}
+
+	req := httpRequestWithHeader(t, "Authorization", "Bearer correct-token")
+	assert.NoError(t, s.authenticate(req))
+
+	req = httpRequestWithHeader(t, "Authorization", "Bearer wrong-token")
+	assert.Error(t, s.authenticate(req))
+
+	req = httpRequestWithHeader(t, "", "")
+	assert.Error(t, s.authenticate(req))
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	path := writeAPIKeyFile(t, "key-one\nkey-two\n")
+	store, err := newAPIKeyStore(path)
+	require.NoError(t, err)
+
+	s := &HTTPServer{auth: &AuthConfig{APIKeyFile: path}, apiKeys: store}
+
+	req := httpRequestWithHeader(t, defaultAPIKeyHeader, "key-one")
+	assert.NoError(t, s.authenticate(req))
+
+	req = httpRequestWithHeader(t, defaultAPIKeyHeader, "not-a-key")
+	assert.Error(t, s.authenticate(req))
+}
+
+func TestAuthenticateAPIKeyCustomHeader(t *testing.T) {
+	path := writeAPIKeyFile(t, "key-one\n")
+	store, err := newAPIKeyStore(path)
+	require.NoError(t, err)
+
+	s := &HTTPServer{auth: &AuthConfig{APIKeyFile: path, APIKeyHeader: "X-Custom-Key"}, apiKeys: store}
+
+	req := httpRequestWithHeader(t, "X-Custom-Key", "key-one")
+	assert.NoError(t, s.authenticate(req))
+
+	req = httpRequestWithHeader(t, defaultAPIKeyHeader, "key-one")
+	assert.Error(t, s.authenticate(req), "the default header must not be accepted once a custom header is configured")
+}
+
+func TestAPIKeyStoreReloadsOnChange(t *testing.T) {
+	path := writeAPIKeyFile(t, "key-one\n")
+	store, err := newAPIKeyStore(path)
+	require.NoError(t, err)
+
+	assert.True(t, store.valid("key-one"))
+	assert.False(t, store.valid("key-two"))
+
+	// Backdate modTime so the rewritten file is guaranteed to be newer;
+	// some filesystems have mtime resolution too coarse to tell apart two
+	// writes issued back to back within this test.
+	store.modTime = store.modTime.Add(-time.Second)
+	require.NoError(t, os.WriteFile(path, []byte("key-two\n"), 0o600))
+
+	assert.True(t, store.valid("key-two"))
+	assert.False(t, store.valid("key-one"))
+}
+
+func writeAPIKeyFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api-keys.txt")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func httpRequestWithHeader(t *testing.T, header, value string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "http://example.invalid/mcp", nil)
+	require.NoError(t, err)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	return req
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/jsonrpc2"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
+)
+
+// dispatcher adapts an mcp.Server into a jsonrpc2.Handler. Every transport
+// (StdioServer, the Streamable HTTP transport) is built around the same
+// dispatcher, so the Tailscale tools are routed and errors are translated
+// identically no matter how the client connected.
+type dispatcher struct {
+	server mcp.Server
+}
+
+func newDispatcher(server mcp.Server) *dispatcher {
+	return &dispatcher{server: server}
+}
+
+// handle implements jsonrpc2.Handler.
+func (d *dispatcher) handle(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	logger.Debug("Dispatching MCP message", "method", req.Method, "id", string(req.ID))
+
+	switch req.Method {
+	case mcp.RequestTypeInitialize:
+		return d.handleInitialize(ctx, reply, req)
+	case mcp.RequestTypeListTools:
+		return d.handleListTools(ctx, reply, req)
+	case mcp.RequestTypeCallTool:
+		return d.handleCallTool(ctx, reply, req)
+	case mcp.RequestTypeShutdown:
+		return d.handleShutdown(ctx, reply, req)
+	case mcp.RequestTypeSetLogLevel:
+		return d.handleSetLevel(ctx, reply, req)
+	case mcp.RequestTypeListResources:
+		return d.handleListResources(ctx, reply, req)
+	case mcp.RequestTypeReadResource:
+		return d.handleReadResource(ctx, reply, req)
+	case mcp.RequestTypeSubscribeResource:
+		return d.handleSubscribeResource(ctx, reply, req)
+	case mcp.RequestTypeUnsubscribeResource:
+		return d.handleUnsubscribeResource(ctx, reply, req)
+	case mcp.NotificationTypeInitialized:
+		// No-op: this is a notification (req.ID is empty), so jsonrpc2.Conn
+		// already discards whatever reply does here. The explicit case just
+		// keeps it out of the "unknown method" branch below and its own log
+		// line, rather than being silently swallowed alongside genuinely
+		// unrecognized methods.
+		logger.Debug("MCP client finished initialization handshake")
+		return nil
+	default:
+		return reply(ctx, nil, toRPCError(mcp.NewMethodNotFoundError(req.Method)))
+	}
+}
+
+func (d *dispatcher) handleInitialize(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.InitializeRequest
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+		}
+	}
+
+	response, err := d.server.Initialize(ctx, &params)
+	if err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+
+	// Record the negotiated version and client identity on this connection
+	// so later requests (looked up via jsonrpc2.ConnFromContext) can branch
+	// on the former and attribute audit records to the latter.
+	if conn, ok := jsonrpc2.ConnFromContext(ctx); ok {
+		conn.SetProtocolVersion(response.ProtocolVersion)
+		conn.SetClientInfo(params.ClientInfo.Name, params.ClientInfo.Version)
+	}
+
+	return reply(ctx, response, nil)
+}
+
+func (d *dispatcher) handleListTools(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	params := &mcp.ListToolsRequest{}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+		}
+	}
+
+	response, err := d.server.ListTools(ctx, params)
+	if err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, response, nil)
+}
+
+func (d *dispatcher) handleCallTool(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.CallToolRequest
+	if len(req.Params) == 0 {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError("missing params")))
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+	}
+
+	ctx = audit.WithRequestID(ctx, string(req.ID))
+	if conn, ok := jsonrpc2.ConnFromContext(ctx); ok {
+		name, version := conn.ClientInfo()
+		ctx = audit.WithClientInfo(ctx, name, version)
+	}
+
+	response, err := d.server.CallTool(ctx, &params)
+	if err != nil {
+		if ctx.Err() != nil {
+			return reply(ctx, nil, toRPCError(mcp.NewRequestCancelledError(params.Name)))
+		}
+		var mcpErr *mcp.Error
+		if errors.As(err, &mcpErr) {
+			return reply(ctx, nil, toRPCError(mcpErr))
+		}
+		return reply(ctx, nil, toRPCError(mcp.NewToolExecutionError(params.Name, err)))
+	}
+	return reply(ctx, response, nil)
+}
+
+func (d *dispatcher) handleShutdown(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.ShutdownRequest
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+		}
+	}
+
+	if err := d.server.Shutdown(ctx, &params); err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, map[string]any{}, nil)
+}
+
+// handleSetLevel changes the minimum severity this connection's log sink
+// (wired up by the transport alongside its Conn) forwards as
+// notifications/message notifications.
+func (d *dispatcher) handleSetLevel(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.SetLevelRequest
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+	}
+
+	zapLevel, ok := logger.Level(params.Level).ZapLevel()
+	if !ok {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError("unknown level: "+params.Level)))
+	}
+
+	conn, ok := jsonrpc2.ConnFromContext(ctx)
+	if !ok {
+		return reply(ctx, nil, toRPCError(mcp.NewInternalError("no connection in context")))
+	}
+	conn.SetLogLevel(int32(zapLevel))
+
+	return reply(ctx, map[string]any{}, nil)
+}
+
+func (d *dispatcher) handleListResources(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	params := &mcp.ListResourcesRequest{}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+		}
+	}
+
+	response, err := d.server.ListResources(ctx, params)
+	if err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, response, nil)
+}
+
+func (d *dispatcher) handleReadResource(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.ReadResourceRequest
+	if len(req.Params) == 0 {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError("missing params")))
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+	}
+
+	response, err := d.server.ReadResource(ctx, &params)
+	if err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, response, nil)
+}
+
+func (d *dispatcher) handleSubscribeResource(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.SubscribeResourceRequest
+	if len(req.Params) == 0 {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError("missing params")))
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+	}
+
+	if err := d.server.SubscribeResource(ctx, &params); err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, map[string]any{}, nil)
+}
+
+func (d *dispatcher) handleUnsubscribeResource(ctx context.Context, reply jsonrpc2.Replier, req *jsonrpc2.Request) error {
+	var params mcp.UnsubscribeResourceRequest
+	if len(req.Params) == 0 {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError("missing params")))
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return reply(ctx, nil, toRPCError(mcp.NewInvalidParamsError(err.Error())))
+	}
+
+	if err := d.server.UnsubscribeResource(ctx, &params); err != nil {
+		return reply(ctx, nil, toRPCError(err))
+	}
+	return reply(ctx, map[string]any{}, nil)
+}
+
+// toRPCError converts an mcp.Error (or any error) into a *jsonrpc2.Error,
+// preserving the MCP-specific code instead of collapsing everything to
+// "internal error".
+func toRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var mcpErr *mcp.Error
+	if errors.As(err, &mcpErr) {
+		return &jsonrpc2.Error{Code: mcpErr.Code, Message: mcpErr.Message, Data: mcpErr.Data}
+	}
+	return &jsonrpc2.Error{Code: mcp.ErrorCodeInternalError, Message: err.Error()}
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxNDJSONLine caps a single ndjson line the way the original
+// bufio.Scanner-based stdio reader did. The other framers carry an explicit
+// length up front instead, so they aren't bound by it.
+const maxNDJSONLine = 10 * 1024 * 1024 // 10 MiB
+
+// Framer builds the FrameReader/FrameWriter pair for one wire format, so the
+// transport (stdio today) isn't hardwired to newline-delimited JSON.
+type Framer interface {
+	NewReader(r io.Reader) FrameReader
+	NewWriter(w io.Writer) FrameWriter
+}
+
+// FrameReader reads exactly one MCP message (or batch) at a time off the
+// wire, how that's delimited being the Framer's concern.
+type FrameReader interface {
+	ReadFrame() (json.RawMessage, error)
+}
+
+// FrameWriter writes exactly one MCP message (or batch) to the wire.
+type FrameWriter interface {
+	WriteFrame(json.RawMessage) error
+}
+
+// framedStream adapts a FrameReader/FrameWriter pair to the jsonrpc2.Stream
+// interface jsonrpc2.Conn actually talks to.
+type framedStream struct {
+	r FrameReader
+	w FrameWriter
+}
+
+func newFramedStream(framer Framer, r io.Reader, w io.Writer) *framedStream {
+	return &framedStream{r: framer.NewReader(r), w: framer.NewWriter(w)}
+}
+
+func (s *framedStream) Read() (json.RawMessage, error)  { return s.r.ReadFrame() }
+func (s *framedStream) Write(msg json.RawMessage) error { return s.w.WriteFrame(msg) }
+
+// --- ndjson: one JSON value per line, the format this server has always spoken ---
+
+// NDJSONFramer frames messages as newline-delimited JSON. Reads are capped
+// at maxNDJSONLine per line; large payloads (e.g. `tailscale status --json`
+// on a big tailnet) should use ContentLengthFramer instead.
+type NDJSONFramer struct{}
+
+func (NDJSONFramer) NewReader(r io.Reader) FrameReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	return &ndjsonReader{scanner: sc}
+}
+
+func (NDJSONFramer) NewWriter(w io.Writer) FrameWriter {
+	return &ndjsonWriter{w: w}
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *ndjsonReader) ReadFrame() (json.RawMessage, error) {
+	for {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		// Scanner reuses its buffer, so copy before handing it off.
+		raw := make(json.RawMessage, len(line))
+		copy(raw, line)
+		return raw, nil
+	}
+}
+
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (w *ndjsonWriter) WriteFrame(msg json.RawMessage) error {
+	if _, err := w.w.Write(msg); err != nil {
+		return err
+	}
+	_, err := w.w.Write([]byte("\n"))
+	return err
+}
+
+// --- Content-Length: LSP-style headers, no line-length cap ---
+
+// ContentLengthFramer frames messages the way the Language Server Protocol
+// does: a "Content-Length: N\r\n\r\n" header followed by exactly N bytes of
+// JSON. Unlike NDJSONFramer, message size isn't capped by a scanner buffer.
+type ContentLengthFramer struct{}
+
+func (ContentLengthFramer) NewReader(r io.Reader) FrameReader {
+	return &contentLengthReader{r: bufio.NewReader(r)}
+}
+
+func (ContentLengthFramer) NewWriter(w io.Writer) FrameWriter {
+	return &contentLengthWriter{w: w}
+}
+
+type contentLengthReader struct {
+	r *bufio.Reader
+}
+
+func (r *contentLengthReader) ReadFrame() (json.RawMessage, error) {
+	var contentLength int
+	haveLength := false
+
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break // blank line ends the header block
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header line: %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+			contentLength = n
+			haveLength = true
+		}
+	}
+
+	if !haveLength {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+type contentLengthWriter struct {
+	w io.Writer
+}
+
+func (w *contentLengthWriter) WriteFrame(msg json.RawMessage) error {
+	if _, err := fmt.Fprintf(w.w, "Content-Length: %d\r\n\r\n", len(msg)); err != nil {
+		return err
+	}
+	_, err := w.w.Write(msg)
+	return err
+}
+
+// --- length-prefixed: 4-byte big-endian length + payload, for embedded callers ---
+
+// LengthPrefixedFramer frames messages as a 4-byte big-endian length
+// followed by that many bytes of JSON, a simple binary framing for embedded
+// callers that would rather not parse a text header.
+type LengthPrefixedFramer struct{}
+
+func (LengthPrefixedFramer) NewReader(r io.Reader) FrameReader {
+	return &lengthPrefixedReader{r: r}
+}
+
+func (LengthPrefixedFramer) NewWriter(w io.Writer) FrameWriter {
+	return &lengthPrefixedWriter{w: w}
+}
+
+type lengthPrefixedReader struct {
+	r io.Reader
+}
+
+func (r *lengthPrefixedReader) ReadFrame() (json.RawMessage, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r.r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(lenBuf[:]))
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(body), nil
+}
+
+type lengthPrefixedWriter struct {
+	w io.Writer
+}
+
+func (w *lengthPrefixedWriter) WriteFrame(msg json.RawMessage) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(msg)))
+	if _, err := w.w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(msg)
+	return err
+}
+
+// --- autodetection ---
+
+// autoDetectFramer peeks at the very first byte to pick between
+// NDJSONFramer ('{' or '[') and ContentLengthFramer (any other byte, e.g.
+// 'C' as in "Content-Length"); leading whitespace is not skipped. LengthPrefixedFramer isn't autodetectable (its first
+// byte is just a length, indistinguishable from text) and must be chosen
+// explicitly via WithFramer.
+type autoDetectFramer struct{}
+
+// AutoDetectFramer returns a Framer that sniffs the first byte(s) on the
+// wire to decide between ndjson and Content-Length framing.
+func AutoDetectFramer() Framer { return autoDetectFramer{} }
+
+func (autoDetectFramer) NewReader(r io.Reader) FrameReader {
+	return &autoDetectReader{br: bufio.NewReader(r)}
+}
+
+func (autoDetectFramer) NewWriter(w io.Writer) FrameWriter {
+	// There's nothing to sniff when writing; ndjson is the server's
+	// long-standing default wire format for outbound messages too.
+	return NDJSONFramer{}.NewWriter(w)
+}
+
+type autoDetectReader struct {
+	br       *bufio.Reader
+	resolved FrameReader
+}
+
+func (r *autoDetectReader) ReadFrame() (json.RawMessage, error) {
+	if r.resolved == nil {
+		b, err := r.br.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case '{', '[':
+			r.resolved = NDJSONFramer{}.NewReader(r.br)
+		default:
+			r.resolved = ContentLengthFramer{}.NewReader(r.br)
+		}
+	}
+	return r.resolved.ReadFrame()
+}
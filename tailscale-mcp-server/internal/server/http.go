@@ -1,28 +1,84 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/webhooks"
 )
 
-// HTTPServer implements MCP protocol over HTTP
+// sessionIDHeader is the MCP Streamable HTTP header clients use to attach a
+// request to a previously created session, and that the server returns in
+// the response to "initialize" so the client can resume it later.
+const sessionIDHeader = "Mcp-Session-Id"
+
+// HTTPServer implements the MCP Streamable HTTP transport: a single /mcp
+// endpoint that accepts POSTed JSON-RPC and replies with either a plain
+// application/json body or a text/event-stream, per-session via
+// Mcp-Session-Id so the same Conn (and therefore server-initiated
+// notifications/requests) spans multiple HTTP requests.
 type HTTPServer struct {
-	server     mcp.Server
 	httpServer *http.Server
 	router     *mux.Router
+	sessions   *httpSessionManager
+
+	// auth configures authMiddleware; nil means every request is accepted
+	// (the server's original behavior).
+	auth *AuthConfig
+
+	// apiKeys is non-nil when auth.APIKeyFile is set, holding the
+	// hot-reloadable set of valid keys authenticate checks requests
+	// against.
+	apiKeys *apiKeyStore
+
+	// metrics is the registry /metrics serves, shared with the ToolRegistry
+	// that made this server's tool calls so tool and HTTP metrics live on
+	// one endpoint.
+	metrics     *tools.MetricsRegistry
+	httpMetrics *httpMetrics
+
+	// baseCtx is the parent context every session's Conn runs under, so a
+	// session outlives the single HTTP request that created it. It's
+	// context.Background() until Start replaces it with the server's own
+	// lifecycle context.
+	baseCtx context.Context
+
+	// webhookSecret, when non-empty, registers /webhooks/tailscale and
+	// verifies deliveries to it against this secret (see
+	// internal/webhooks). Empty leaves the endpoint unregistered.
+	webhookSecret string
 }
 
-// NewHTTPServer creates a new HTTP server instance
-func NewHTTPServer(server mcp.Server, port int) *HTTPServer {
+// NewHTTPServer creates a new HTTP server instance. client, when non-nil, is
+// used to resolve each new session's caller identity via WhoIs against the
+// HTTP request's peer address, e.g. for tools.AuthorizationMiddleware to key
+// policy decisions on; pass nil if no tailnet identity resolution is wanted
+// (sessions then carry no caller identity at all). auth, when non-nil,
+// gates every route but /health and /webhooks/tailscale behind
+// authMiddleware and, if it configures
+// mTLS, makes Start serve over TLS; pass nil to accept every request, as
+// before this option existed. metrics backs the /metrics endpoint,
+// typically the same ToolRegistry.Metrics() the mcp.Server dispatches tool
+// calls through. webhookSecret, when non-empty, registers the
+// /webhooks/tailscale endpoint (see internal/webhooks); pass "" to leave
+// webhook delivery unsupported, as before this option existed.
+func NewHTTPServer(server mcp.Server, client tailscale.Client, port int, auth *AuthConfig, metrics *tools.MetricsRegistry, webhookSecret string) (*HTTPServer, error) {
 	router := mux.NewRouter()
 
 	httpServer := &http.Server{
@@ -34,13 +90,81 @@ func NewHTTPServer(server mcp.Server, port int) *HTTPServer {
 	}
 
 	s := &HTTPServer{
-		server:     server,
-		httpServer: httpServer,
-		router:     router,
+		httpServer:    httpServer,
+		router:        router,
+		sessions:      newHTTPSessionManager(server, client),
+		auth:          auth,
+		metrics:       metrics,
+		baseCtx:       context.Background(),
+		webhookSecret: webhookSecret,
+	}
+
+	if auth.mTLSEnabled() {
+		tlsCfg, err := auth.tlsConfig()
+		if err != nil {
+			return nil, fmt.Errorf("configuring mTLS: %w", err)
+		}
+		httpServer.TLSConfig = tlsCfg
 	}
 
+	if auth != nil && auth.APIKeyFile != "" {
+		store, err := newAPIKeyStore(auth.APIKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading API key file: %w", err)
+		}
+		s.apiKeys = store
+	}
+
+	hm, err := newHTTPMetrics(metrics)
+	if err != nil {
+		return nil, fmt.Errorf("registering HTTP metrics: %w", err)
+	}
+	s.httpMetrics = hm
+
 	s.setupRoutes()
-	return s
+	return s, nil
+}
+
+// httpMetrics holds the Prometheus collectors loggingMiddleware records
+// every HTTP request into.
+type httpMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	inFlight        prometheus.Gauge
+}
+
+// newHTTPMetrics creates the HTTP-layer collectors and registers them onto
+// reg, so they're served from the same /metrics endpoint as tool-call and
+// Tailscale-request metrics.
+func newHTTPMetrics(reg *tools.MetricsRegistry) (*httpMetrics, error) {
+	m := &httpMetrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_http_requests_total",
+			Help: "Total HTTP requests served, by method and status code.",
+		}, []string{"method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "mcp_http_request_duration_seconds",
+			Help: "HTTP request duration in seconds, by method and status code.",
+		}, []string{"method", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "mcp_http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served.",
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requestsTotal, m.requestDuration, m.inFlight} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Handler returns the server's underlying HTTP handler, e.g. to serve it
+// over a listener other than the one Start binds (such as an embedded
+// tsnet node's tailnet-facing listener).
+func (s *HTTPServer) Handler() http.Handler {
+	return s.router
 }
 
 // setupRoutes configures HTTP routes and middleware
@@ -49,24 +173,71 @@ func (s *HTTPServer) setupRoutes() {
 	s.router.Use(s.corsMiddleware)
 	s.router.Use(s.loggingMiddleware)
 
-	// MCP endpoints
-	s.router.HandleFunc("/mcp", s.handleMCPRequest).Methods("POST", "OPTIONS")
-
-	// Health check endpoint
+	// Health check endpoint: reachable without credentials, on its own
+	// route outside of the protected subrouter below, for load balancers
+	// and container liveness probes that have no way to present any.
 	s.router.HandleFunc("/health", s.handleHealth).Methods("GET")
 
+	// Every other route requires authMiddleware to pass (a no-op when s.auth
+	// is nil or configures nothing).
+	protected := s.router.NewRoute().Subrouter()
+	protected.Use(s.authMiddleware)
+
+	// MCP endpoints
+	protected.HandleFunc("/mcp", s.handleMCPPost).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/mcp", s.handleMCPStream).Methods("GET")
+	protected.HandleFunc("/mcp", s.handleMCPDelete).Methods("DELETE")
+
 	// Server info endpoint
-	s.router.HandleFunc("/info", s.handleInfo).Methods("GET")
+	protected.HandleFunc("/info", s.handleInfo).Methods("GET")
+
+	// Prometheus metrics: tool calls, Tailscale API/CLI latency, and this
+	// server's own HTTP request counters. Gated the same as /mcp, since an
+	// operator who requires credentials for one almost certainly wants them
+	// for the other too.
+	protected.Handle("/metrics", promhttp.HandlerFor(s.metrics.Gatherer(), promhttp.HandlerOpts{})).Methods("GET")
+
+	// Admin endpoint: only reachable in HTTP and tsnet modes, since stdio
+	// transports never construct an HTTPServer.
+	protected.HandleFunc("/debug/loglevel", s.handleSetLogLevel).Methods("PUT")
+
+	// Webhook deliveries arrive from Tailscale's infrastructure rather than
+	// an authenticated tailnet peer, and authenticate via their own
+	// Tailscale-Webhook-Signature header instead of authMiddleware, so this
+	// is registered outside the protected subrouter. Left unregistered
+	// entirely unless an operator configured a secret to verify against.
+	if s.webhookSecret != "" {
+		s.router.HandleFunc("/webhooks/tailscale", s.handleWebhook).Methods("POST")
+	}
 }
 
 // Start begins the HTTP server
 func (s *HTTPServer) Start(ctx context.Context) error {
-	logger.Info("Starting HTTP MCP server", "addr", s.httpServer.Addr)
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve runs the HTTP server on ln instead of a listener Start creates
+// itself, so callers that need a non-TCP-port listener (e.g. a tsnet
+// node's tailnet-only socket) can still reuse all of HTTPServer's routing,
+// auth, and graceful-shutdown behavior.
+func (s *HTTPServer) Serve(ctx context.Context, ln net.Listener) error {
+	logger.Info("Starting HTTP MCP server", "addr", ln.Addr())
+	s.baseCtx = ctx
 
 	// Start server in a goroutine
 	errChan := make(chan error, 1)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if s.auth.mTLSEnabled() {
+			err = s.httpServer.ServeTLS(ln, s.auth.TLSCertFile, s.auth.TLSKeyFile)
+		} else {
+			err = s.httpServer.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
@@ -84,6 +255,7 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 			logger.Error("Error during HTTP server shutdown", "error", err)
 			return err
 		}
+		s.sessions.closeAll()
 
 		return ctx.Err()
 	case err := <-errChan:
@@ -92,149 +264,213 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 	}
 }
 
-// handleMCPRequest processes MCP JSON-RPC requests
-func (s *HTTPServer) handleMCPRequest(w http.ResponseWriter, r *http.Request) {
+// handleMCPPost processes POSTed JSON-RPC messages (single or batch) against
+// a session's Conn, same as StdioServer does over stdin/stdout, just framed
+// as one HTTP request/response instead of a persistent stream.
+func (s *HTTPServer) handleMCPPost(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		// CORS preflight handled by middleware
 		return
 	}
 
-	// Parse request body
-	var rawMsg map[string]json.RawMessage
-	if err := json.NewDecoder(r.Body).Decode(&rawMsg); err != nil {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		s.sendError(w, nil, mcp.NewParseError(err.Error()))
 		return
 	}
 
-	// Extract ID and method
-	var id json.RawMessage
-	if idRaw, exists := rawMsg["id"]; exists {
-		id = idRaw
+	sess, isNewSession, rpcErr := s.resolveSession(r, body)
+	if rpcErr != nil {
+		s.sendError(w, nil, rpcErr)
+		return
+	}
+	if isNewSession {
+		w.Header().Set(sessionIDHeader, sess.id)
 	}
 
-	var method string
-	if methodRaw, exists := rawMsg["method"]; exists {
-		if err := json.Unmarshal(methodRaw, &method); err != nil {
-			s.sendError(w, id, mcp.NewInvalidRequestError("invalid method"))
-			return
-		}
+	expectsReply, err := messageExpectsReply(body)
+	if err != nil {
+		s.sendError(w, nil, mcp.NewParseError(err.Error()))
+		return
+	}
+
+	sess.transport.incoming <- body
+
+	if !expectsReply {
+		// A notification (or an all-notification batch) gets no reply per
+		// JSON-RPC 2.0; 202 just acknowledges it was accepted.
+		w.WriteHeader(http.StatusAccepted)
+		return
 	}
 
-	// Route the request based on method
-	switch method {
-	case mcp.RequestTypeInitialize:
-		s.handleInitialize(w, r, id, rawMsg)
-	case mcp.RequestTypeListTools:
-		s.handleListTools(w, r, id, rawMsg)
-	case mcp.RequestTypeCallTool:
-		s.handleCallTool(w, r, id, rawMsg)
-	case mcp.RequestTypeShutdown:
-		s.handleShutdown(w, r, id, rawMsg)
-	default:
-		s.sendError(w, id, mcp.NewMethodNotFoundError(method))
+	select {
+	case reply := <-sess.transport.outgoing:
+		s.writeSessionReply(w, r, sess, reply)
+	case <-r.Context().Done():
 	}
 }
 
-// handleInitialize processes initialize requests
-func (s *HTTPServer) handleInitialize(w http.ResponseWriter, r *http.Request, id json.RawMessage, rawMsg map[string]json.RawMessage) {
-	var msg mcp.Message[mcp.InitializeRequest, any]
-	if err := s.parseMessage(rawMsg, &msg); err != nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError(err.Error()))
+// handleMCPStream opens a long-lived Server-Sent Events connection a client
+// can use to receive server-initiated notifications and requests for an
+// existing session outside of any particular POST. A reconnecting client
+// that sends Last-Event-ID first replays whatever the session buffered
+// after that id, so a dropped connection doesn't lose notifications sent
+// while it was gone.
+func (s *HTTPServer) handleMCPStream(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(sessionIDHeader)
+	sess, ok := s.sessions.get(id)
+	if !ok {
+		s.sendError(w, nil, mcp.NewSessionNotFoundError(id))
 		return
 	}
 
-	if msg.Params == nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError("missing params"))
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.sendError(w, nil, mcp.NewInternalError("streaming unsupported"))
 		return
 	}
 
-	response, err := s.server.Initialize(r.Context(), msg.Params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			s.sendError(w, id, mcpErr)
-		} else {
-			s.sendError(w, id, mcp.NewInternalError(err.Error()))
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if lastID, ok := lastEventID(r); ok {
+		for _, ev := range sess.eventsSince(lastID) {
+			writeSSEEvent(w, ev)
 		}
-		return
 	}
+	flusher.Flush()
+
+	for {
+		select {
+		case msg := <-sess.transport.outgoing:
+			writeSSEEvent(w, sess.recordEvent(msg))
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
 
-	s.sendResponse(w, id, response)
+// lastEventID parses the Last-Event-ID header a reconnecting SSE client
+// sends to resume a stream, reporting ok=false if it's absent or malformed.
+func lastEventID(r *http.Request) (int64, bool) {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
 }
 
-// handleListTools processes list tools requests
-func (s *HTTPServer) handleListTools(w http.ResponseWriter, r *http.Request, id json.RawMessage, rawMsg map[string]json.RawMessage) {
-	var msg mcp.Message[mcp.ListToolsRequest, any]
-	if err := s.parseMessage(rawMsg, &msg); err != nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError(err.Error()))
+// handleMCPDelete explicitly terminates a session, per the Streamable HTTP
+// spec, rather than leaving the client to let it go stale.
+func (s *HTTPServer) handleMCPDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(sessionIDHeader)
+	if !s.sessions.close(id) {
+		s.sendError(w, nil, mcp.NewSessionNotFoundError(id))
 		return
 	}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	params := &mcp.ListToolsRequest{}
-	if msg.Params != nil {
-		params = msg.Params
+// resolveSession finds the session named by the Mcp-Session-Id header, or
+// creates one if the request is an "initialize" call that didn't send one.
+func (s *HTTPServer) resolveSession(r *http.Request, body []byte) (*httpSession, bool, *mcp.Error) {
+	if id := r.Header.Get(sessionIDHeader); id != "" {
+		sess, ok := s.sessions.get(id)
+		if !ok {
+			return nil, false, mcp.NewSessionNotFoundError(id)
+		}
+		return sess, false, nil
 	}
 
-	response, err := s.server.ListTools(r.Context(), params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			s.sendError(w, id, mcpErr)
-		} else {
-			s.sendError(w, id, mcp.NewInternalError(err.Error()))
-		}
-		return
+	if !messageIsMethod(body, mcp.RequestTypeInitialize) {
+		return nil, false, mcp.NewInvalidRequestError("missing " + sessionIDHeader)
 	}
 
-	s.sendResponse(w, id, response)
+	return s.sessions.create(s.baseCtx, r.RemoteAddr), true, nil
 }
 
-// handleCallTool processes call tool requests
-func (s *HTTPServer) handleCallTool(w http.ResponseWriter, r *http.Request, id json.RawMessage, rawMsg map[string]json.RawMessage) {
-	var msg mcp.Message[mcp.CallToolRequest, any]
-	if err := s.parseMessage(rawMsg, &msg); err != nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError(err.Error()))
-		return
+// writeSessionReply writes a session's response either as a plain JSON body
+// or, when the client asked for it via Accept, as a single Server-Sent
+// Event; both carry the exact same JSON-RPC bytes the Conn produced. The SSE
+// form is recorded into sess's event buffer like any other pushed event, so
+// a client that upgrades to GET /mcp afterwards with Last-Event-ID can't
+// miss it.
+func (s *HTTPServer) writeSessionReply(w http.ResponseWriter, r *http.Request, sess *httpSession, reply json.RawMessage) {
+	if acceptsEventStream(r) {
+		flusher, ok := w.(http.Flusher)
+		if ok {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			writeSSEEvent(w, sess.recordEvent(reply))
+			flusher.Flush()
+			return
+		}
 	}
 
-	if msg.Params == nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError("missing params"))
-		return
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(reply); err != nil {
+		logger.Error("Failed to write MCP response", "error", err)
 	}
+}
 
-	response, err := s.server.CallTool(r.Context(), msg.Params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			s.sendError(w, id, mcpErr)
-		} else {
-			s.sendError(w, id, mcp.NewToolExecutionError(msg.Params.Name, err))
-		}
-		return
-	}
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
 
-	s.sendResponse(w, id, response)
+func writeSSEEvent(w http.ResponseWriter, ev sseEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", ev.id, ev.data)
 }
 
-// handleShutdown processes shutdown requests
-func (s *HTTPServer) handleShutdown(w http.ResponseWriter, r *http.Request, id json.RawMessage, rawMsg map[string]json.RawMessage) {
-	var msg mcp.Message[mcp.ShutdownRequest, any]
-	if err := s.parseMessage(rawMsg, &msg); err != nil {
-		s.sendError(w, id, mcp.NewInvalidParamsError(err.Error()))
-		return
+// messageExpectsReply reports whether body (a single JSON-RPC message or a
+// batch) contains at least one request with an id, i.e. whether the caller
+// should wait for a reply rather than being told 202 Accepted immediately.
+func messageExpectsReply(body []byte) (bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return false, err
+		}
+		for _, msg := range batch {
+			if hasID, err := messageHasID(msg); err != nil {
+				return false, err
+			} else if hasID {
+				return true, nil
+			}
+		}
+		return false, nil
 	}
+	return messageHasID(trimmed)
+}
 
-	if err := s.server.Shutdown(r.Context(), msg.Params); err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			s.sendError(w, id, mcpErr)
-		} else {
-			s.sendError(w, id, mcp.NewInternalError(err.Error()))
-		}
-		return
+func messageHasID(raw json.RawMessage) (bool, error) {
+	var envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return false, err
 	}
+	return len(envelope.ID) > 0, nil
+}
 
-	s.sendResponse(w, id, map[string]interface{}{})
+// messageIsMethod reports whether a (non-batch) JSON-RPC message invokes
+// method. Malformed or batch bodies are treated as not matching.
+func messageIsMethod(body []byte, method string) bool {
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(body), &envelope); err != nil {
+		return false
+	}
+	return envelope.Method == method
 }
 
 // handleHealth provides a health check endpoint
@@ -267,19 +503,66 @@ func (s *HTTPServer) handleInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// parseMessage parses a raw message into a typed message
-func (s *HTTPServer) parseMessage(rawMsg map[string]json.RawMessage, target interface{}) error {
-	data, err := json.Marshal(rawMsg)
-	if err != nil {
-		return err
+// debugLogLevelRequest is the body PUT /debug/loglevel expects.
+type debugLogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// handleSetLogLevel changes the server's runtime log verbosity, the HTTP
+// counterpart to the SIGUSR1/SIGUSR2 signal handlers in cli.runServer.
+func (s *HTTPServer) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req debugLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	level, ok := logger.ParseLevel(req.Level)
+	if !ok {
+		http.Error(w, "unknown level: "+req.Level, http.StatusBadRequest)
+		return
 	}
-	return json.Unmarshal(data, target)
+
+	logger.SetLevel(level)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// sendResponse sends a successful response
-func (s *HTTPServer) sendResponse(w http.ResponseWriter, id json.RawMessage, result interface{}) {
-	response := mcp.NewResponse(id, &result)
-	s.writeJSON(w, http.StatusOK, response)
+// handleWebhook receives a Tailscale webhook delivery, verifies its
+// Tailscale-Webhook-Signature header against s.webhookSecret, and
+// republishes each verified event as a notifications/tailscale/webhook
+// notification to every connected MCP session (see
+// httpSessionManager.notifyAll), so an agent watching the tailnet hears
+// about device/policy changes in real time instead of polling for them.
+func (s *HTTPServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "reading request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := webhooks.VerifySignature(s.webhookSecret, r.Header.Get(webhooks.SignatureHeader), body); err != nil {
+		logger.Warn("rejected webhook delivery", "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := webhooks.ParsePayload(body)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range payload.Data {
+		s.sessions.notifyAll(r.Context(), mcp.NotificationTailscaleWebhook, mcp.TailscaleWebhookEventParams{
+			Type:      string(event.Type),
+			Tailnet:   event.Tailnet,
+			Timestamp: event.Timestamp.Format(time.RFC3339),
+			Message:   event.Message,
+			Data:      event.Data,
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // sendError sends an error response
@@ -295,6 +578,10 @@ func (s *HTTPServer) sendError(w http.ResponseWriter, id json.RawMessage, err *m
 		statusCode = http.StatusNotFound
 	case mcp.ErrorCodeToolNotFound:
 		statusCode = http.StatusNotFound
+	case mcp.ErrorCodeSessionNotFound:
+		statusCode = http.StatusNotFound
+	case mcp.ErrorCodeUnauthorized:
+		statusCode = http.StatusUnauthorized
 	}
 
 	s.writeJSON(w, statusCode, response)
@@ -315,7 +602,8 @@ func (s *HTTPServer) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+sessionIDHeader)
+		w.Header().Set("Access-Control-Expose-Headers", sessionIDHeader)
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		if r.Method == "OPTIONS" {
@@ -327,17 +615,25 @@ func (s *HTTPServer) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// loggingMiddleware logs HTTP requests
+// loggingMiddleware logs HTTP requests and records mcp_http_requests_total,
+// mcp_http_request_duration_seconds and mcp_http_requests_in_flight.
 func (s *HTTPServer) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		s.httpMetrics.inFlight.Inc()
+		defer s.httpMetrics.inFlight.Dec()
+
 		// Create a response writer wrapper to capture status code
 		wrapper := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		next.ServeHTTP(wrapper, r)
 
 		duration := time.Since(start)
+		status := strconv.Itoa(wrapper.statusCode)
+		s.httpMetrics.requestsTotal.WithLabelValues(r.Method, status).Inc()
+		s.httpMetrics.requestDuration.WithLabelValues(r.Method, status).Observe(duration.Seconds())
+
 		logger.Info("HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
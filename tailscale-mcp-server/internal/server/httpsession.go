@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/jsonrpc2"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// httpSessionTransport is a Transport backed by channels instead of a real
+// socket: POSTed request bodies are fed in via ReadMessage, and whatever the
+// dispatcher writes back (responses, but also server-initiated notifications
+// and requests) comes out of WriteMessage for the HTTP handler to forward,
+// either as a single JSON body or as Server-Sent Events.
+type httpSessionTransport struct {
+	incoming chan json.RawMessage
+	outgoing chan json.RawMessage
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newHTTPSessionTransport() *httpSessionTransport {
+	return &httpSessionTransport{
+		incoming: make(chan json.RawMessage, 16),
+		outgoing: make(chan json.RawMessage, 16),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (t *httpSessionTransport) ReadMessage() (json.RawMessage, error) {
+	select {
+	case msg := <-t.incoming:
+		return msg, nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *httpSessionTransport) WriteMessage(msg json.RawMessage) error {
+	select {
+	case t.outgoing <- msg:
+		return nil
+	case <-t.closed:
+		return fmt.Errorf("session closed")
+	}
+}
+
+func (t *httpSessionTransport) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+// sseEventBufferSize bounds how many past SSE events a session replays to a
+// client that reconnects with a Last-Event-ID header; older events are
+// simply lost, same as if the client had missed them outright.
+const sseEventBufferSize = 256
+
+// sseEvent pairs an SSE payload with the monotonically increasing id a
+// reconnecting client can name via Last-Event-ID to resume after it.
+type sseEvent struct {
+	id   int64
+	data json.RawMessage
+}
+
+// httpSession is one client's Streamable HTTP session: a standing
+// jsonrpc2.Conn over an httpSessionTransport, addressed by the client on
+// every request via the Mcp-Session-Id header so it can resume the same
+// Conn across separate HTTP requests.
+type httpSession struct {
+	id        string
+	transport *httpSessionTransport
+	conn      *jsonrpc2.Conn
+	cancel    context.CancelFunc
+	logSinkID int64
+
+	eventsMu    sync.Mutex
+	nextEventID int64
+	eventBuf    []sseEvent
+}
+
+// recordEvent assigns the next event id to data and buffers it, so a client
+// that drops its GET /mcp stream and reconnects with Last-Event-ID doesn't
+// lose anything sent while it was gone.
+func (s *httpSession) recordEvent(data json.RawMessage) sseEvent {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	s.nextEventID++
+	ev := sseEvent{id: s.nextEventID, data: data}
+	s.eventBuf = append(s.eventBuf, ev)
+	if len(s.eventBuf) > sseEventBufferSize {
+		s.eventBuf = s.eventBuf[len(s.eventBuf)-sseEventBufferSize:]
+	}
+	return ev
+}
+
+// eventsSince returns buffered events with an id greater than lastID, in
+// order, for replay to a reconnecting client.
+func (s *httpSession) eventsSince(lastID int64) []sseEvent {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	var out []sseEvent
+	for _, ev := range s.eventBuf {
+		if ev.id > lastID {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+// httpSessionManager owns every live Streamable HTTP session for an
+// HTTPServer.
+type httpSessionManager struct {
+	dispatcher *dispatcher
+	client     tailscale.Client
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+func newHTTPSessionManager(mcpServer mcp.Server, client tailscale.Client) *httpSessionManager {
+	return &httpSessionManager{
+		dispatcher: newDispatcher(mcpServer),
+		client:     client,
+		sessions:   make(map[string]*httpSession),
+	}
+}
+
+// create starts a new session and its Conn's read loop, returning the
+// session so the caller can feed it the request that triggered creation
+// (normally "initialize"). remoteAddr is the creating HTTP request's
+// RemoteAddr, used once to resolve the session's tailnet identity via
+// WhoIs; every later request against this session (by Mcp-Session-Id)
+// inherits that identity rather than re-resolving it.
+func (m *httpSessionManager) create(ctx context.Context, remoteAddr string) *httpSession {
+	sessionCtx, cancel := context.WithCancel(ctx)
+
+	sessionID := generateSessionID()
+	sessionCtx = audit.WithSessionID(sessionCtx, sessionID)
+	if identity := resolveIdentity(sessionCtx, m.client, remoteAddr); identity != "" {
+		sessionCtx = tailscale.WithCallerID(sessionCtx, identity)
+	}
+
+	transport := newHTTPSessionTransport()
+	sess := &httpSession{
+		id:        sessionID,
+		transport: transport,
+		cancel:    cancel,
+	}
+	sess.conn = jsonrpc2.NewConn(transportStream{transport}, m.dispatcher.handle)
+	sess.logSinkID = logger.AddSink(newLogNotifyCore(sess.conn))
+
+	// Run exits once the session is closed (transport.Close unblocks
+	// ReadMessage with io.EOF); there's nothing useful to do with its error.
+	go func() { _ = sess.conn.Run(sessionCtx) }()
+
+	m.mu.Lock()
+	m.sessions[sess.id] = sess
+	m.mu.Unlock()
+
+	return sess
+}
+
+// get looks up an existing session by the ID the client sent.
+func (m *httpSessionManager) get(id string) (*httpSession, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sess, ok := m.sessions[id]
+	return sess, ok
+}
+
+// notifyAll pushes a server-initiated notification to every live session,
+// e.g. a verified tailnet webhook event. Unlike a notification triggered by
+// one session's own tool call (resolved via notifierFromContext), this has
+// no single Conn to address, so it's fanned out to all of them; a session
+// whose Notify fails (e.g. a dropped GET /mcp stream) is logged and
+// skipped rather than aborting delivery to the rest.
+func (m *httpSessionManager) notifyAll(ctx context.Context, method string, params any) {
+	m.mu.Lock()
+	sessions := make([]*httpSession, 0, len(m.sessions))
+	for _, sess := range m.sessions {
+		sessions = append(sessions, sess)
+	}
+	m.mu.Unlock()
+
+	for _, sess := range sessions {
+		if err := sess.conn.Notify(ctx, method, params); err != nil {
+			logger.Debug("failed to push notification to session", "session", sess.id, "method", method, "error", err)
+		}
+	}
+}
+
+// close terminates and forgets a session.
+func (m *httpSessionManager) close(id string) bool {
+	m.mu.Lock()
+	sess, ok := m.sessions[id]
+	if ok {
+		delete(m.sessions, id)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	sess.cancel()
+	logger.RemoveSink(sess.logSinkID)
+	_ = sess.transport.Close()
+	return true
+}
+
+// closeAll terminates every session, e.g. on server shutdown.
+func (m *httpSessionManager) closeAll() {
+	m.mu.Lock()
+	sessions := make([]*httpSession, 0, len(m.sessions))
+	for _, sess := range m.sessions {
+		sessions = append(sessions, sess)
+	}
+	m.sessions = make(map[string]*httpSession)
+	m.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.cancel()
+		logger.RemoveSink(sess.logSinkID)
+		_ = sess.transport.Close()
+	}
+}
+
+// resolveIdentity looks up the tailnet identity of the peer at remoteAddr
+// (an HTTP request's RemoteAddr, host:port) via WhoIs, so tools.
+// AuthorizationMiddleware can key policy decisions on it. Any failure —
+// client is nil, the backend doesn't support WhoIs (the CLI backend never
+// does), or the peer isn't a tailnet node — falls back to "" (unresolved)
+// rather than blocking session creation; a policy that denies unresolved
+// callers is how an operator opts into rejecting those instead.
+func resolveIdentity(ctx context.Context, client tailscale.Client, remoteAddr string) string {
+	if client == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	who, err := client.WhoIs(ctx, host)
+	if err != nil {
+		logger.Debug("WhoIs lookup failed for HTTP session, leaving identity unresolved", "remote_addr", remoteAddr, "error", err)
+		return ""
+	}
+	if who.UserLogin != "" {
+		return who.UserLogin
+	}
+	return who.NodeName
+}
+
+func generateSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand failing is effectively unrecoverable, but a fixed
+		// fallback keeps the server from panicking on a degraded host.
+		return "0000000000000000000000000000000000"
+	}
+	return hex.EncodeToString(buf)
+}
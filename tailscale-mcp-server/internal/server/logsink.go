@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/jsonrpc2"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
+)
+
+// notifyTimeout bounds how long a single log forward is allowed to block the
+// peer before it's abandoned; log delivery must never back up behind a slow
+// or wedged client.
+const notifyTimeout = 5 * time.Second
+
+// logNotifyCore is a zapcore.Core, registered with logger.AddSink, that
+// forwards every record it's given to one MCP client as a
+// notifications/message notification. Its minimum level tracks conn's
+// LogLevel, so a logging/setLevel request (handled by
+// dispatcher.handleSetLevel, which calls conn.SetLogLevel) changes what this
+// client receives without touching the server's own stderr/file logging or
+// any other connected client.
+type logNotifyCore struct {
+	conn *jsonrpc2.Conn
+}
+
+// newLogNotifyCore returns a core that forwards logs to conn's peer. Attach
+// it with logger.AddSink and detach the returned handle with
+// logger.RemoveSink when conn closes.
+func newLogNotifyCore(conn *jsonrpc2.Conn) *logNotifyCore {
+	return &logNotifyCore{conn: conn}
+}
+
+func (c *logNotifyCore) Enabled(level zapcore.Level) bool {
+	return level >= zapcore.Level(int8(c.conn.LogLevel()))
+}
+
+func (c *logNotifyCore) With(fields []zapcore.Field) zapcore.Core { return c }
+
+func (c *logNotifyCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *logNotifyCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	enc.Fields["message"] = entry.Message
+
+	params := mcp.LogMessageParams{
+		Level:  string(logger.MCPLevel(entry.Level)),
+		Logger: entry.LoggerName,
+		Data:   enc.Fields,
+	}
+
+	// Forwarding must never block (or fail) the log call that triggered it,
+	// so it happens on its own goroutine with its own timeout.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		_ = c.conn.Notify(ctx, mcp.NotificationMessage, params)
+	}()
+	return nil
+}
+
+func (c *logNotifyCore) Sync() error { return nil }
@@ -4,13 +4,21 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
 	"sync"
 
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tools"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/authz"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/routing"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/tailnet"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tsnetnode"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/web"
 )
 
 // ServerOption configures the TailscaleMCPServer (functional options pattern)
@@ -23,9 +31,36 @@ type TailscaleMCPServer struct {
 	config    *config.Config
 	api       *tailscale.APIClient
 	cli       *tailscale.TailscaleCLI
+	client    tailscale.Client
 	registry  *tools.ToolRegistry
 	mcpServer mcp.Server
 	running   bool
+	shutdown  bool
+
+	// httpServer is set for the lifetime of a running StartHTTP or
+	// StartTsnet call, so
+	// Shutdown can terminate its live Streamable HTTP sessions (and their
+	// session contexts) directly instead of relying solely on the caller
+	// having canceled ctx before calling Shutdown.
+	httpServer *HTTPServer
+
+	// auditSpooler is non-nil when cfg.AuditDir is set, spooling a
+	// structured JSON record of every tool call to disk. Close it during
+	// Shutdown so no record queued before SIGTERM is lost.
+	auditSpooler *audit.Spooler
+
+	// tsnetNode is the embedded Tailscale node backing the tailnet_* tools.
+	// Nil unless the server was configured with TsnetEnabled.
+	tsnetNode *tsnetnode.Node
+
+	// failoverMonitor polls HA subnet router health and, when enabled,
+	// automatically promotes a healthy standby in place of an unhealthy
+	// primary. Nil unless the server was configured with AutoFailover.
+	failoverMonitor *routing.FailoverMonitor
+
+	// webServer is the local browser UI (internal/web). Nil unless the
+	// server was configured with WebUIEnabled.
+	webServer *web.Server
 }
 
 // WithCustomMCPServer allows injecting a custom MCP server implementation
@@ -52,6 +87,24 @@ func WithCustomRegistry(registry *tools.ToolRegistry) ServerOption {
 	}
 }
 
+// WithToolAuthorizer installs authorizer as a gate in front of every tool
+// call, keyed by the caller's tailnet identity (see
+// tools.AuthorizationMiddleware). Stdio mode should pass authz.Noop, or
+// omit this option entirely, since its single local client is already
+// trusted; HTTP mode behind a Tailscale sidecar should pass an
+// authz.NewPolicyAuthorizer loaded from a tools.policy.hujson file so
+// deny decisions come back as MCP-level errors rather than HTTP 403s.
+func WithToolAuthorizer(authorizer authz.Authorizer) ServerOption {
+	return func(s *TailscaleMCPServer) error {
+		if authorizer == nil {
+			return fmt.Errorf("tool authorizer cannot be nil")
+		}
+
+		s.registry.Use(tools.AuthorizationMiddleware(authorizer))
+		return nil
+	}
+}
+
 // New creates a new server instance using Go best practices
 func New(cfg *config.Config, opts ...ServerOption) (*TailscaleMCPServer, error) {
 	if cfg == nil {
@@ -72,20 +125,31 @@ func New(cfg *config.Config, opts ...ServerOption) (*TailscaleMCPServer, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tailscale cli: %w", err)
 	}
+	if cfg.TailscaleLoginServer != "" {
+		cli.SetDefaultLoginServer(cfg.TailscaleLoginServer)
+	}
+
+	client, err := tailscale.NewClient(cfg.TailscaleBackend, cli, cfg.WatchDebounce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tailscale client: %w", err)
+	}
 
 	// Create tool registry
-	registry := tools.NewToolRegistry(api, cli)
+	registry := tools.NewToolRegistry(api, cli, client, cfg.LogFile, logger.Default())
 
 	// Create server with default MCP implementation
 	server := &TailscaleMCPServer{
 		config:   cfg,
 		api:      api,
 		cli:      cli,
+		client:   client,
 		registry: registry,
 		mcpServer: mcp.NewMCPServer(
 			registry,
+			client,
 			"tailscale-mcp-server",
 			"0.1.0", // TODO: Get version from config or build flags
+			logger.Default(),
 		),
 	}
 
@@ -96,6 +160,97 @@ func New(cfg *config.Config, opts ...ServerOption) (*TailscaleMCPServer, error)
 		}
 	}
 
+	if cfg.AuditDir != "" {
+		spooler, err := audit.NewSpooler(cfg.AuditDir, audit.NoopUploader{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audit spooler: %w", err)
+		}
+		server.auditSpooler = spooler
+		server.registry.Use(tools.AuditSpoolerMiddleware(spooler))
+	}
+
+	if cfg.TsnetEnabled || cfg.ServerMode == "tsnet" {
+		node, err := tsnetnode.New(tsnetnode.Options{
+			Hostname:   cfg.TsnetHostname,
+			AuthKey:    cfg.TsnetAuthKey,
+			StateDir:   cfg.TsnetStateDir,
+			ControlURL: cfg.TsnetControlURL,
+			Ephemeral:  cfg.TsnetEphemeral,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tsnet node: %w", err)
+		}
+
+		// The MCP HTTP handler is reused as-is to serve the tailnet_serve_mcp
+		// listener; port 0 is fine since this instance is never started.
+		// Whatever HTTP auth the operator configured applies here too, since
+		// this handler is reachable by any tailnet peer just like the
+		// regular HTTP server.
+		httpServerForTsnet, err := NewHTTPServer(server.mcpServer, server.client, 0, authConfigFromConfig(cfg), registry.Metrics(), cfg.TailscaleWebhookSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP handler for tailnet_serve_mcp: %w", err)
+		}
+		mcpHandler := httpServerForTsnet.Handler()
+
+		registerTailnetTool := func(tool tools.Tool) error {
+			if err := server.registry.Register(tool); err != nil {
+				return fmt.Errorf("failed to register %s: %w", tool.Name(), err)
+			}
+			return nil
+		}
+
+		if err := registerTailnetTool(tailnet.NewDialTCPTool(node)); err != nil {
+			return nil, err
+		}
+		if err := registerTailnetTool(tailnet.NewHTTPGetTool(node)); err != nil {
+			return nil, err
+		}
+		if err := registerTailnetTool(tailnet.NewWhoIsTool(node)); err != nil {
+			return nil, err
+		}
+		if err := registerTailnetTool(tailnet.NewServeMCPTool(node, mcpHandler)); err != nil {
+			return nil, err
+		}
+
+		server.tsnetNode = node
+	}
+
+	if cfg.AutoFailover {
+		server.failoverMonitor = routing.NewFailoverMonitor(api, cfg.FailoverPollInterval, func(event tailscale.FailoverEvent) {
+			logger.Info("HA subnet router failover", "event", event)
+		})
+	}
+
+	if cfg.WebUIEnabled {
+		// Over a tsnet listener the bind address is no longer a loopback
+		// perimeter, so authorize every request against the embedded
+		// node's own view of tailnet identity instead.
+		var authorizer web.Authorizer
+		if server.tsnetNode != nil {
+			authorizer = func(r *http.Request) (web.PeerIdentity, bool) {
+				lc, err := server.tsnetNode.LocalClient()
+				if err != nil {
+					return web.PeerIdentity{}, false
+				}
+				who, err := lc.WhoIs(r.Context(), r.RemoteAddr)
+				if err != nil || who.UserProfile == nil || who.Node == nil {
+					return web.PeerIdentity{}, false
+				}
+				return web.PeerIdentity{LoginName: who.UserProfile.LoginName, NodeName: who.Node.ComputedName}, true
+			}
+		}
+
+		webServer, err := web.NewServer(api, web.Options{
+			Bind:       cfg.WebUIBind,
+			Mode:       web.Mode(cfg.WebUIMode),
+			Authorizer: authorizer,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create web UI server: %w", err)
+		}
+		server.webServer = webServer
+	}
+
 	return server, nil
 }
 
@@ -115,6 +270,14 @@ func (s *TailscaleMCPServer) StartStdio(ctx context.Context) error {
 		s.mu.Unlock()
 	}()
 
+	if err := s.startTsnetNode(ctx); err != nil {
+		return err
+	}
+	s.startFailoverMonitor(ctx)
+	if err := s.startWebUI(ctx); err != nil {
+		return err
+	}
+
 	logger.Info("Starting stdio MCP server")
 
 	server := NewStdioServer(s.mcpServer)
@@ -137,23 +300,176 @@ func (s *TailscaleMCPServer) StartHTTP(ctx context.Context, port int) error {
 		s.mu.Unlock()
 	}()
 
+	if err := s.startTsnetNode(ctx); err != nil {
+		return err
+	}
+	s.startFailoverMonitor(ctx)
+	if err := s.startWebUI(ctx); err != nil {
+		return err
+	}
+
 	logger.Info("Starting HTTP MCP server", "port", port)
 
-	server := NewHTTPServer(s.mcpServer, port)
-	return server.Start(ctx)
+	httpServer, err := NewHTTPServer(s.mcpServer, s.client, port, authConfigFromConfig(s.config), s.registry.Metrics(), s.config.TailscaleWebhookSecret)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP server: %w", err)
+	}
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.httpServer = nil
+		s.mu.Unlock()
+	}()
+
+	return httpServer.Start(ctx)
 }
 
-// Shutdown gracefully shuts down the server
-func (s *TailscaleMCPServer) Shutdown(ctx context.Context) error {
+// StartTsnet starts the server in tsnet mode: the MCP Streamable HTTP
+// transport is served from the embedded tsnet node's own listener instead
+// of a regular host TCP port, so only authenticated tailnet peers can ever
+// reach it. Requires the server to have been created with ServerMode
+// "tsnet" (or TsnetEnabled), which New validates and wires s.tsnetNode up
+// for.
+func (s *TailscaleMCPServer) StartTsnet(ctx context.Context, port int) error {
+	if s.tsnetNode == nil {
+		return fmt.Errorf("tsnet mode requires an embedded tsnet node (ServerMode \"tsnet\" or TsnetEnabled)")
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("server is already running")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
 
-	if !s.running {
-		return nil // Already stopped
+	if err := s.startTsnetNode(ctx); err != nil {
+		return err
+	}
+	s.startFailoverMonitor(ctx)
+	if err := s.startWebUI(ctx); err != nil {
+		return err
 	}
 
+	logger.Info("Starting tsnet MCP server", "port", port)
+
+	httpServer, err := NewHTTPServer(s.mcpServer, s.client, port, authConfigFromConfig(s.config), s.registry.Metrics(), s.config.TailscaleWebhookSecret)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP server: %w", err)
+	}
+
+	ln, err := s.tsnetNode.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on tsnet node: %w", err)
+	}
+
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.httpServer = nil
+		s.mu.Unlock()
+	}()
+
+	return httpServer.Serve(ctx, ln)
+}
+
+// startWebUI brings up the local web UI in the background, if one was
+// configured. When the server also has an embedded tsnet node, the web UI
+// listens on the node's own tailnet-only socket (the port from WebUIBind,
+// or DefaultBind's port if unset) instead of opening its own host port.
+// It is a no-op when the server was not configured with WebUIEnabled.
+func (s *TailscaleMCPServer) startWebUI(ctx context.Context) error {
+	if s.webServer == nil {
+		return nil
+	}
+
+	if s.tsnetNode == nil {
+		go func() {
+			if err := s.webServer.Start(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("web UI stopped unexpectedly", "error", err)
+			}
+		}()
+		return nil
+	}
+
+	bind := s.config.WebUIBind
+	if bind == "" {
+		bind = web.DefaultBind
+	}
+	_, port, err := net.SplitHostPort(bind)
+	if err != nil {
+		return fmt.Errorf("invalid web UI bind address %q: %w", bind, err)
+	}
+
+	ln, err := s.tsnetNode.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on tsnet node for web UI: %w", err)
+	}
+
+	go func() {
+		if err := s.webServer.Serve(ctx, ln); err != nil && ctx.Err() == nil {
+			logger.Error("web UI stopped unexpectedly", "error", err)
+		}
+	}()
+	return nil
+}
+
+// startTsnetNode brings up the embedded tsnet node, if one was configured.
+// It is a no-op when the server was configured with neither TsnetEnabled
+// nor ServerMode "tsnet".
+func (s *TailscaleMCPServer) startTsnetNode(ctx context.Context) error {
+	if s.tsnetNode == nil {
+		return nil
+	}
+
+	if err := s.tsnetNode.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start tsnet node: %w", err)
+	}
+	return nil
+}
+
+// startFailoverMonitor begins HA subnet router health polling, if one was
+// configured. It is a no-op when the server was not configured with
+// AutoFailover.
+func (s *TailscaleMCPServer) startFailoverMonitor(ctx context.Context) {
+	if s.failoverMonitor == nil {
+		return
+	}
+	s.failoverMonitor.Start(ctx)
+}
+
+// Shutdown gracefully shuts down the server: tool registry, audit spooler,
+// failover monitor, and tsnet node. It's idempotent and safe to call even
+// after StartStdio/StartHTTP has already returned (they clear running
+// before returning, but Shutdown's own cleanup must still run exactly once
+// so a SIGTERM caller can always flush queued audit records via it).
+func (s *TailscaleMCPServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.shutdown {
+		s.mu.Unlock()
+		return nil
+	}
+	s.shutdown = true
+	s.running = false
+	s.mu.Unlock()
+
 	logger.Info("Shutting down TailscaleMCPServer")
 
+	// Stop accepting new tool calls and give in-flight Tool.Execute calls up
+	// to ShutdownTimeout to finish before force-canceling survivors (see
+	// ToolRegistry.Drain).
+	s.registry.Drain(s.config.ShutdownTimeout)
+
 	// Shutdown MCP server
 	if err := s.mcpServer.Shutdown(ctx, &mcp.ShutdownRequest{}); err != nil {
 		logger.Error("Error shutting down MCP server", "error", err)
@@ -166,7 +482,38 @@ func (s *TailscaleMCPServer) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to close tool registry: %w", err)
 	}
 
-	s.running = false
+	// Terminate any live Streamable HTTP sessions so their session contexts
+	// are canceled even if the caller invoked Shutdown before StartHTTP's
+	// own ctx cancellation unwound them.
+	s.mu.RLock()
+	httpServer := s.httpServer
+	s.mu.RUnlock()
+	if httpServer != nil {
+		httpServer.sessions.closeAll()
+	}
+
+	// Flush any audit records still queued before the process exits.
+	if s.auditSpooler != nil {
+		if err := s.auditSpooler.Close(); err != nil {
+			logger.Error("Error closing audit spooler", "error", err)
+			return fmt.Errorf("failed to close audit spooler: %w", err)
+		}
+	}
+
+	// Stop the HA subnet router failover monitor, if any, before the tsnet
+	// node goes away. The audit spooler is already closed at this point.
+	if s.failoverMonitor != nil {
+		s.failoverMonitor.Stop()
+	}
+
+	// Shut down the embedded tsnet node, if any
+	if s.tsnetNode != nil {
+		if err := s.tsnetNode.Close(); err != nil {
+			logger.Error("Error closing tsnet node", "error", err)
+			return fmt.Errorf("failed to close tsnet node: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -1,233 +1,95 @@
 package server
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"os"
-	"sync"
 
+	"github.com/hexsleeves/tailscale-mcp-server/internal/jsonrpc2"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/mcp"
 )
 
-// StdioServer implements MCP protocol over stdin/stdout
-type StdioServer struct {
-	server mcp.Server
-	reader *bufio.Scanner
-	writer io.Writer
-	mu     sync.Mutex
+// notifierSetter is satisfied by an mcp.Server implementation that wants to
+// push notifications/requests back over whatever transport it's running on
+// (today only *mcp.MCPServer does). It's checked with a type assertion so
+// mcp.Server itself doesn't have to grow the method for every implementation.
+type notifierSetter interface {
+	SetNotifier(mcp.Notifier)
 }
 
-// NewStdioServer creates a new stdio server instance
-func NewStdioServer(server mcp.Server) *StdioServer {
-	sc := bufio.NewScanner(os.Stdin)
-	sc.Buffer(make([]byte, 0, 64*1024), 10*1024*1024) // 10 MiB hard-cap
-
-	return &StdioServer{
-		server: server,
-		reader: sc,
-		writer: os.Stdout,
-	}
+// stdioTransport implements Transport over os.Stdin/os.Stdout, framing
+// messages according to whatever Framer it's given (ndjson by default).
+type stdioTransport struct {
+	stream *framedStream
 }
 
-// Start begins processing MCP messages from stdin
-func (s *StdioServer) Start(ctx context.Context) error {
-	logger.Info("Starting stdio MCP server")
-
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Stdio server shutting down")
-			return ctx.Err()
-		default:
-			if !s.reader.Scan() {
-				if err := s.reader.Err(); err != nil {
-					logger.Error("Error reading from stdin", "error", err)
-					return fmt.Errorf("stdin read error: %w", err)
-				}
-				// EOF reached
-				logger.Info("Stdin closed, shutting down")
-				return nil
-			}
-
-			line := s.reader.Text()
-			if line == "" {
-				continue
-			}
-
-			logger.Debug("Received message", "message", line)
-
-			if err := s.handleMessage(ctx, line); err != nil {
-				logger.Error("Error handling message", "error", err, "message", line)
-				// Continue processing other messages
-			}
-		}
-	}
+func newStdioTransport(framer Framer) *stdioTransport {
+	return &stdioTransport{stream: newFramedStream(framer, os.Stdin, os.Stdout)}
 }
 
-// handleMessage processes a single JSON-RPC message
-func (s *StdioServer) handleMessage(ctx context.Context, message string) error {
-	// Parse the raw message to determine the method
-	var rawMsg map[string]json.RawMessage
-	if err := json.Unmarshal([]byte(message), &rawMsg); err != nil {
-		return s.sendError(nil, mcp.NewParseError(err.Error()))
-	}
-
-	// Extract ID and method
-	var id json.RawMessage
-	if idRaw, exists := rawMsg["id"]; exists {
-		id = idRaw
-	}
+func (t *stdioTransport) ReadMessage() (json.RawMessage, error)  { return t.stream.Read() }
+func (t *stdioTransport) WriteMessage(msg json.RawMessage) error { return t.stream.Write(msg) }
 
-	var method string
-	if methodRaw, exists := rawMsg["method"]; exists {
-		if err := json.Unmarshal(methodRaw, &method); err != nil {
-			return s.sendError(id, mcp.NewInvalidRequestError("invalid method"))
-		}
-	}
+// Close is a no-op: stdin/stdout are the process's, not ours to close.
+func (t *stdioTransport) Close() error { return nil }
 
-	// Route the message based on method
-	switch method {
-	case mcp.RequestTypeInitialize:
-		return s.handleInitialize(ctx, id, message)
-	case mcp.RequestTypeListTools:
-		return s.handleListTools(ctx, id, message)
-	case mcp.RequestTypeCallTool:
-		return s.handleCallTool(ctx, id, message)
-	case mcp.RequestTypeShutdown:
-		return s.handleShutdown(ctx, id, message)
-	default:
-		return s.sendError(id, mcp.NewMethodNotFoundError(method))
-	}
+// StdioServer implements MCP protocol over stdin/stdout, on top of a
+// bidirectional jsonrpc2.Conn so the server can also push notifications and
+// requests back to the client, not just answer its calls.
+type StdioServer struct {
+	server    mcp.Server
+	transport Transport
+	conn      *jsonrpc2.Conn
+	logSinkID int64
 }
 
-// handleInitialize processes initialize requests
-func (s *StdioServer) handleInitialize(ctx context.Context, id json.RawMessage, message string) error {
-	var msg mcp.Message[mcp.InitializeRequest, any]
-	if err := json.Unmarshal([]byte(message), &msg); err != nil {
-		return s.sendError(id, mcp.NewInvalidParamsError(err.Error()))
-	}
-
-	if msg.Params == nil {
-		return s.sendError(id, mcp.NewInvalidParamsError("missing params"))
-	}
+// StdioOption configures a StdioServer at construction time.
+type StdioOption func(*stdioConfig)
 
-	response, err := s.server.Initialize(ctx, msg.Params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			return s.sendError(id, mcpErr)
-		}
-		return s.sendError(id, mcp.NewInternalError(err.Error()))
-	}
-
-	return s.sendResponse(id, response)
+type stdioConfig struct {
+	framer Framer
 }
 
-// handleListTools processes list tools requests
-func (s *StdioServer) handleListTools(ctx context.Context, id json.RawMessage, message string) error {
-	var msg mcp.Message[mcp.ListToolsRequest, any]
-	if err := json.Unmarshal([]byte(message), &msg); err != nil {
-		return s.sendError(id, mcp.NewInvalidParamsError(err.Error()))
-	}
-
-	params := &mcp.ListToolsRequest{}
-	if msg.Params != nil {
-		params = msg.Params
-	}
-
-	response, err := s.server.ListTools(ctx, params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			return s.sendError(id, mcpErr)
-		}
-		return s.sendError(id, mcp.NewInternalError(err.Error()))
-	}
-
-	return s.sendResponse(id, response)
+// WithFramer selects the wire framing NewStdioServer uses instead of the
+// default NDJSONFramer. Use ContentLengthFramer for LSP-style clients, or
+// AutoDetectFramer to sniff ndjson vs Content-Length from the first byte.
+func WithFramer(framer Framer) StdioOption {
+	return func(c *stdioConfig) { c.framer = framer }
 }
 
-// handleCallTool processes call tool requests
-func (s *StdioServer) handleCallTool(ctx context.Context, id json.RawMessage, message string) error {
-	var msg mcp.Message[mcp.CallToolRequest, any]
-	if err := json.Unmarshal([]byte(message), &msg); err != nil {
-		return s.sendError(id, mcp.NewInvalidParamsError(err.Error()))
-	}
-
-	if msg.Params == nil {
-		return s.sendError(id, mcp.NewInvalidParamsError("missing params"))
-	}
-
-	response, err := s.server.CallTool(ctx, msg.Params)
-	if err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			return s.sendError(id, mcpErr)
-		}
-		return s.sendError(id, mcp.NewToolExecutionError(msg.Params.Name, err))
+// NewStdioServer creates a new stdio server instance
+func NewStdioServer(server mcp.Server, opts ...StdioOption) *StdioServer {
+	cfg := stdioConfig{framer: NDJSONFramer{}}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	return s.sendResponse(id, response)
-}
+	s := &StdioServer{server: server, transport: newStdioTransport(cfg.framer)}
+	s.conn = jsonrpc2.NewConn(transportStream{s.transport}, newDispatcher(server).handle)
+	s.logSinkID = logger.AddSink(newLogNotifyCore(s.conn))
 
-// handleShutdown processes shutdown requests
-func (s *StdioServer) handleShutdown(ctx context.Context, id json.RawMessage, message string) error {
-	var msg mcp.Message[mcp.ShutdownRequest, any]
-	if err := json.Unmarshal([]byte(message), &msg); err != nil {
-		return s.sendError(id, mcp.NewInvalidParamsError(err.Error()))
+	if notifiable, ok := server.(notifierSetter); ok {
+		notifiable.SetNotifier(s.conn)
 	}
 
-	if err := s.server.Shutdown(ctx, msg.Params); err != nil {
-		var mcpErr *mcp.Error
-		if errors.As(err, &mcpErr) {
-			return s.sendError(id, mcpErr)
-		}
-		return s.sendError(id, mcp.NewInternalError(err.Error()))
-	}
-
-	// Send success response
-	return s.sendResponse(id, map[string]interface{}{})
-}
-
-// sendResponse sends a successful response
-func (s *StdioServer) sendResponse(id json.RawMessage, result any) error {
-	response := mcp.NewResponse(id, &result)
-	return s.writeMessage(response)
-}
-
-// sendError sends an error response
-func (s *StdioServer) sendError(id json.RawMessage, err *mcp.Error) error {
-	response := mcp.NewErrorMessage(id, err)
-	return s.writeMessage(response)
+	return s
 }
 
-// writeMessage writes a message to stdout
-func (s *StdioServer) writeMessage(msg interface{}) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		logger.Error("Failed to marshal response", "error", err)
-		return fmt.Errorf("marshal error: %w", err)
-	}
-
-	logger.Debug("Sending message", "message", string(data))
-
-	if _, err := s.writer.Write(data); err != nil {
-		logger.Error("Failed to write to stdout", "error", err)
-		return fmt.Errorf("write error: %w", err)
-	}
+// Start begins processing MCP messages from stdin
+func (s *StdioServer) Start(ctx context.Context) error {
+	logger.Info("Starting stdio MCP server")
+	defer s.transport.Close()
+	defer logger.RemoveSink(s.logSinkID)
 
-	if _, err := s.writer.Write([]byte("\n")); err != nil {
-		logger.Error("Failed to write newline to stdout", "error", err)
-		return fmt.Errorf("write newline error: %w", err)
+	err := s.conn.Run(ctx)
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		logger.Info("Stdio server shutting down")
+		return nil
 	}
 
-	return nil
+	logger.Error("Error running stdio connection", "error", err)
+	return err
 }
@@ -0,0 +1,24 @@
+package server
+
+import "encoding/json"
+
+// Transport is the full-duplex channel a single jsonrpc2.Conn runs on:
+// ReadMessage/WriteMessage frame one MCP message (or batch) at a time, and
+// Close releases whatever the transport holds open once the client
+// disconnects or the server shuts down. StdioServer and the Streamable HTTP
+// transport both implement it, so the same dispatcher drives the Tailscale
+// tools identically regardless of how the client connected.
+type Transport interface {
+	ReadMessage() (json.RawMessage, error)
+	WriteMessage(msg json.RawMessage) error
+	Close() error
+}
+
+// transportStream adapts a Transport to the jsonrpc2.Stream interface that
+// jsonrpc2.Conn actually reads and writes through.
+type transportStream struct {
+	t Transport
+}
+
+func (s transportStream) Read() (json.RawMessage, error) { return s.t.ReadMessage() }
+func (s transportStream) Write(msg json.RawMessage) error { return s.t.WriteMessage(msg) }
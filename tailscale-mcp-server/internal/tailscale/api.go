@@ -0,0 +1,920 @@
+// tailscale-mcp-server/internal/tailscale/api.go
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"github.com/tailscale/hujson"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+)
+
+// oauthTokenURL is the Tailscale API's OAuth2 client-credentials token
+// endpoint, used when cfg carries TailscaleOAuthClientID/Secret instead of
+// a static TailscaleAPIKey.
+const oauthTokenURL = "https://api.tailscale.com/api/v2/oauth/token"
+
+// APIClient talks to the Tailscale v2 management API
+// (https://api.tailscale.com/api/v2), as opposed to TailscaleCLI (shells out
+// to the `tailscale` binary) or the LocalAPI Client (speaks to tailscaled's
+// local socket). Unlike the other two, it requires API credentials: a static
+// API key or an OAuth2 client-credentials pair (see NewAPIClient).
+type APIClient struct {
+	apiKey     string
+	tailnet    string
+	baseURL    string
+	httpClient *http.Client
+
+	// limiter and breaker protect baseURL from this client's own request
+	// volume: limiter smooths out bursts, breaker stops sending requests
+	// altogether once the backend is clearly failing. Both are per-client
+	// (in practice per-baseURL, since a process normally has one APIClient)
+	// rather than a shared global, so independent APIClients can't starve
+	// each other's budget.
+	limiter *tokenBucket
+	breaker *circuitBreaker
+}
+
+// NewAPIClient creates a new Tailscale v2 API client from cfg. The tailnet
+// defaults to "-" (the API's shorthand for "the tailnet the API key belongs
+// to") when cfg doesn't specify one. When cfg carries an OAuth2
+// client-credentials pair instead of a static API key, the client's
+// httpClient transparently fetches and refreshes bearer tokens via
+// clientcredentials.Config rather than request() setting a fixed
+// Authorization header.
+func NewAPIClient(cfg *config.Config) *APIClient {
+	tailnet := cfg.TailscaleTailnet
+	if tailnet == "" {
+		tailnet = "-"
+	}
+
+	client := &APIClient{
+		apiKey:  cfg.TailscaleAPIKey,
+		tailnet: tailnet,
+		baseURL: strings.TrimSuffix(cfg.TailscaleAPIBaseURL, "/") + "/api/v2",
+		limiter: newTokenBucket(cfg.TailscaleAPIRateLimit, cfg.TailscaleAPIRateBurst),
+		breaker: newCircuitBreaker(cfg.TailscaleAPICircuitThreshold, cfg.TailscaleAPICircuitOpenDuration),
+	}
+
+	if cfg.TailscaleOAuthClientID != "" && cfg.TailscaleOAuthClientSecret != "" {
+		oauthCfg := &clientcredentials.Config{
+			ClientID:     cfg.TailscaleOAuthClientID,
+			ClientSecret: cfg.TailscaleOAuthClientSecret,
+			TokenURL:     oauthTokenURL,
+			Scopes:       splitOAuthScopes(cfg.TailscaleOAuthScopes),
+		}
+		client.httpClient = oauthCfg.Client(context.Background())
+		client.httpClient.Timeout = 30 * time.Second
+	} else {
+		client.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return client
+}
+
+// splitOAuthScopes parses TailscaleOAuthScopes' comma-separated form into
+// the slice clientcredentials.Config expects.
+func splitOAuthScopes(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var scopes []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// APIResponse wraps every APIClient call's result uniformly, so callers
+// check Success rather than juggling a separate error return for HTTP-level
+// failures (the Tailscale API's error body) versus transport failures.
+type APIResponse[T any] struct {
+	Success    bool   `json:"success"`
+	Data       T      `json:"data,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+
+	// ETag is the response's ETag header, when present (notably on GET
+	// ACL), for callers that need to round-trip it back via If-Match.
+	ETag string `json:"etag,omitempty"`
+}
+
+// apiErrorBody is the Tailscale API's standard JSON error envelope.
+type apiErrorBody struct {
+	Message string `json:"message"`
+}
+
+// isInsufficientScope reports whether a 401's error message indicates the
+// caller's OAuth token lacks a required scope, as opposed to missing or
+// expired credentials entirely.
+func isInsufficientScope(msg string) bool {
+	return strings.Contains(strings.ToLower(msg), "insufficient scope")
+}
+
+// request issues an authenticated request against the v2 API and decodes a
+// successful response body into out (skipped if out is nil), subject to
+// c's rate limiter and circuit breaker. extraHeaders are set on the request
+// in addition to Authorization and Content-Type.
+//
+// GET/DELETE and a handful of POST endpoints that are idempotent in effect
+// (see isIdempotent) are retried up to retryMaxAttempts times on a 429,
+// 5xx, or transport failure, honoring a Retry-After header when the server
+// sent one and falling back to exponential backoff with full jitter
+// otherwise. Every other method is sent at most once: retrying a non-
+// idempotent POST/PATCH/PUT risks double-applying a change whose first
+// attempt actually succeeded but whose response was lost.
+func request[T any](ctx context.Context, c *APIClient, method, path string, body []byte, contentType string, extraHeaders map[string]string) APIResponse[T] {
+	attempts := 1
+	idempotent := isIdempotent(method, path)
+	if idempotent {
+		attempts = retryMaxAttempts
+	}
+
+	var resp APIResponse[T]
+	var retryAfter time.Duration
+	for attempt := 0; attempt < attempts; attempt++ {
+		if !c.breaker.allow() {
+			return APIResponse[T]{Error: "circuit open", StatusCode: http.StatusServiceUnavailable}
+		}
+		if err := c.limiter.wait(ctx); err != nil {
+			return APIResponse[T]{Error: fmt.Sprintf("rate limiter: %v", err)}
+		}
+
+		var serverErr bool
+		resp, retryAfter, serverErr = doRequest[T](ctx, c, method, path, body, contentType, extraHeaders)
+		c.breaker.recordResult(serverErr)
+
+		if resp.Success || !idempotent || attempt == attempts-1 || !retryableStatus(resp.StatusCode) {
+			return resp
+		}
+
+		observeResilience(ResilienceEventRetry, "api")
+		if resp.StatusCode == http.StatusTooManyRequests {
+			observeResilience(ResilienceEventRateLimited, "api")
+		}
+		wait := retryAfter
+		if wait <= 0 {
+			wait = backoff(attempt)
+		}
+		logger.Debug("Tailscale API request retrying", "method", method, "path", path, "attempt", attempt+1, "wait", wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return APIResponse[T]{Error: ctx.Err().Error()}
+		case <-timer.C:
+		}
+	}
+	return resp
+}
+
+// doRequest performs a single attempt of the request method/path describes,
+// returning the decoded response, the Retry-After delay the server asked
+// for (zero if none/unparseable), and whether the failure (if any) counts
+// against c's circuit breaker (a transport error or 5xx, not a 4xx).
+func doRequest[T any](ctx context.Context, c *APIClient, method, path string, body []byte, contentType string, extraHeaders map[string]string) (APIResponse[T], time.Duration, bool) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return APIResponse[T]{Error: fmt.Sprintf("building request: %v", err)}, 0, false
+	}
+
+	// When c.httpClient was built from an OAuth2 clientcredentials.Config,
+	// its Transport injects the Authorization header itself (refreshing the
+	// token as needed); apiKey is only set for the static-key case.
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	if body != nil {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range extraHeaders {
+		req.Header.Set(k, v)
+	}
+
+	logger.Debug("Tailscale API request", append([]any{"method", method, "path", path}, logFields(ctx)...)...)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		observeRequest("api", method, start, false)
+		return APIResponse[T]{Error: fmt.Sprintf("request failed: %v", err)}, 0, true
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		observeRequest("api", method, start, false)
+		return APIResponse[T]{StatusCode: resp.StatusCode, Error: fmt.Sprintf("reading response: %v", err)}, 0, true
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errBody apiErrorBody
+		msg := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &errBody) == nil && errBody.Message != "" {
+			msg = errBody.Message
+		}
+		if resp.StatusCode == http.StatusUnauthorized && isInsufficientScope(msg) {
+			// Distinct from a generic 401 (bad/expired credentials): the
+			// OAuth client authenticated fine but wasn't granted a scope
+			// this call needs, which an operator fixes by re-minting the
+			// OAuth client with more scopes rather than rotating a key.
+			logger.Warn("Tailscale API request rejected for insufficient OAuth scope", append([]any{"method", method, "path", path, "message", msg}, logFields(ctx)...)...)
+		} else {
+			logger.Warn("Tailscale API error", append([]any{"method", method, "path", path, "status", resp.StatusCode, "message", msg}, logFields(ctx)...)...)
+		}
+		observeRequest("api", method, start, false)
+		retryAfter, _ := parseRetryAfter(resp.Header)
+		return APIResponse[T]{StatusCode: resp.StatusCode, Error: msg}, retryAfter, resp.StatusCode >= 500
+	}
+
+	out := APIResponse[T]{Success: true, StatusCode: resp.StatusCode, ETag: resp.Header.Get("ETag")}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &out.Data); err != nil {
+			observeRequest("api", method, start, false)
+			return APIResponse[T]{StatusCode: resp.StatusCode, Error: fmt.Sprintf("decoding response: %v", err)}, 0, false
+		}
+	}
+	observeRequest("api", method, start, true)
+	return out, 0, false
+}
+
+// ListDevices returns every device in the tailnet.
+func (c *APIClient) ListDevices(ctx context.Context) APIResponse[DeviceListResponse] {
+	return request[DeviceListResponse](ctx, c, http.MethodGet, fmt.Sprintf("/tailnet/%s/devices", c.tailnet), nil, "", nil)
+}
+
+// GetDevice returns a single device by ID.
+func (c *APIClient) GetDevice(ctx context.Context, deviceID string) APIResponse[Device] {
+	return request[Device](ctx, c, http.MethodGet, "/device/"+deviceID, nil, "", nil)
+}
+
+// SetDeviceAuthorized approves or revokes a device's access to the tailnet.
+func (c *APIClient) SetDeviceAuthorized(ctx context.Context, deviceID string, authorized bool) APIResponse[map[string]any] {
+	body, err := json.Marshal(DeviceAuthorization{Authorized: authorized})
+	if err != nil {
+		return APIResponse[map[string]any]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/authorized", body, "", nil)
+}
+
+// DeleteDevice permanently removes a device from the tailnet.
+func (c *APIClient) DeleteDevice(ctx context.Context, deviceID string) APIResponse[map[string]any] {
+	return request[map[string]any](ctx, c, http.MethodDelete, "/device/"+deviceID, nil, "", nil)
+}
+
+// ExpireDeviceKey expires a device's node key immediately, forcing it to
+// re-authenticate before it can rejoin the tailnet.
+func (c *APIClient) ExpireDeviceKey(ctx context.Context, deviceID string) APIResponse[map[string]any] {
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/key/expire", nil, "", nil)
+}
+
+// SetDeviceKeyExpiryDisabled toggles whether a device's node key is exempt
+// from the tailnet's key expiry policy, keeping it connected indefinitely
+// without manual reauthentication (commonly used for servers and other
+// unattended nodes).
+func (c *APIClient) SetDeviceKeyExpiryDisabled(ctx context.Context, deviceID string, disabled bool) APIResponse[map[string]any] {
+	body, err := json.Marshal(DeviceKey{KeyExpiryDisabled: disabled})
+	if err != nil {
+		return APIResponse[map[string]any]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/key", body, "", nil)
+}
+
+// SetDeviceTags replaces a device's ACL tags.
+func (c *APIClient) SetDeviceTags(ctx context.Context, deviceID string, tags []string) APIResponse[map[string]any] {
+	body, err := json.Marshal(DeviceTags{Tags: tags})
+	if err != nil {
+		return APIResponse[map[string]any]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/tags", body, "", nil)
+}
+
+// SetDeviceEnabledRoutes sets the subnet routes a device is enabled to
+// serve, out of the routes it advertises. Passing routes that omit a CIDR
+// the device previously served stops it serving that route; this is the
+// primitive HA subnet router failover is built on.
+func (c *APIClient) SetDeviceEnabledRoutes(ctx context.Context, deviceID string, routes []string) APIResponse[map[string]any] {
+	if routes == nil {
+		routes = []string{}
+	}
+	body, err := json.Marshal(map[string][]string{"routes": routes})
+	if err != nil {
+		return APIResponse[map[string]any]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/routes", body, "", nil)
+}
+
+// SetDeviceName renames a device's tailnet hostname.
+func (c *APIClient) SetDeviceName(ctx context.Context, deviceID string, name string) APIResponse[map[string]any] {
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return APIResponse[map[string]any]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[map[string]any](ctx, c, http.MethodPost, "/device/"+deviceID+"/name", body, "", nil)
+}
+
+// ListAuthKeys returns every auth key on the configured tailnet. The API
+// only includes the plaintext Key field in the response from CreateAuthKey,
+// right after creation; keys returned here have it blank.
+func (c *APIClient) ListAuthKeys(ctx context.Context) APIResponse[AuthKeyListResponse] {
+	return request[AuthKeyListResponse](ctx, c, http.MethodGet, fmt.Sprintf("/tailnet/%s/keys", c.tailnet), nil, "", nil)
+}
+
+// CreateAuthKey creates a new auth key with the given capabilities.
+func (c *APIClient) CreateAuthKey(ctx context.Context, req AuthKeyRequest) APIResponse[AuthKey] {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return APIResponse[AuthKey]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[AuthKey](ctx, c, http.MethodPost, fmt.Sprintf("/tailnet/%s/keys", c.tailnet), body, "", nil)
+}
+
+// RevokeAuthKey revokes an auth key by ID, preventing any further use.
+func (c *APIClient) RevokeAuthKey(ctx context.Context, keyID string) APIResponse[map[string]any] {
+	return request[map[string]any](ctx, c, http.MethodDelete, "/tailnet/"+c.tailnet+"/keys/"+keyID, nil, "", nil)
+}
+
+// GetTailnetInfo returns metadata about the configured tailnet.
+func (c *APIClient) GetTailnetInfo(ctx context.Context) APIResponse[TailnetInfo] {
+	return request[TailnetInfo](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet, nil, "", nil)
+}
+
+// TailnetSettings is the tailnet-wide posture configuration exposed at
+// GET/PATCH /tailnet/{tailnet}/settings: device/user approval, key
+// rotation, and related knobs that apply across the whole tailnet rather
+// than to one device.
+type TailnetSettings struct {
+	DevicesApprovalOn                      bool   `json:"devicesApprovalOn"`
+	DevicesAutoUpdatesOn                   bool   `json:"devicesAutoUpdatesOn"`
+	DevicesKeyDurationDays                 int    `json:"devicesKeyDurationDays"`
+	UsersApprovalOn                        bool   `json:"usersApprovalOn"`
+	UsersRoleAllowedToJoinExternalTailnets string `json:"usersRoleAllowedToJoinExternalTailnets"`
+	PostureIdentityCollectionOn            bool   `json:"postureIdentityCollectionOn"`
+	RegionalRoutingOn                      bool   `json:"regionalRoutingOn"`
+	NetworkFlowLoggingOn                   bool   `json:"networkFlowLoggingOn"`
+}
+
+// TailnetSettingsPatch is a sparse update to TailnetSettings: only non-nil
+// fields are sent, so UpdateTailnetSettings can flip a single knob (e.g.
+// DevicesApprovalOn) without having to first read back and resend every
+// other setting.
+type TailnetSettingsPatch struct {
+	DevicesApprovalOn                      *bool   `json:"devicesApprovalOn,omitempty"`
+	DevicesAutoUpdatesOn                   *bool   `json:"devicesAutoUpdatesOn,omitempty"`
+	DevicesKeyDurationDays                 *int    `json:"devicesKeyDurationDays,omitempty"`
+	UsersApprovalOn                        *bool   `json:"usersApprovalOn,omitempty"`
+	UsersRoleAllowedToJoinExternalTailnets *string `json:"usersRoleAllowedToJoinExternalTailnets,omitempty"`
+	PostureIdentityCollectionOn            *bool   `json:"postureIdentityCollectionOn,omitempty"`
+	RegionalRoutingOn                      *bool   `json:"regionalRoutingOn,omitempty"`
+	NetworkFlowLoggingOn                   *bool   `json:"networkFlowLoggingOn,omitempty"`
+}
+
+// GetTailnetSettings fetches the tailnet's current posture configuration.
+func (c *APIClient) GetTailnetSettings(ctx context.Context) APIResponse[TailnetSettings] {
+	return request[TailnetSettings](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet+"/settings", nil, "", nil)
+}
+
+// UpdateTailnetSettings applies patch to the tailnet's posture
+// configuration; only its non-nil fields are changed.
+func (c *APIClient) UpdateTailnetSettings(ctx context.Context, patch TailnetSettingsPatch) APIResponse[TailnetSettings] {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return APIResponse[TailnetSettings]{Error: fmt.Sprintf("encoding settings patch: %v", err)}
+	}
+	return request[TailnetSettings](ctx, c, http.MethodPatch, "/tailnet/"+c.tailnet+"/settings", body, "application/json", nil)
+}
+
+// TestConnection checks that the configured API key and tailnet can reach
+// the Tailscale API, for use by health/status tools.
+func (c *APIClient) TestConnection(ctx context.Context) APIResponse[map[string]string] {
+	resp := c.GetTailnetInfo(ctx)
+	if !resp.Success {
+		return APIResponse[map[string]string]{StatusCode: resp.StatusCode, Error: resp.Error}
+	}
+
+	return APIResponse[map[string]string]{
+		Success:    true,
+		StatusCode: resp.StatusCode,
+		Data: map[string]string{
+			"status":  "connected",
+			"tailnet": resp.Data.Name,
+		},
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// ACL
+////////////////////////////////////////////////////////////////////////////////
+
+// ACLPolicy is the ACL endpoints' response shape: the policy document plus
+// the ETag the server returned alongside it, which a later SetACL call
+// should echo back via If-Match to detect concurrent edits.
+type ACLPolicy struct {
+	// HuJSON holds the policy exactly as the API returned it, comments,
+	// trailing commas and all, since round-tripping it through
+	// encoding/json would silently drop both.
+	HuJSON string `json:"hujson"`
+	ETag   string `json:"etag"`
+}
+
+// GetACL fetches the tailnet's current ACL policy as HuJSON, along with the
+// ETag to use for a subsequent conditional SetACL.
+func (c *APIClient) GetACL(ctx context.Context) APIResponse[ACLPolicy] {
+	resp := request[json.RawMessage](ctx, c, http.MethodGet, fmt.Sprintf("/tailnet/%s/acl", c.tailnet), nil, "", map[string]string{
+		"Accept": "application/hujson",
+	})
+	if !resp.Success {
+		return APIResponse[ACLPolicy]{StatusCode: resp.StatusCode, Error: resp.Error}
+	}
+
+	return APIResponse[ACLPolicy]{
+		Success:    true,
+		StatusCode: resp.StatusCode,
+		ETag:       resp.ETag,
+		Data:       ACLPolicy{HuJSON: string(resp.Data), ETag: resp.ETag},
+	}
+}
+
+// aclContentType reports the Content-Type to send a policy body as: HuJSON
+// when the text isn't already strict JSON (it has comments, trailing
+// commas, or similar), plain JSON otherwise. The API accepts either, but
+// sending the narrower type when we can avoids surprising a server-side
+// linter expecting strict JSON for a strict-JSON submission.
+func aclContentType(policy string) string {
+	if json.Valid([]byte(policy)) {
+		return "application/json"
+	}
+	return "application/hujson"
+}
+
+// SetACL replaces the tailnet's ACL policy. ifMatch, when non-empty, is sent
+// as If-Match so the write is rejected with a conflict if the policy changed
+// since the caller last read it, instead of silently clobbering it.
+func (c *APIClient) SetACL(ctx context.Context, policy string, ifMatch string) APIResponse[ACLPolicy] {
+	headers := map[string]string{}
+	if ifMatch != "" {
+		headers["If-Match"] = ifMatch
+	}
+
+	resp := request[json.RawMessage](ctx, c, http.MethodPost, fmt.Sprintf("/tailnet/%s/acl", c.tailnet), []byte(policy), aclContentType(policy), headers)
+	if !resp.Success {
+		return APIResponse[ACLPolicy]{StatusCode: resp.StatusCode, Error: resp.Error}
+	}
+
+	return APIResponse[ACLPolicy]{
+		Success:    true,
+		StatusCode: resp.StatusCode,
+		ETag:       resp.ETag,
+		Data:       ACLPolicy{HuJSON: policy, ETag: resp.ETag},
+	}
+}
+
+// ACLValidationResult reports whether a submitted policy (or test set) is
+// valid and, if not, why.
+type ACLValidationResult struct {
+	Valid   bool     `json:"valid"`
+	Errors  []string `json:"errors,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// aclValidateResponse is the raw shape POST /acl/validate responds with: an
+// empty 200 body (or one with only a message) on success, or a message
+// describing the first failure otherwise. Our own request() helper already
+// turns a non-2xx status into resp.Error, so ACLValidationResult.Valid just
+// mirrors resp.Success.
+type aclValidateResponse struct {
+	Message string `json:"message"`
+}
+
+// ValidateACL checks a policy document (or, per the API, a list of ACL
+// tests run against the tailnet's current stored policy) for syntax and
+// semantic errors without writing it.
+func (c *APIClient) ValidateACL(ctx context.Context, policy string) ACLValidationResult {
+	resp := request[aclValidateResponse](ctx, c, http.MethodPost, fmt.Sprintf("/tailnet/%s/acl/validate", c.tailnet), []byte(policy), aclContentType(policy), nil)
+	if !resp.Success {
+		return ACLValidationResult{Valid: false, Errors: []string{resp.Error}}
+	}
+	return ACLValidationResult{Valid: true, Message: resp.Data.Message}
+}
+
+// ACLTestEntry is one source/destination rule to evaluate against a policy,
+// matching the API's acltest shape.
+type ACLTestEntry struct {
+	Src    string   `json:"src"`
+	Accept []string `json:"accept,omitempty"`
+	Deny   []string `json:"deny,omitempty"`
+}
+
+// RunACLTests evaluates tests against the tailnet's current stored ACL via
+// the same /acl/validate endpoint, which accepts a JSON array of
+// ACLTestEntry instead of a full policy document.
+func (c *APIClient) RunACLTests(ctx context.Context, tests []ACLTestEntry) ACLValidationResult {
+	body, err := json.Marshal(tests)
+	if err != nil {
+		return ACLValidationResult{Valid: false, Errors: []string{fmt.Sprintf("encoding tests: %v", err)}}
+	}
+
+	resp := request[aclValidateResponse](ctx, c, http.MethodPost, fmt.Sprintf("/tailnet/%s/acl/validate", c.tailnet), body, "application/json", nil)
+	if !resp.Success {
+		return ACLValidationResult{Valid: false, Errors: []string{resp.Error}}
+	}
+	return ACLValidationResult{Valid: true, Message: resp.Data.Message}
+}
+
+// ACLPreviewMatch is one device the preview endpoint reports as affected by
+// a (possibly not-yet-committed) policy, for a given previewFor identity.
+type ACLPreviewMatch struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// aclPreviewResponse is the v2 API's /acl/preview response shape.
+type aclPreviewResponse struct {
+	Matches []ACLPreviewMatch `json:"matches"`
+}
+
+// PreviewACL reports which nodes/users previewFor (an IP or login name)
+// would be able to reach under policy, without committing it. It's how
+// ACLTool's "diff" action computes a who-is-affected list before a write.
+// previewType selects what kind of identity previewFor is - "ipport" (an
+// IP, the default when empty) or "user" (a login name) - matching the
+// API's own "type" query parameter.
+func (c *APIClient) PreviewACL(ctx context.Context, policy string, previewFor string, previewType string) APIResponse[[]ACLPreviewMatch] {
+	if previewType == "" {
+		previewType = "ipport"
+	}
+	path := fmt.Sprintf("/tailnet/%s/acl/preview?previewFor=%s&type=%s", c.tailnet, previewFor, previewType)
+	resp := request[aclPreviewResponse](ctx, c, http.MethodPost, path, []byte(policy), aclContentType(policy), nil)
+	if !resp.Success {
+		return APIResponse[[]ACLPreviewMatch]{StatusCode: resp.StatusCode, Error: resp.Error}
+	}
+	return APIResponse[[]ACLPreviewMatch]{Success: true, StatusCode: resp.StatusCode, Data: resp.Data.Matches}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Policy file (parsed ACL document)
+////////////////////////////////////////////////////////////////////////////////
+
+// ACLRow is one entry in a policy document's "acls" list. Source/Destination
+// hold the newer src/dst shape; Users/Ports hold the older shape some
+// tailnets still carry. The API accepts either on write, and GetPolicyFile
+// passes through whichever fields were actually present on read.
+type ACLRow struct {
+	Action      string   `json:"action,omitempty"`
+	Proto       string   `json:"proto,omitempty"`
+	Users       []string `json:"users,omitempty"`
+	Ports       []string `json:"ports,omitempty"`
+	Source      []string `json:"src,omitempty"`
+	Destination []string `json:"dst,omitempty"`
+}
+
+// ACLTest is one entry in a policy document's "tests" list, checked against
+// the rest of the document whenever the policy is validated or set.
+type ACLTest struct {
+	User   string   `json:"user,omitempty"`
+	Source string   `json:"src,omitempty"`
+	Proto  string   `json:"proto,omitempty"`
+	Accept []string `json:"accept,omitempty"`
+	Deny   []string `json:"deny,omitempty"`
+}
+
+// ACLAutoApprovers is a policy document's "autoApprovers" section: routes
+// and exit nodes a tagged device may advertise without manual approval.
+type ACLAutoApprovers struct {
+	Routes   map[string][]string `json:"routes,omitempty"`
+	ExitNode []string            `json:"exitNode,omitempty"`
+}
+
+// ACLSSHRule is one entry in a policy document's "ssh" list.
+type ACLSSHRule struct {
+	Action      string   `json:"action,omitempty"`
+	Source      []string `json:"src,omitempty"`
+	Destination []string `json:"dst,omitempty"`
+	Users       []string `json:"users,omitempty"`
+}
+
+// ACLNodeAttrGrant is one entry in a policy document's "nodeAttrs" list,
+// granting the listed attr(s) to the matching target(s).
+type ACLNodeAttrGrant struct {
+	Target []string `json:"target,omitempty"`
+	Attr   []string `json:"attr,omitempty"`
+}
+
+// ACLDocument is the parsed shape of a tailnet's policy file, for callers
+// that want to read or build individual sections instead of round-tripping
+// opaque HuJSON. Groups/Hosts/TagOwners are left as plain maps since their
+// values are simple string lists with no further structure worth a named
+// type.
+type ACLDocument struct {
+	Groups        map[string][]string `json:"groups,omitempty"`
+	Hosts         map[string]string   `json:"hosts,omitempty"`
+	TagOwners     map[string][]string `json:"tagOwners,omitempty"`
+	ACLs          []ACLRow            `json:"acls,omitempty"`
+	Tests         []ACLTest           `json:"tests,omitempty"`
+	AutoApprovers *ACLAutoApprovers   `json:"autoApprovers,omitempty"`
+	SSH           []ACLSSHRule        `json:"ssh,omitempty"`
+	NodeAttrs     []ACLNodeAttrGrant  `json:"nodeAttrs,omitempty"`
+}
+
+// GetPolicyFile fetches the tailnet's current policy. format selects the
+// shape of PolicyFileResult.Parsed: "json" populates it from the HuJSON
+// response, anything else (including empty) leaves it nil and only
+// PolicyFileResult.HuJSON/ETag are set. The raw HuJSON is always returned
+// either way, since parsing is lossy (comments, trailing commas, and
+// sections this build doesn't model are dropped from Parsed).
+func (c *APIClient) GetPolicyFile(ctx context.Context, format string) APIResponse[PolicyFileResult] {
+	acl := c.GetACL(ctx)
+	if !acl.Success {
+		return APIResponse[PolicyFileResult]{StatusCode: acl.StatusCode, Error: acl.Error}
+	}
+
+	result := PolicyFileResult{HuJSON: acl.Data.HuJSON, ETag: acl.Data.ETag}
+	if strings.EqualFold(format, "json") {
+		parsed, err := parseACLDocument(acl.Data.HuJSON)
+		if err != nil {
+			return APIResponse[PolicyFileResult]{Error: fmt.Sprintf("parsing policy file: %v", err)}
+		}
+		result.Parsed = parsed
+	}
+
+	return APIResponse[PolicyFileResult]{Success: true, StatusCode: acl.StatusCode, ETag: acl.ETag, Data: result}
+}
+
+// PolicyFileResult is GetPolicyFile's response shape.
+type PolicyFileResult struct {
+	HuJSON string       `json:"hujson"`
+	ETag   string       `json:"etag"`
+	Parsed *ACLDocument `json:"parsed,omitempty"`
+}
+
+// parseACLDocument standardizes HuJSON policy text to strict JSON and
+// unmarshals it into an ACLDocument.
+func parseACLDocument(policy string) (*ACLDocument, error) {
+	standardized, err := hujson.Standardize([]byte(policy))
+	if err != nil {
+		return nil, err
+	}
+	var doc ACLDocument
+	if err := json.Unmarshal(standardized, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// SetPolicyFile replaces the tailnet's policy. It's SetACL under a name
+// that matches GetPolicyFile/ValidatePolicyFile/PreviewPolicyFile, for
+// callers building or editing a policy via the typed ACLDocument sections
+// above rather than hand-editing HuJSON.
+func (c *APIClient) SetPolicyFile(ctx context.Context, content string, etag string) APIResponse[ACLPolicy] {
+	return c.SetACL(ctx, content, etag)
+}
+
+// ValidatePolicyFile validates a proposed policy document without writing
+// it. It's ValidateACL under the PolicyFile-subsystem name.
+func (c *APIClient) ValidatePolicyFile(ctx context.Context, content string) ACLValidationResult {
+	return c.ValidateACL(ctx, content)
+}
+
+// PreviewPolicyFile reports which nodes/users target would be able to reach
+// under a proposed policy document, without committing it. previewType
+// selects whether target is an IP ("ipport", the default) or a login name
+// ("user"). It's PreviewACL under the PolicyFile-subsystem name.
+func (c *APIClient) PreviewPolicyFile(ctx context.Context, content string, previewType string, target string) APIResponse[[]ACLPreviewMatch] {
+	return c.PreviewACL(ctx, content, target, previewType)
+}
+
+// Subscription is one event type a Webhook can be subscribed to, per
+// https://tailscale.com/api#description/webhooks.
+type Subscription string
+
+const (
+	SubscriptionNodeCreated                    Subscription = "nodeCreated"
+	SubscriptionNodeDeleted                    Subscription = "nodeDeleted"
+	SubscriptionNodeApproved                   Subscription = "nodeApproved"
+	SubscriptionNodeKeyExpiringInOneDay        Subscription = "nodeKeyExpiringInOneDay"
+	SubscriptionNodeKeyExpired                 Subscription = "nodeKeyExpired"
+	SubscriptionUserApproved                   Subscription = "userApproved"
+	SubscriptionUserSuspended                  Subscription = "userSuspended"
+	SubscriptionUserDeleted                    Subscription = "userDeleted"
+	SubscriptionPolicyUpdate                   Subscription = "policyUpdate"
+	SubscriptionExitNodeIPForwardingNotEnabled Subscription = "exitNodeIPForwardingNotEnabled"
+	SubscriptionSubnetIPForwardingNotEnabled   Subscription = "subnetIPForwardingNotEnabled"
+)
+
+// Webhook is a tailnet event subscription delivered to EndpointURL as a
+// signed HTTP POST; see internal/webhooks for verifying and consuming the
+// deliveries this describes.
+type Webhook struct {
+	EndpointID       string         `json:"endpointId"`
+	EndpointURL      string         `json:"endpointUrl"`
+	ProviderType     string         `json:"providerType,omitempty"`
+	Subscriptions    []Subscription `json:"subscriptions"`
+	Secret           string         `json:"secret,omitempty"`
+	CreatorLoginName string         `json:"creatorLoginName,omitempty"`
+	Created          string         `json:"created,omitempty"`
+	LastModified     string         `json:"lastModified,omitempty"`
+}
+
+// WebhookListResponse is the body of GET /tailnet/{tailnet}/webhooks.
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookRequest is the body of POST /tailnet/{tailnet}/webhooks.
+type WebhookRequest struct {
+	EndpointURL   string         `json:"endpointUrl"`
+	ProviderType  string         `json:"providerType,omitempty"`
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// ListWebhooks returns every webhook endpoint registered on the tailnet.
+func (c *APIClient) ListWebhooks(ctx context.Context) APIResponse[WebhookListResponse] {
+	return request[WebhookListResponse](ctx, c, http.MethodGet, fmt.Sprintf("/tailnet/%s/webhooks", c.tailnet), nil, "", nil)
+}
+
+// CreateWebhook registers a new webhook endpoint. The response's Secret is
+// only ever populated on this call and on RotateWebhookSecret; store it,
+// since GetWebhook/ListWebhooks never return it again.
+func (c *APIClient) CreateWebhook(ctx context.Context, req WebhookRequest) APIResponse[Webhook] {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return APIResponse[Webhook]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[Webhook](ctx, c, http.MethodPost, fmt.Sprintf("/tailnet/%s/webhooks", c.tailnet), body, "", nil)
+}
+
+// GetWebhook returns a single webhook endpoint by ID.
+func (c *APIClient) GetWebhook(ctx context.Context, endpointID string) APIResponse[Webhook] {
+	return request[Webhook](ctx, c, http.MethodGet, "/webhooks/"+endpointID, nil, "", nil)
+}
+
+// DeleteWebhook permanently removes a webhook endpoint.
+func (c *APIClient) DeleteWebhook(ctx context.Context, endpointID string) APIResponse[map[string]any] {
+	return request[map[string]any](ctx, c, http.MethodDelete, "/webhooks/"+endpointID, nil, "", nil)
+}
+
+// TestWebhook asks Tailscale to send a test event to an existing webhook
+// endpoint, for an operator to confirm delivery works end to end.
+func (c *APIClient) TestWebhook(ctx context.Context, endpointID string) APIResponse[map[string]any] {
+	return request[map[string]any](ctx, c, http.MethodPost, "/webhooks/"+endpointID+"/test", nil, "", nil)
+}
+
+// RotateWebhookSecret generates a new signing secret for a webhook
+// endpoint, invalidating the old one. Like CreateWebhook, the new Secret is
+// only returned here; it is never readable again afterwards.
+func (c *APIClient) RotateWebhookSecret(ctx context.Context, endpointID string) APIResponse[Webhook] {
+	return request[Webhook](ctx, c, http.MethodPost, "/webhooks/"+endpointID+"/rotate", nil, "", nil)
+}
+
+// DNSNameservers is the body of GET/POST /tailnet/{tailnet}/dns/nameservers:
+// the tailnet's global (non-split) DNS nameservers.
+type DNSNameservers struct {
+	DNS []string `json:"dns"`
+}
+
+// DNSPreferences is the body of GET/POST /tailnet/{tailnet}/dns/preferences.
+type DNSPreferences struct {
+	MagicDNS bool `json:"magicDNS"`
+}
+
+// DNSSearchPaths is the body of GET/POST /tailnet/{tailnet}/dns/searchpaths.
+type DNSSearchPaths struct {
+	SearchPaths []string `json:"searchPaths"`
+}
+
+// SplitDNS maps a domain to the nameservers queries for it should be routed
+// to; it's both the body of GET /tailnet/{tailnet}/dns/split-dns and the
+// patch PatchSplitDNS sends, where setting a domain to an empty/nil slice
+// removes that domain's split-DNS route entirely.
+type SplitDNS map[string][]string
+
+// validateNameservers reports the first entry in ns that isn't a valid IP
+// address (the API itself would reject it, but failing fast here gives a
+// clearer error than an opaque 400 from the server).
+func validateNameservers(ns []string) error {
+	for _, addr := range ns {
+		if net.ParseIP(addr) == nil {
+			return fmt.Errorf("nameserver %q is not a valid IP address", addr)
+		}
+	}
+	return nil
+}
+
+// validateFQDNs reports the first entry in names that doesn't look like a
+// hostname, reusing validTargetPattern (the same check
+// TailscaleCLI.validateTarget applies to CLI target arguments) rather than a
+// second, divergent pattern.
+func validateFQDNs(names []string) error {
+	for _, name := range names {
+		if !validTargetPattern.MatchString(name) {
+			return fmt.Errorf("%q is not a valid domain name", name)
+		}
+	}
+	return nil
+}
+
+// GetDNSNameservers fetches the tailnet's global DNS nameservers.
+func (c *APIClient) GetDNSNameservers(ctx context.Context) APIResponse[DNSNameservers] {
+	return request[DNSNameservers](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet+"/dns/nameservers", nil, "", nil)
+}
+
+// SetDNSNameservers replaces the tailnet's global DNS nameservers.
+func (c *APIClient) SetDNSNameservers(ctx context.Context, nameservers []string) APIResponse[DNSNameservers] {
+	if err := validateNameservers(nameservers); err != nil {
+		return APIResponse[DNSNameservers]{Error: err.Error()}
+	}
+	body, err := json.Marshal(DNSNameservers{DNS: nameservers})
+	if err != nil {
+		return APIResponse[DNSNameservers]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[DNSNameservers](ctx, c, http.MethodPost, "/tailnet/"+c.tailnet+"/dns/nameservers", body, "", nil)
+}
+
+// GetDNSPreferences fetches whether MagicDNS is enabled for the tailnet.
+func (c *APIClient) GetDNSPreferences(ctx context.Context) APIResponse[DNSPreferences] {
+	return request[DNSPreferences](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet+"/dns/preferences", nil, "", nil)
+}
+
+// SetDNSPreferences turns MagicDNS on or off for the tailnet. The API
+// requires at least one nameserver to already be configured before MagicDNS
+// can be enabled; a caller that gets a failure back should check
+// GetDNSNameservers first.
+func (c *APIClient) SetDNSPreferences(ctx context.Context, magicDNS bool) APIResponse[DNSPreferences] {
+	body, err := json.Marshal(DNSPreferences{MagicDNS: magicDNS})
+	if err != nil {
+		return APIResponse[DNSPreferences]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[DNSPreferences](ctx, c, http.MethodPost, "/tailnet/"+c.tailnet+"/dns/preferences", body, "", nil)
+}
+
+// GetDNSSearchPaths fetches the tailnet's DNS search paths.
+func (c *APIClient) GetDNSSearchPaths(ctx context.Context) APIResponse[DNSSearchPaths] {
+	return request[DNSSearchPaths](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet+"/dns/searchpaths", nil, "", nil)
+}
+
+// SetDNSSearchPaths replaces the tailnet's DNS search paths.
+func (c *APIClient) SetDNSSearchPaths(ctx context.Context, searchPaths []string) APIResponse[DNSSearchPaths] {
+	if err := validateFQDNs(searchPaths); err != nil {
+		return APIResponse[DNSSearchPaths]{Error: err.Error()}
+	}
+	body, err := json.Marshal(DNSSearchPaths{SearchPaths: searchPaths})
+	if err != nil {
+		return APIResponse[DNSSearchPaths]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[DNSSearchPaths](ctx, c, http.MethodPost, "/tailnet/"+c.tailnet+"/dns/searchpaths", body, "", nil)
+}
+
+// GetSplitDNS fetches the tailnet's split-DNS domain-to-nameserver routes.
+func (c *APIClient) GetSplitDNS(ctx context.Context) APIResponse[SplitDNS] {
+	return request[SplitDNS](ctx, c, http.MethodGet, "/tailnet/"+c.tailnet+"/dns/split-dns", nil, "", nil)
+}
+
+// PatchSplitDNS merges patch into the tailnet's split-DNS configuration:
+// each domain in patch is set to the given nameservers, or removed
+// entirely if its slice is empty/nil. Domains not mentioned in patch are
+// left untouched.
+func (c *APIClient) PatchSplitDNS(ctx context.Context, patch SplitDNS) APIResponse[SplitDNS] {
+	if err := validateFQDNs(splitDNSDomains(patch)); err != nil {
+		return APIResponse[SplitDNS]{Error: err.Error()}
+	}
+	for domain, nameservers := range patch {
+		if err := validateNameservers(nameservers); err != nil {
+			return APIResponse[SplitDNS]{Error: fmt.Sprintf("domain %q: %s", domain, err)}
+		}
+	}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return APIResponse[SplitDNS]{Error: fmt.Sprintf("encoding request: %v", err)}
+	}
+	return request[SplitDNS](ctx, c, http.MethodPatch, "/tailnet/"+c.tailnet+"/dns/split-dns", body, "application/json", nil)
+}
+
+// splitDNSDomains returns s's keys, for validateFQDNs.
+func splitDNSDomains(s SplitDNS) []string {
+	domains := make([]string, 0, len(s))
+	for domain := range s {
+		domains = append(domains, domain)
+	}
+	return domains
+}
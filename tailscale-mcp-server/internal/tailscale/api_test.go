@@ -68,6 +68,39 @@ func TestNewAPIClientDefaults(t *testing.T) {
 	}
 }
 
+func TestNewAPIClientOAuth(t *testing.T) {
+	cfg := &config.Config{
+		TailscaleOAuthClientID:     "test-client-id",
+		TailscaleOAuthClientSecret: "test-client-secret",
+		TailscaleOAuthScopes:       "devices:core, devices:routes",
+		TailscaleTailnet:           "test-tailnet",
+		TailscaleAPIBaseURL:        "https://api.tailscale.com",
+	}
+
+	client := NewAPIClient(cfg)
+
+	if client.apiKey != "" {
+		t.Errorf("Expected no static API key when OAuth is configured, got '%s'", client.apiKey)
+	}
+	if client.httpClient == nil {
+		t.Fatal("Expected a non-nil httpClient")
+	}
+}
+
+func TestSplitOAuthScopes(t *testing.T) {
+	got := splitOAuthScopes("devices:core, devices:routes ,")
+	want := []string{"devices:core", "devices:routes"}
+
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
 func TestListDevices(t *testing.T) {
 	mockDevices := DeviceListResponse{
 		Devices: []Device{
@@ -228,6 +261,69 @@ func TestAPIError(t *testing.T) {
 	}
 }
 
+func TestIsInsufficientScope(t *testing.T) {
+	if !isInsufficientScope("request failed: insufficient scope") {
+		t.Error("Expected message containing 'insufficient scope' to match")
+	}
+	if !isInsufficientScope("Insufficient Scope for this operation") {
+		t.Error("Expected the match to be case-insensitive")
+	}
+	if isInsufficientScope("unauthorized") {
+		t.Error("Expected a generic unauthorized message not to match")
+	}
+}
+
+func TestAPIErrorInsufficientScope(t *testing.T) {
+	client, server := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{
+			"message": "insufficient scope",
+		}); err != nil {
+			t.Errorf("Failed to encode error response: %v", err)
+		}
+	})
+	defer server.Close()
+
+	ctx := context.Background()
+	response := client.ListDevices(ctx)
+
+	if response.Success {
+		t.Error("Expected error response, got success")
+	}
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, response.StatusCode)
+	}
+	if response.Error != "insufficient scope" {
+		t.Errorf("Expected error message 'insufficient scope', got '%s'", response.Error)
+	}
+}
+
+func TestNewEphemeralAuthKeyRequest(t *testing.T) {
+	req := NewEphemeralAuthKeyRequest("ci-runner", []string{"tag:ci"}, 3600)
+
+	if req.Description != "ci-runner" {
+		t.Errorf("Expected description 'ci-runner', got '%s'", req.Description)
+	}
+	if req.ExpirySeconds != 3600 {
+		t.Errorf("Expected expiry 3600, got %d", req.ExpirySeconds)
+	}
+
+	create := req.Capabilities.Devices.Create
+	if create.Reusable {
+		t.Error("Expected an ephemeral key request not to be reusable")
+	}
+	if !create.Ephemeral {
+		t.Error("Expected Ephemeral to be true")
+	}
+	if !create.Preauthorized {
+		t.Error("Expected Preauthorized to be true")
+	}
+	if len(create.Tags) != 1 || create.Tags[0] != "tag:ci" {
+		t.Errorf("Expected tags [tag:ci], got %v", create.Tags)
+	}
+}
+
 func TestTestConnection(t *testing.T) {
 	client, server := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,215 @@
+// Package audit records a structured trail of every attempted Tailscale CLI
+// invocation, so the validation in TailscaleCLI.ExecuteCommand (allowlist,
+// shell-metacharacter scrubbing, argument length caps) leaves evidence
+// behind instead of a rejected argument simply vanishing into a returned
+// error.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+)
+
+// Decision is the outcome recorded for a single CLI invocation attempt.
+type Decision string
+
+const (
+	Allowed     Decision = "allowed"
+	Denied      Decision = "denied"
+	RateLimited Decision = "rate-limited"
+)
+
+// Record is a single structured audit entry. Args reflects the
+// already-validated argv for Allowed records, and the attempted argv for
+// Denied/RateLimited ones.
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	ClientID    string    `json:"clientId,omitempty"`
+	Command     string    `json:"command"`
+	Args        []string  `json:"args,omitempty"`
+	Decision    Decision  `json:"decision"`
+	Validator   string    `json:"validator,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	ExitCode    int       `json:"exitCode,omitempty"`
+	DurationMS  int64     `json:"durationMs,omitempty"`
+	StdoutBytes int       `json:"stdoutBytes,omitempty"`
+	StderrBytes int       `json:"stderrBytes,omitempty"`
+}
+
+// Sink receives every audit record emitted by a Recorder.
+type Sink interface {
+	Write(Record) error
+}
+
+// Recorder fans a Record out to every configured Sink. A Recorder with no
+// sinks (including a nil *Recorder) is a valid no-op, so audit logging
+// stays opt-in.
+type Recorder struct {
+	sinks []Sink
+}
+
+// NewRecorder creates a Recorder writing to the given sinks, in order.
+func NewRecorder(sinks ...Sink) *Recorder {
+	return &Recorder{sinks: sinks}
+}
+
+// Record timestamps rec (if unset) and writes it to every sink, logging
+// (rather than failing the calling operation on) sink errors.
+func (r *Recorder) Record(rec Record) {
+	if r == nil {
+		return
+	}
+
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+
+	for _, s := range r.sinks {
+		if err := s.Write(rec); err != nil {
+			logger.Error("audit sink write failed", "error", err)
+		}
+	}
+}
+
+// LoggerSink mirrors every record to the existing structured logger: Warn
+// for denied/rate-limited decisions (the ones an operator needs to notice),
+// Debug otherwise.
+type LoggerSink struct{}
+
+func (LoggerSink) Write(r Record) error {
+	fields := []any{"command", r.Command, "args", r.Args, "decision", string(r.Decision)}
+	if r.Validator != "" {
+		fields = append(fields, "validator", r.Validator)
+	}
+	if r.Reason != "" {
+		fields = append(fields, "reason", r.Reason)
+	}
+	if r.DurationMS > 0 {
+		fields = append(fields, "durationMs", r.DurationMS)
+	}
+
+	switch r.Decision {
+	case Denied, RateLimited:
+		logger.Warn("tailscale CLI invocation "+string(r.Decision), fields...)
+	default:
+		logger.Debug("tailscale CLI invocation allowed", fields...)
+	}
+	return nil
+}
+
+// defaultMaxBytes is the size a FileSink rotates at when MaxBytes is left
+// unset.
+const defaultMaxBytes = 10 * 1024 * 1024
+
+// defaultMaxBackups is how many rotated copies a FileSink keeps by default.
+const defaultMaxBackups = 5
+
+// FileSink appends newline-delimited JSON records to a file, rotating it
+// once it exceeds MaxBytes and keeping up to MaxBackups rotated copies
+// (path.1 most recent, path.2 next, ...).
+type FileSink struct {
+	MaxBytes   int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (creating if necessary) a rotating audit log at path.
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to stat audit log %s: %w", path, err)
+	}
+
+	return &FileSink{
+		MaxBytes:   defaultMaxBytes,
+		MaxBackups: defaultMaxBackups,
+		path:       path,
+		file:       f,
+		size:       info.Size(),
+	}, nil
+}
+
+// Write appends rec as a single JSON line, rotating first if needed.
+func (s *FileSink) Write(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maxBytes := s.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	if s.size+int64(len(data)) > maxBytes {
+		if err := s.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+func (s *FileSink) rotateLocked() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log before rotation: %w", err)
+	}
+
+	maxBackups := s.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+
+	for i := maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", s.path, i)
+		dst := fmt.Sprintf("%s.%d", s.path, i+1)
+		if _, err := os.Stat(src); err == nil {
+			_ = os.Rename(src, dst)
+		}
+	}
+	if _, err := os.Stat(s.path); err == nil {
+		if err := os.Rename(s.path, s.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen audit log after rotation: %w", err)
+	}
+	s.file = f
+	s.size = 0
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
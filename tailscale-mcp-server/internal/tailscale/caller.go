@@ -0,0 +1,60 @@
+package tailscale
+
+import (
+	"context"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
+)
+
+type callerIDKey struct{}
+
+// unknownCallerID is used when a request context carries no caller
+// identity, e.g. calls made outside of an MCP tool invocation.
+const unknownCallerID = "unknown"
+
+// UnknownCallerID is unknownCallerID exported for packages outside
+// tailscale (e.g. tools' authorization middleware) that need to tell a
+// resolved identity apart from "no identity was ever set".
+const UnknownCallerID = unknownCallerID
+
+// WithCallerID attaches an MCP caller identity (e.g. an MCP client ID) to
+// ctx, so ExecuteCommand's audit trail can attribute invocations to it.
+func WithCallerID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, callerIDKey{}, id)
+}
+
+// callerIDFromContext returns the caller identity attached via WithCallerID,
+// or unknownCallerID if none was set.
+func callerIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(callerIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return unknownCallerID
+}
+
+// CallerIDFromContext is callerIDFromContext exported for packages outside
+// tailscale that want to attribute their own audit trails (e.g. tools'
+// per-invocation audit middleware) to the same caller identity.
+func CallerIDFromContext(ctx context.Context) string {
+	return callerIDFromContext(ctx)
+}
+
+// logFields returns structured logging fields correlating a log line to the
+// MCP request and HTTP session (if any) ctx was derived from, for
+// ExecuteCommand and APIClient.request to attach to their own log calls.
+// Fields are omitted when unset, so stdio mode (which never carries a
+// session ID, and rarely a caller ID) doesn't pad every log line with empty
+// values.
+func logFields(ctx context.Context) []any {
+	var fields []any
+	if id := audit.RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, "mcp_request_id", id)
+	}
+	if id := audit.SessionIDFromContext(ctx); id != "" {
+		fields = append(fields, "session_id", id)
+	}
+	if id := callerIDFromContext(ctx); id != unknownCallerID {
+		fields = append(fields, "tailscale_caller", id)
+	}
+	return fields
+}
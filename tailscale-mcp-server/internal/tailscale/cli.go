@@ -4,6 +4,7 @@ package tailscale
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -11,10 +12,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/audit"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/ratelimit"
 	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
 )
 
@@ -29,6 +33,10 @@ const (
 	// Ping count limits
 	minPingCount = 1
 	maxPingCount = 100
+
+	// maxShareNameLen is the longest name Tailscale accepts for a published
+	// TailFS/Taildrive share, same as a single DNS label.
+	maxShareNameLen = 63
 )
 
 // Validation patterns
@@ -39,6 +47,10 @@ var (
 
 	// CIDR validation pattern
 	cidrPattern = regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}\/\d{1,2}$|^([0-9a-fA-F:]+)\/\d{1,3}$`)
+
+	// shareNamePattern matches a single DNS label: Tailscale requires share
+	// names to be valid as a path component of the share's DNS-based URL.
+	shareNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
 )
 
 // allowedCommands defines the whitelist of allowed Tailscale CLI commands for security
@@ -64,10 +76,36 @@ var allowedCommands = map[string]bool{
 	"exit-node": true,
 	"set":       true,
 	"unset":     true,
+	"share":     true,
 }
 
+// TailscaleCLI shells out to the `tailscale` binary for every call, paying
+// process-spawn and `status --json` re-parse cost each time. Prefer
+// constructing a Client (see NewClient) over using TailscaleCLI directly
+// when that cost matters: in "auto" (the default) or "localapi" mode it
+// talks to tailscaled's LocalAPI socket instead, which is what backs
+// Client's sub-second Status/WhoIs/Watch. TailscaleCLI itself stays
+// CLI-only rather than growing its own LocalAPI fallback, so there's a
+// single place (localapiBackend) that owns the socket-dialing and
+// IPN-bus-watching logic instead of two.
 type TailscaleCLI struct {
 	tailscalePath string
+
+	audit   *audit.Recorder
+	limiter *ratelimit.Limiter
+
+	// defaultLoginServer, set via SetDefaultLoginServer, is the
+	// --login-server Up falls back to when its options don't specify one.
+	// Populated from an active Headscale config.ControlProfile (see
+	// server.New) so tools calling Up don't need to know which control
+	// server is active.
+	defaultLoginServer string
+}
+
+// SetDefaultLoginServer sets the --login-server Up falls back to when its
+// options don't specify one.
+func (c *TailscaleCLI) SetDefaultLoginServer(loginServer string) {
+	c.defaultLoginServer = loginServer
 }
 
 // CLIError represents an error that occurred during CLI execution
@@ -81,6 +119,9 @@ type CLIError struct {
 
 func (e *CLIError) Error() string {
 	if e.Underlying != nil {
+		if e.Stderr != "" {
+			return fmt.Sprintf("tailscale %s failed: %v: %s", e.Command, e.Underlying, e.Stderr)
+		}
 		return fmt.Sprintf("tailscale %s failed: %v", e.Command, e.Underlying)
 	}
 	return fmt.Sprintf("tailscale %s failed with exit code %d: %s", e.Command, e.ExitCode, e.Stderr)
@@ -140,7 +181,29 @@ func NewTailscaleCLI() (*TailscaleCLI, error) {
 		}
 	}
 
-	return &TailscaleCLI{tailscalePath: path}, nil
+	return &TailscaleCLI{
+		tailscalePath: path,
+		audit:         newAuditRecorder(),
+		limiter:       ratelimit.NewLimiter(ratelimit.LoadRatesFromEnv()),
+	}, nil
+}
+
+// newAuditRecorder builds the audit.Recorder every TailscaleCLI uses: it
+// always mirrors to the structured logger, and additionally appends to a
+// rotating file when TS_MCP_AUDIT_LOG_FILE is set.
+func newAuditRecorder() *audit.Recorder {
+	sinks := []audit.Sink{audit.LoggerSink{}}
+
+	if path := strings.TrimSpace(os.Getenv("TS_MCP_AUDIT_LOG_FILE")); path != "" {
+		sink, err := audit.NewFileSink(path)
+		if err != nil {
+			logger.Error("failed to open audit log file, continuing without it", "path", path, "error", err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+
+	return audit.NewRecorder(sinks...)
 }
 
 // ExecuteCommand runs the Tailscale CLI with validation, timeout, buffer limit
@@ -150,37 +213,85 @@ func (c *TailscaleCLI) ExecuteCommand(
 	args []string,
 	env []string,
 ) (string, error) {
+	return c.executeCommand(ctx, args, env, nil)
+}
+
+// ExecuteCommandStreaming behaves exactly like ExecuteCommand, except onLine
+// is invoked once per complete line of stdout as the command produces it,
+// rather than only once the command has finished. It's for callers that
+// stream progress off of a long-running command's output, e.g. reporting
+// each parsed `tailscale ping` reply as a notifications/progress message.
+func (c *TailscaleCLI) ExecuteCommandStreaming(
+	ctx context.Context,
+	args []string,
+	env []string,
+	onLine func(line string),
+) (string, error) {
+	return c.executeCommand(ctx, args, env, onLine)
+}
+
+func (c *TailscaleCLI) executeCommand(
+	ctx context.Context,
+	args []string,
+	env []string,
+	onLine func(line string),
+) (string, error) {
+	clientID := callerIDFromContext(ctx)
+
 	// --- command validation --------------------------------------------------
 	if len(args) == 0 {
+		c.audit.Record(audit.Record{ClientID: clientID, Decision: audit.Denied, Validator: "allowlist", Reason: "no command specified"})
 		return "", errors.New("no command specified")
 	}
 
 	// Validate the command is in our whitelist
 	command := args[0]
 	if !allowedCommands[command] {
-		return "", fmt.Errorf("command %q not allowed", command)
+		reason := fmt.Sprintf("command %q not allowed", command)
+		c.audit.Record(audit.Record{ClientID: clientID, Command: command, Args: args, Decision: audit.Denied, Validator: "allowlist", Reason: reason})
+		return "", errors.New(reason)
+	}
+
+	// --- rate limiting ---------------------------------------------------------
+	if !c.limiter.Allow(clientID, command) {
+		reason := fmt.Sprintf("rate limit exceeded for command %q", command)
+		c.audit.Record(audit.Record{ClientID: clientID, Command: command, Args: args, Decision: audit.RateLimited, Reason: reason})
+		return "", errors.New(reason)
 	}
 
 	// --- argument validation -------------------------------------------------
 	for i, a := range args {
 		if len(a) > maxArgLen {
-			return "", fmt.Errorf("argument %d too long (%d chars)", i, len(a))
+			reason := fmt.Sprintf("argument %d too long (%d chars)", i, len(a))
+			c.audit.Record(audit.Record{ClientID: clientID, Command: command, Args: args, Decision: audit.Denied, Validator: "length", Reason: reason})
+			return "", errors.New(reason)
 		}
 
 		// Basic injection prevention - reject arguments with suspicious characters
 		if strings.ContainsAny(a, ";&|`$(){}[]<>") {
-			return "", fmt.Errorf("argument %d contains invalid characters: %q", i, a)
+			reason := fmt.Sprintf("argument %d contains invalid characters: %q", i, a)
+			c.audit.Record(audit.Record{ClientID: clientID, Command: command, Args: args, Decision: audit.Denied, Validator: "metachar", Reason: reason})
+			return "", errors.New(reason)
 		}
 	}
 
-	logger.Debug("Executing tailscale command", "path", c.tailscalePath, "args", args)
+	logger.Debug("Executing tailscale command", append([]any{"path", c.tailscalePath, "args", args}, logFields(ctx)...)...)
 
 	// --- build exec.Command --------------------------------------------------
+	start := time.Now()
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(execCtx, c.tailscalePath, args...)
-	setWinAttrs(cmd) // hides console on Windows, no-op elsewhere
+	setProcAttrs(cmd) // own process group (Unix) / process group + hidden console (Windows)
+
+	// Replace exec.CommandContext's default cancellation (which only kills
+	// cmd.Process itself) with killTree, so a command that spawns helpers
+	// (or a hung `tailscale ping`) doesn't outlive execCtx's deadline or a
+	// forced shutdown cancellation. WaitDelay is the backstop if killTree's
+	// signal doesn't land before the pipes need closing.
+	cmd.Cancel = func() error { return killTree(cmd) }
+	cmd.WaitDelay = 5 * time.Second
 
 	// Apply additional environment variables
 	if len(env) > 0 {
@@ -193,14 +304,18 @@ func (c *TailscaleCLI) ExecuteCommand(
 		errBuf bytes.Buffer
 	)
 	cmd.Stdout = newLimitWriter(&outBuf, maxBufSize)
+	if onLine != nil {
+		cmd.Stdout = newLineWriter(cmd.Stdout, onLine)
+	}
 	cmd.Stderr = newLimitWriter(&errBuf, maxBufSize)
 
 	// --- execute -------------------------------------------------------------
 	err := cmd.Run()
+	duration := time.Since(start)
 
 	stderrStr := strings.TrimSpace(errBuf.String())
 	if stderrStr != "" {
-		logger.Warn("CLI stderr", "stderr", stderrStr)
+		logger.Warn("CLI stderr", append([]any{"stderr", stderrStr}, logFields(ctx)...)...)
 	}
 
 	// --- handle errors -------------------------------------------------------
@@ -222,10 +337,33 @@ func (c *TailscaleCLI) ExecuteCommand(
 			cliErr.ExitCode = exitErr.ExitCode()
 		}
 
-		logger.Error("CLI command failed", "command", command, "args", args, "error", err)
+		c.audit.Record(audit.Record{
+			ClientID:    clientID,
+			Command:     command,
+			Args:        args,
+			Decision:    audit.Allowed,
+			ExitCode:    cliErr.ExitCode,
+			DurationMS:  duration.Milliseconds(),
+			StdoutBytes: outBuf.Len(),
+			StderrBytes: errBuf.Len(),
+		})
+
+		logger.Error("CLI command failed", append([]any{"command", command, "args", args, "error", err}, logFields(ctx)...)...)
+		observeRequest("cli", command, start, false)
 		return "", cliErr
 	}
 
+	c.audit.Record(audit.Record{
+		ClientID:    clientID,
+		Command:     command,
+		Args:        args,
+		Decision:    audit.Allowed,
+		DurationMS:  duration.Milliseconds(),
+		StdoutBytes: outBuf.Len(),
+		StderrBytes: errBuf.Len(),
+	})
+
+	observeRequest("cli", command, start, true)
 	return strings.TrimSpace(outBuf.String()), nil
 }
 
@@ -270,14 +408,18 @@ func (c *TailscaleCLI) Up(options *UpOptions) error {
 	args := []string{"up"}
 	env := []string{}
 
-	if options != nil {
-		if options.LoginServer != "" {
-			if err := c.validateStringInput(options.LoginServer, "loginServer"); err != nil {
-				return fmt.Errorf("invalid login server: %w", err)
-			}
-			args = append(args, "--login-server", options.LoginServer)
+	loginServer := c.defaultLoginServer
+	if options != nil && options.LoginServer != "" {
+		loginServer = options.LoginServer
+	}
+	if loginServer != "" {
+		if err := c.validateStringInput(loginServer, "loginServer"); err != nil {
+			return fmt.Errorf("invalid login server: %w", err)
 		}
+		args = append(args, "--login-server", loginServer)
+	}
 
+	if options != nil {
 		if options.AcceptRoutes {
 			args = append(args, "--accept-routes")
 		}
@@ -295,6 +437,13 @@ func (c *TailscaleCLI) Up(options *UpOptions) error {
 
 		if len(options.AdvertiseRoutes) > 0 {
 			if err := c.validateRoutes(options.AdvertiseRoutes); err != nil {
+				c.audit.Record(audit.Record{
+					Command:   "up",
+					Args:      options.AdvertiseRoutes,
+					Decision:  audit.Denied,
+					Validator: "route-format",
+					Reason:    err.Error(),
+				})
 				return fmt.Errorf("invalid routes: %w", err)
 			}
 			args = append(args, "--advertise-routes", strings.Join(options.AdvertiseRoutes, ","))
@@ -344,6 +493,22 @@ func (c *TailscaleCLI) Ping(target string, count int) (string, error) {
 	return c.ExecuteCommand(context.Background(), cmdArgs, nil)
 }
 
+// PingStreaming behaves exactly like Ping, except onLine is invoked once per
+// complete line of `tailscale ping` output as it's produced, so a caller can
+// report progress per reply instead of only once the command finishes.
+func (c *TailscaleCLI) PingStreaming(ctx context.Context, target string, count int, onLine func(line string)) (string, error) {
+	if err := c.validateTarget(target); err != nil {
+		return "", fmt.Errorf("invalid target: %w", err)
+	}
+
+	if count < minPingCount || count > maxPingCount {
+		return "", fmt.Errorf("count must be an integer between %d and %d", minPingCount, maxPingCount)
+	}
+
+	cmdArgs := []string{"ping", target, "-c", fmt.Sprintf("%d", count)}
+	return c.ExecuteCommandStreaming(ctx, cmdArgs, nil, onLine)
+}
+
 // IP gets the Tailscale IP addresses
 func (c *TailscaleCLI) IP() (string, error) {
 	return c.ExecuteCommand(context.Background(), []string{"ip"}, nil)
@@ -354,6 +519,14 @@ func (c *TailscaleCLI) Netcheck() (string, error) {
 	return c.ExecuteCommand(context.Background(), []string{"netcheck"}, nil)
 }
 
+// NetcheckStreaming behaves exactly like Netcheck, except onLine is invoked
+// once per complete line of output as `tailscale netcheck` produces it, so a
+// caller can report progress samples (e.g. as DERP latencies are probed)
+// instead of only once the command finishes.
+func (c *TailscaleCLI) NetcheckStreaming(ctx context.Context, onLine func(line string)) (string, error) {
+	return c.ExecuteCommandStreaming(ctx, []string{"netcheck"}, nil, onLine)
+}
+
 // SetExitNode sets or clears the exit node
 func (c *TailscaleCLI) SetExitNode(nodeID string) error {
 	args := []string{"set"}
@@ -477,3 +650,200 @@ func (c *TailscaleCLI) ListPeers() ([]string, error) {
 
 	return peers, nil
 }
+
+// shareRootsEnv lists the colon-separated (semicolon on Windows, per
+// os.PathListSeparator) allow-list of root directories file/share
+// operations may touch. Unset means no restriction: validateSharePath then
+// only rejects an empty path, and its symlink-escape check, which compares
+// the resolved path against these roots, does not apply.
+const shareRootsEnv = "TAILSCALE_SHARE_ROOTS"
+
+// validateSharePath rejects a file/share path that, once symlinks are
+// resolved, falls outside every root named in TAILSCALE_SHARE_ROOTS (when
+// set) — guarding against a share/file operation being pointed at, say,
+// /etc/shadow via a symlink planted inside an otherwise-allowed directory.
+func (c *TailscaleCLI) validateSharePath(path string) error {
+	if path == "" {
+		return errors.New("path cannot be empty")
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		// The path may not exist yet (e.g. a ReceiveFiles destination
+		// directory created on first use); fall back to a lexical
+		// resolution so the allow-list check below still has something to
+		// compare against.
+		abs, absErr := filepath.Abs(path)
+		if absErr != nil {
+			return fmt.Errorf("resolving path: %w", err)
+		}
+		resolved = abs
+	}
+
+	var allowedRoots []string
+	for _, root := range strings.Split(os.Getenv(shareRootsEnv), string(os.PathListSeparator)) {
+		if root = strings.TrimSpace(root); root != "" {
+			allowedRoots = append(allowedRoots, root)
+		}
+	}
+	if len(allowedRoots) == 0 {
+		return nil
+	}
+
+	for _, root := range allowedRoots {
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(rootAbs, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("path %q is outside the allowed share roots (%s)", path, shareRootsEnv)
+}
+
+// validateShareName rejects a TailFS/Taildrive share name that isn't a
+// valid DNS label, the form Tailscale requires since a share is addressed
+// as a path component of the node's DNS-based share URL.
+func validateShareName(name string) error {
+	if name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if len(name) > maxShareNameLen {
+		return fmt.Errorf("name too long (max %d chars)", maxShareNameLen)
+	}
+	if !shareNamePattern.MatchString(name) {
+		return errors.New("name must be a valid DNS label (letters, digits, and hyphens, not starting or ending with a hyphen)")
+	}
+	return nil
+}
+
+// ReceivedFile describes a single file `tailscale file get` delivered.
+type ReceivedFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// receivedFilePattern matches a "<name>, <size> bytes" line from `tailscale
+// file get`'s output.
+var receivedFilePattern = regexp.MustCompile(`^(.+), (\d+) bytes$`)
+
+// SendFile sends the file at path to target over Taildrop (`tailscale file
+// cp`), the counterpart to ReceiveFiles on the receiving node.
+func (c *TailscaleCLI) SendFile(ctx context.Context, target, path string) error {
+	if err := c.validateTarget(target); err != nil {
+		return fmt.Errorf("invalid target: %w", err)
+	}
+	if err := c.validateSharePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	_, err := c.ExecuteCommand(ctx, []string{"file", "cp", path, target + ":"}, nil)
+	return err
+}
+
+// ReceiveFiles accepts any files Taildrop is holding for this node into dir
+// (`tailscale file get`).
+func (c *TailscaleCLI) ReceiveFiles(ctx context.Context, dir string) ([]ReceivedFile, error) {
+	if err := c.validateSharePath(dir); err != nil {
+		return nil, fmt.Errorf("invalid directory: %w", err)
+	}
+
+	output, err := c.ExecuteCommand(ctx, []string{"file", "get", dir}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive files: %w", err)
+	}
+
+	var files []ReceivedFile
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		m := receivedFilePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		size, err := strconv.ParseInt(m[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, ReceivedFile{Name: m[1], Size: size})
+	}
+	return files, nil
+}
+
+// Share describes a single Taildrop/TailFS file share this node is serving.
+type Share struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+// ShareAdd publishes path as a TailFS share named name (`tailscale share
+// add`), subject to validateSharePath's symlink and allow-list checks.
+func (c *TailscaleCLI) ShareAdd(ctx context.Context, name, path string, readOnly bool) error {
+	if err := validateShareName(name); err != nil {
+		return fmt.Errorf("invalid share name: %w", err)
+	}
+	if err := c.validateSharePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	args := []string{"share", "add", name, path}
+	if readOnly {
+		args = append(args, "--read-only")
+	}
+
+	_, err := c.ExecuteCommand(ctx, args, nil)
+	return err
+}
+
+// ShareRemove un-publishes a share (`tailscale share remove`).
+func (c *TailscaleCLI) ShareRemove(ctx context.Context, name string) error {
+	if err := validateShareName(name); err != nil {
+		return fmt.Errorf("invalid share name: %w", err)
+	}
+
+	_, err := c.ExecuteCommand(ctx, []string{"share", "remove", name}, nil)
+	return err
+}
+
+// ShareRename renames a published share (`tailscale share rename`).
+func (c *TailscaleCLI) ShareRename(ctx context.Context, oldName, newName string) error {
+	if err := validateShareName(oldName); err != nil {
+		return fmt.Errorf("invalid share name: %w", err)
+	}
+	if err := validateShareName(newName); err != nil {
+		return fmt.Errorf("invalid new share name: %w", err)
+	}
+
+	_, err := c.ExecuteCommand(ctx, []string{"share", "rename", oldName, newName}, nil)
+	return err
+}
+
+// SetFileServerAddr points this node's TailFS/Taildrive file server at a
+// non-default address (`tailscale share set-file-server-addr`), the knob a
+// macOS sandboxed build needs since it can't bind the default address
+// directly.
+func (c *TailscaleCLI) SetFileServerAddr(ctx context.Context, addr string) error {
+	if err := c.validateStringInput(addr, "addr"); err != nil {
+		return fmt.Errorf("invalid file server address: %w", err)
+	}
+
+	_, err := c.ExecuteCommand(ctx, []string{"share", "set-file-server-addr", addr}, nil)
+	return err
+}
+
+// ShareList lists this node's published TailFS shares (`tailscale share
+// list --json`).
+func (c *TailscaleCLI) ShareList(ctx context.Context) ([]Share, error) {
+	output, err := c.ExecuteCommand(ctx, []string{"share", "list", "--json"}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shares: %w", err)
+	}
+
+	var shares []Share
+	if err := json.Unmarshal([]byte(output), &shares); err != nil {
+		return nil, fmt.Errorf("failed to parse share list: %w", err)
+	}
+	return shares, nil
+}
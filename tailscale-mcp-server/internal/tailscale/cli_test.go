@@ -1,6 +1,7 @@
 package tailscale
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/testfake"
 )
 
 // useStubBinary writes a tiny stub "tailscale" binary that always exits 1 quickly.
@@ -94,7 +96,7 @@ func TestCommandWhitelist(t *testing.T) {
 		"status", "up", "down", "logout", "switch", "configure",
 		"netcheck", "ip", "ping", "ssh", "version", "update",
 		"web", "file", "bugreport", "cert", "lock", "licenses",
-		"exit-node", "set", "unset",
+		"exit-node", "set", "unset", "share",
 	}
 
 	for _, cmd := range expectedCommands {
@@ -456,3 +458,212 @@ func TestNewTailscaleCLI_FallbackPaths(t *testing.T) {
 		assert.True(t, found, "CLI should use one of the fallback paths: %v, got: %s", fallbackPaths, cli.tailscalePath)
 	}
 }
+
+// setupFakeCliTest installs a programmable fake tailscale binary configured
+// with opts and returns both the CLI under test and a handle for asserting
+// against recorded invocations.
+func setupFakeCliTest(t *testing.T, opts testfake.Options) (*TailscaleCLI, *testfake.Handle) {
+	t.Helper()
+
+	handle := testfake.UseFakeTailscale(t, opts)
+
+	require.NoError(t, logger.Initialize(0, ""))
+
+	cli, err := NewTailscaleCLI()
+	require.NoError(t, err)
+
+	return cli, handle
+}
+
+func TestGetVersionWithFake(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{State: testfake.State{Version: "1.42.0"}})
+
+	version, err := cli.GetVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.42.0", version)
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 1)
+	assert.Equal(t, []string{"version"}, invocations[0].Argv)
+}
+
+func TestIPWithFake(t *testing.T) {
+	cli, _ := setupFakeCliTest(t, testfake.Options{
+		State: testfake.State{Self: &testfake.Peer{TailscaleIPs: []string{"100.64.0.5", "fd7a:115c::1"}}},
+	})
+
+	out, err := cli.IP()
+	require.NoError(t, err)
+	assert.Contains(t, out, "100.64.0.5")
+	assert.Contains(t, out, "fd7a:115c::1")
+}
+
+func TestNetcheckWithFake(t *testing.T) {
+	cli, _ := setupFakeCliTest(t, testfake.Options{})
+
+	out, err := cli.Netcheck()
+	require.NoError(t, err)
+	assert.Contains(t, out, "Report:")
+}
+
+func TestPingWithFake(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{})
+
+	out, err := cli.Ping("100.64.0.1", 3)
+	require.NoError(t, err)
+	assert.Contains(t, out, "pong from 100.64.0.1")
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 1)
+	assert.Equal(t, []string{"ping", "100.64.0.1", "-c", "3"}, invocations[0].Argv)
+}
+
+func TestUpDownWithFake(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{})
+
+	require.NoError(t, cli.Up(&UpOptions{AuthKey: "tskey-dummy-authkey", AcceptRoutes: true}))
+	require.NoError(t, cli.Down())
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 2)
+	// Auth keys must never appear on argv; they're passed via TS_AUTHKEY.
+	assert.Equal(t, []string{"up", "--accept-routes"}, invocations[0].Argv)
+	assert.Contains(t, invocations[0].Env, "TS_AUTHKEY=tskey-dummy-authkey")
+	assert.Equal(t, []string{"down"}, invocations[1].Argv)
+}
+
+func TestUpWithSimulatedFailure(t *testing.T) {
+	cli, _ := setupFakeCliTest(t, testfake.Options{
+		Errors: map[string]string{"up": "tailscale up: not logged in"},
+	})
+
+	err := cli.Up(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not logged in")
+}
+
+func TestGetStatusWithFake(t *testing.T) {
+	cli, _ := setupFakeCliTest(t, testfake.Options{
+		State: testfake.State{
+			Up:             true,
+			Version:        "1.42.0",
+			MagicDNSSuffix: "tail1234.ts.net",
+			Self: &testfake.Peer{
+				HostName:     "laptop",
+				TailscaleIPs: []string{"100.64.0.1"},
+				Online:       true,
+			},
+			Peers: []*testfake.Peer{
+				{HostName: "server", TailscaleIPs: []string{"100.64.0.2"}, Online: true},
+			},
+		},
+	})
+
+	status, err := cli.GetStatus()
+	require.NoError(t, err)
+	assert.Equal(t, "1.42.0", status.Version)
+	assert.Equal(t, "Running", status.BackendState)
+	assert.Equal(t, "laptop", status.Self.HostName)
+	require.NotNil(t, status.CurrentTailnet)
+	assert.Equal(t, "tail1234.ts.net", status.CurrentTailnet.MagicDNSSuffix)
+	assert.Len(t, status.Peer, 1)
+}
+
+func TestExecuteCommandRecordsEnv(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{})
+
+	_, err := cli.ExecuteCommand(context.Background(), []string{"version"}, []string{"TS_AUTHKEY=tskey-dummy"})
+	require.NoError(t, err)
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 1)
+	assert.Contains(t, invocations[0].Env, "TS_AUTHKEY=tskey-dummy")
+}
+
+func TestValidateSharePath(t *testing.T) {
+	cli := setupCliTest(t)
+
+	t.Run("empty path is rejected", func(t *testing.T) {
+		assert.Error(t, cli.validateSharePath(""))
+	})
+
+	t.Run("no TAILSCALE_SHARE_ROOTS means no restriction", func(t *testing.T) {
+		t.Setenv("TAILSCALE_SHARE_ROOTS", "")
+		assert.NoError(t, cli.validateSharePath(t.TempDir()))
+	})
+
+	t.Run("path inside an allowed root is accepted", func(t *testing.T) {
+		root := t.TempDir()
+		sub := filepath.Join(root, "shared")
+		require.NoError(t, os.MkdirAll(sub, 0o755))
+
+		t.Setenv("TAILSCALE_SHARE_ROOTS", root)
+		assert.NoError(t, cli.validateSharePath(sub))
+	})
+
+	t.Run("path outside every allowed root is rejected", func(t *testing.T) {
+		root := t.TempDir()
+		outside := t.TempDir()
+
+		t.Setenv("TAILSCALE_SHARE_ROOTS", root)
+		assert.Error(t, cli.validateSharePath(outside))
+	})
+
+	t.Run("symlink escaping an allowed root is rejected", func(t *testing.T) {
+		root := t.TempDir()
+		outside := t.TempDir()
+		link := filepath.Join(root, "escape")
+		require.NoError(t, os.Symlink(outside, link))
+
+		t.Setenv("TAILSCALE_SHARE_ROOTS", root)
+		assert.Error(t, cli.validateSharePath(link))
+	})
+}
+
+func TestSendFileWithFake(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{})
+	t.Setenv("TAILSCALE_SHARE_ROOTS", "")
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+
+	require.NoError(t, cli.SendFile(context.Background(), "server", path))
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 1)
+	assert.Equal(t, []string{"file", "cp", path, "server:"}, invocations[0].Argv)
+}
+
+func TestReceiveFilesWithFake(t *testing.T) {
+	cli, _ := setupFakeCliTest(t, testfake.Options{
+		State: testfake.State{
+			ReceivedFiles: []testfake.ReceivedFile{
+				{Name: "report.pdf", Size: 1024},
+				{Name: "photo.jpg", Size: 2048},
+			},
+		},
+	})
+	t.Setenv("TAILSCALE_SHARE_ROOTS", "")
+
+	files, err := cli.ReceiveFiles(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, ReceivedFile{Name: "report.pdf", Size: 1024}, files[0])
+	assert.Equal(t, ReceivedFile{Name: "photo.jpg", Size: 2048}, files[1])
+}
+
+func TestShareAddRemoveRenameWithFake(t *testing.T) {
+	cli, handle := setupFakeCliTest(t, testfake.Options{})
+	t.Setenv("TAILSCALE_SHARE_ROOTS", "")
+
+	dir := t.TempDir()
+	require.NoError(t, cli.ShareAdd(context.Background(), "docs", dir, true))
+	require.NoError(t, cli.ShareRename(context.Background(), "docs", "documents"))
+	require.NoError(t, cli.ShareRemove(context.Background(), "documents"))
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 3)
+	assert.Equal(t, []string{"share", "add", "docs", dir, "--read-only"}, invocations[0].Argv)
+	assert.Equal(t, []string{"share", "rename", "docs", "documents"}, invocations[1].Argv)
+	assert.Equal(t, []string{"share", "remove", "documents"}, invocations[2].Argv)
+}
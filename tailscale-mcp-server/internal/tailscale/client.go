@@ -0,0 +1,223 @@
+// tailscale-mcp-server/internal/tailscale/client.go
+package tailscale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
+)
+
+// Client abstracts talking to a local Tailscale node, independent of whether
+// the underlying transport is the `tailscale` CLI (cliBackend) or
+// tailscaled's LocalAPI socket (localapiBackend). Prefer this over
+// TailscaleCLI directly when a tool doesn't specifically need CLI-only
+// behavior, since the LocalAPI backend avoids re-implementing the CLI's own
+// argument validation and exposes richer structured data.
+type Client interface {
+	// Status returns the current Tailscale node status.
+	Status(ctx context.Context) (*schema.TailscaleStatus, error)
+
+	// WhoIs resolves a Tailscale IP or hostname to node/user identity.
+	// Only the LocalAPI backend supports this; the CLI backend returns an
+	// error since `tailscale` has no equivalent subcommand.
+	WhoIs(ctx context.Context, addr string) (*WhoIsResponse, error)
+
+	// Ping pings a peer and returns the raw result text.
+	Ping(ctx context.Context, target string, count int) (string, error)
+
+	// Netcheck runs a network connectivity report and returns the raw
+	// result text.
+	Netcheck(ctx context.Context) (string, error)
+
+	// Up brings the Tailscale node up with the given options.
+	Up(ctx context.Context, options *UpOptions) error
+
+	// Logout logs the node out of its tailnet.
+	Logout(ctx context.Context) error
+
+	// LoginInteractive starts an interactive (OIDC or browser) login and
+	// returns once the resulting AuthURL is available, without waiting for
+	// the human on the other end to complete it. Call PollLogin with the
+	// returned session to find out when they have.
+	LoginInteractive(ctx context.Context, options *LoginOptions) (*LoginSession, error)
+
+	// PollLogin blocks until session's login completes (BackendState
+	// reaches Running) or ctx is done, whichever comes first.
+	PollLogin(ctx context.Context, session *LoginSession) (LoginState, error)
+
+	// SetExitNode sets the node to use as an exit node, or clears it when
+	// nodeID is empty.
+	SetExitNode(ctx context.Context, nodeID string) error
+
+	// SetShieldsUp enables or disables shields-up mode (reject all incoming
+	// connections from other tailnet peers).
+	SetShieldsUp(ctx context.Context, enabled bool) error
+
+	// ListPeers returns the hostnames of the node's current peers.
+	ListPeers(ctx context.Context) ([]string, error)
+
+	// Subscribe streams status changes as they happen. Only the LocalAPI
+	// backend supports this, since it's backed by tailscaled's IPN-bus
+	// watcher; the CLI backend returns an error since polling `tailscale
+	// status` repeatedly has no equivalent push notification. The returned
+	// channel is closed when ctx is done or the underlying watch ends.
+	Subscribe(ctx context.Context) (<-chan StatusChange, error)
+
+	// Watch streams typed Events derived from successive status snapshots:
+	// peers appearing/disappearing/going on- or offline, the netmap as a
+	// whole changing, and the active exit node changing. Unlike Subscribe,
+	// it's supported on both backends (the CLI backend falls back to
+	// polling `status --json` every few seconds and diffing), and as a side
+	// effect it starts caching the latest status so Status/ListPeers become
+	// O(1) reads for as long as at least one Watch stream is live. The
+	// returned channel is closed when ctx is done or the underlying watch
+	// ends.
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// StatusChange is a single notification off a Subscribe stream: either a
+// fresh node status, or an error that ended the stream.
+type StatusChange struct {
+	Status *schema.TailscaleStatus
+	Err    error
+}
+
+// WhoIsResponse is a reduced view of LocalAPI's whois response, covering the
+// fields tools need today.
+type WhoIsResponse struct {
+	NodeName  string `json:"NodeName"`
+	NodeIP    string `json:"NodeIP"`
+	UserLogin string `json:"UserLogin"`
+}
+
+// localAPIReachabilityTimeout bounds how long "auto" backend selection waits
+// for a dial attempt against the LocalAPI socket before falling back to CLI.
+const localAPIReachabilityTimeout = 500 * time.Millisecond
+
+// NewClient constructs a Client for the given backend mode: "cli",
+// "localapi", or "auto" (the default). In "auto" mode, LocalAPI is preferred
+// when its socket is reachable, falling back to the CLI backend otherwise;
+// an operator who set TAILSCALE_PATH to point at a specific binary has
+// already said which `tailscale` they want run, so auto mode honors that
+// and skips straight to the CLI backend without probing the socket.
+// watchDebounce is the window Watch uses to coalesce rapid per-peer flaps
+// (see debounceEvents); zero uses defaultWatchDebounce.
+func NewClient(mode string, cli *TailscaleCLI, watchDebounce time.Duration) (Client, error) {
+	switch mode {
+	case "cli":
+		return newCLIBackend(cli, watchDebounce), nil
+	case "localapi":
+		return newLocalAPIBackend(defaultLocalAPISocketPath(), watchDebounce), nil
+	case "auto", "":
+		if path := os.Getenv("TAILSCALE_PATH"); path != "" {
+			logger.Debug("TAILSCALE_PATH set, using cli backend", "path", path)
+			return newCLIBackend(cli, watchDebounce), nil
+		}
+
+		socketPath := defaultLocalAPISocketPath()
+		if isLocalAPIReachable(socketPath) {
+			logger.Debug("LocalAPI socket reachable, using localapi backend", "socket", socketPath)
+			return newLocalAPIBackend(socketPath, watchDebounce), nil
+		}
+		logger.Debug("LocalAPI socket unreachable, falling back to cli backend", "socket", socketPath)
+		return newCLIBackend(cli, watchDebounce), nil
+	default:
+		return nil, fmt.Errorf("unknown tailscale backend %q: must be one of cli, localapi, auto", mode)
+	}
+}
+
+// cliBackend implements Client on top of the existing TailscaleCLI, which
+// shells out to the `tailscale` binary.
+type cliBackend struct {
+	cli           *TailscaleCLI
+	cache         statusCache
+	watchDebounce time.Duration
+}
+
+func newCLIBackend(cli *TailscaleCLI, watchDebounce time.Duration) *cliBackend {
+	return &cliBackend{cli: cli, watchDebounce: watchDebounce}
+}
+
+func (b *cliBackend) Status(ctx context.Context) (*schema.TailscaleStatus, error) {
+	if cached := b.cache.get(); cached != nil {
+		return cached, nil
+	}
+	return b.cli.GetStatus()
+}
+
+func (b *cliBackend) WhoIs(ctx context.Context, addr string) (*WhoIsResponse, error) {
+	return nil, fmt.Errorf("whois is not available via the cli backend; use --backend=localapi")
+}
+
+func (b *cliBackend) Ping(ctx context.Context, target string, count int) (string, error) {
+	return b.cli.Ping(target, count)
+}
+
+func (b *cliBackend) Netcheck(ctx context.Context) (string, error) {
+	return b.cli.Netcheck()
+}
+
+func (b *cliBackend) Up(ctx context.Context, options *UpOptions) error {
+	return b.cli.Up(options)
+}
+
+func (b *cliBackend) Logout(ctx context.Context) error {
+	return b.cli.Logout()
+}
+
+func (b *cliBackend) LoginInteractive(ctx context.Context, options *LoginOptions) (*LoginSession, error) {
+	return b.cli.LoginInteractive(ctx, options)
+}
+
+func (b *cliBackend) PollLogin(ctx context.Context, session *LoginSession) (LoginState, error) {
+	return b.cli.PollLogin(ctx, session)
+}
+
+func (b *cliBackend) SetExitNode(ctx context.Context, nodeID string) error {
+	return b.cli.SetExitNode(nodeID)
+}
+
+func (b *cliBackend) SetShieldsUp(ctx context.Context, enabled bool) error {
+	return b.cli.SetShieldsUp(enabled)
+}
+
+func (b *cliBackend) ListPeers(ctx context.Context) ([]string, error) {
+	status, err := b.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []string
+	for _, peer := range status.Peer {
+		if peer.HostName != "" {
+			peers = append(peers, peer.HostName)
+		}
+	}
+	return peers, nil
+}
+
+func (b *cliBackend) Subscribe(ctx context.Context) (<-chan StatusChange, error) {
+	return nil, fmt.Errorf("subscribing to status changes is not available via the cli backend; use --backend=localapi")
+}
+
+// Watch falls back to polling `status --json` every watchPollInterval and
+// diffing, since the CLI has no equivalent of tailscaled's IPN-bus push
+// notifications (and no long-running `--watch` mode this package can
+// safely exec, given TailscaleCLI bounds every command to a fixed timeout).
+// The raw diff stream is debounced (see debounceEvents) before it reaches
+// the caller.
+func (b *cliBackend) Watch(ctx context.Context) (<-chan Event, error) {
+	raw, err := watchWithPoller(ctx, &b.cache, func() (*schema.TailscaleStatus, error) {
+		return b.cli.GetStatus()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return debounceEvents(ctx, raw, b.watchDebounce), nil
+}
+
+var _ Client = (*cliBackend)(nil)
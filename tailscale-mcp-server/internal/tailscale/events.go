@@ -0,0 +1,371 @@
+// tailscale-mcp-server/internal/tailscale/events.go
+package tailscale
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
+)
+
+// EventKind identifies the shape of an Event's payload. Watch emits these
+// instead of raw status snapshots so callers can react to just the change
+// they care about (e.g. a peer going offline) without re-diffing status
+// themselves.
+type EventKind string
+
+const (
+	EventPeerAdded               EventKind = "PeerAdded"
+	EventPeerRemoved             EventKind = "PeerRemoved"
+	EventPeerOnlineChanged       EventKind = "PeerOnlineChanged"
+	EventNetMapUpdated           EventKind = "NetMapUpdated"
+	EventPrefsChanged            EventKind = "PrefsChanged"
+	EventBrowseToURL             EventKind = "BrowseToURL"
+	EventExitNodeChanged         EventKind = "ExitNodeChanged"
+	EventSelfBackendStateChanged EventKind = "SelfBackendStateChanged"
+	EventHealthChanged           EventKind = "HealthChanged"
+)
+
+// Event is a single notification off a Watch stream.
+type Event struct {
+	Kind EventKind
+
+	// PeerID/PeerHostName/Online are set for EventPeerAdded,
+	// EventPeerRemoved, and EventPeerOnlineChanged.
+	PeerID       string
+	PeerHostName string
+	Online       bool
+
+	// ExitNodeID is set for EventExitNodeChanged: the new exit node's peer
+	// ID, or empty if the exit node was cleared.
+	ExitNodeID string
+
+	// BrowseToURL is set for EventBrowseToURL, same as LoginSession.AuthURL.
+	BrowseToURL string
+
+	// BackendState is set for EventSelfBackendStateChanged: the node's new
+	// BackendState (e.g. "Running", "NeedsLogin").
+	BackendState string
+
+	// Health is set for EventHealthChanged: the node's new Health warning
+	// list, empty once every warning has cleared.
+	Health []string
+
+	// Status carries the full snapshot that produced this event, for
+	// EventNetMapUpdated and EventPrefsChanged, and for callers that want
+	// more than an Event's own summary fields.
+	Status *schema.TailscaleStatus
+}
+
+// diffStatus compares prev (nil on the first observation) against next and
+// returns the Events a Watch implementation should emit for the transition:
+// removed peers, then added peers, then online-state changes, then an
+// exit-node change, then a backend-state change, then a health change, then
+// a trailing NetMapUpdated summarizing the whole snapshot. Within each
+// category, peers are ordered by ID rather than Go's randomized map
+// iteration, so the result is deterministic.
+func diffStatus(prev, next *schema.TailscaleStatus) []Event {
+	if next == nil {
+		return nil
+	}
+	if prev == nil {
+		return []Event{{Kind: EventNetMapUpdated, Status: next}}
+	}
+
+	var events []Event
+
+	for _, id := range sortedPeerIDs(prev.Peer) {
+		if _, ok := next.Peer[id]; !ok {
+			events = append(events, Event{Kind: EventPeerRemoved, PeerID: id, PeerHostName: prev.Peer[id].HostName})
+		}
+	}
+	nextIDs := sortedPeerIDs(next.Peer)
+	for _, id := range nextIDs {
+		if _, ok := prev.Peer[id]; !ok {
+			cur := next.Peer[id]
+			events = append(events, Event{Kind: EventPeerAdded, PeerID: id, PeerHostName: cur.HostName, Online: cur.Online})
+		}
+	}
+	for _, id := range nextIDs {
+		old, ok := prev.Peer[id]
+		if !ok {
+			continue
+		}
+		cur := next.Peer[id]
+		if old.Online != cur.Online {
+			events = append(events, Event{Kind: EventPeerOnlineChanged, PeerID: id, PeerHostName: cur.HostName, Online: cur.Online})
+		}
+	}
+
+	if oldExit, newExit := activeExitNode(prev), activeExitNode(next); oldExit != newExit {
+		events = append(events, Event{Kind: EventExitNodeChanged, ExitNodeID: newExit})
+	}
+
+	if prev.BackendState != next.BackendState {
+		events = append(events, Event{Kind: EventSelfBackendStateChanged, BackendState: next.BackendState})
+	}
+
+	if !stringsEqual(prev.Health, next.Health) {
+		events = append(events, Event{Kind: EventHealthChanged, Health: next.Health})
+	}
+
+	events = append(events, Event{Kind: EventNetMapUpdated, Status: next})
+
+	return events
+}
+
+// sortedPeerIDs returns peers' keys in sorted order, so diffStatus emits
+// peer-add/peer-remove/online-change events in a deterministic order
+// instead of depending on Go's randomized map iteration.
+func sortedPeerIDs(peers map[string]schema.Peer) []string {
+	ids := make([]string, 0, len(peers))
+	for id := range peers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// stringsEqual reports whether a and b contain the same strings in the
+// same order, treating nil and empty as equal.
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, v := range a {
+		if v != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// activeExitNode returns the peer ID status reports as the active exit
+// node, or "" when none is set. schema.TailscaleStatus doesn't surface this
+// as its own field, so it's derived from which peer has ExitNode set.
+func activeExitNode(status *schema.TailscaleStatus) string {
+	if status == nil {
+		return ""
+	}
+	for id, peer := range status.Peer {
+		if peer.ExitNode {
+			return id
+		}
+	}
+	return ""
+}
+
+// watchPollInterval is how often cliBackend.Watch re-execs `tailscale status
+// --json` to diff against the previous snapshot, since the CLI has no
+// equivalent of tailscaled's IPN-bus push notifications.
+const watchPollInterval = 5 * time.Second
+
+// statusCache holds the last status a backend's Watch loop has observed, so
+// Status/ListPeers can become O(1) reads off it instead of re-querying
+// tailscaled or re-exec'ing the CLI every time. It stays empty (callers
+// should fall back to a direct query) until something calls Watch.
+type statusCache struct {
+	mu     sync.RWMutex
+	status *schema.TailscaleStatus
+}
+
+// get returns the cached status, or nil if Watch has never populated it.
+func (c *statusCache) get() *schema.TailscaleStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// swap stores next as the cached value and returns whatever was cached
+// before it (nil on the first call).
+func (c *statusCache) swap(next *schema.TailscaleStatus) *schema.TailscaleStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev := c.status
+	c.status = next
+	return prev
+}
+
+// eventsFromStatusChange adapts a single fresh status observation into the
+// Events diffStatus emits against cache's previous value, updating cache to
+// next. Shared by both backends' Watch loops so the diffing logic and cache
+// bookkeeping live in exactly one place.
+func eventsFromStatusChange(cache *statusCache, next *schema.TailscaleStatus) []Event {
+	prev := cache.swap(next)
+	return diffStatus(prev, next)
+}
+
+// watchWithPoller drives a Watch implementation that has no native push
+// mechanism (cliBackend): it calls fetch every watchPollInterval, diffs
+// against cache, and emits the resulting Events until ctx is done.
+func watchWithPoller(ctx context.Context, cache *statusCache, fetch func() (*schema.TailscaleStatus, error)) (<-chan Event, error) {
+	ch := make(chan Event)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+
+		for {
+			status, err := fetch()
+			if err == nil {
+				for _, ev := range eventsFromStatusChange(cache, status) {
+					select {
+					case ch <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// defaultWatchDebounce is the debounce window applied when a backend is
+// constructed without an explicit one (e.g. in tests), matching
+// config.Config's own default.
+const defaultWatchDebounce = 2 * time.Second
+
+// isFlappable reports whether kind is a per-peer event worth debouncing.
+// Every other kind (NetMapUpdated, PrefsChanged, BrowseToURL,
+// ExitNodeChanged, SelfBackendStateChanged, HealthChanged) already
+// represents a single point-in-time summary rather than a signal prone to
+// rapid flapping, so it's passed through immediately instead.
+func isFlappable(kind EventKind) bool {
+	switch kind {
+	case EventPeerAdded, EventPeerRemoved, EventPeerOnlineChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+// pendingEvent is one peer's not-yet-flushed debounced event, alongside the
+// deadline it should flush at if no further event for that peer arrives
+// first.
+type pendingEvent struct {
+	ev       Event
+	deadline time.Time
+}
+
+// debounceEvents wraps in, coalescing rapid repeated PeerAdded/PeerRemoved/
+// PeerOnlineChanged events for the same peer: while peer id keeps producing
+// new events within window of each other, only the latest is kept, and it's
+// emitted once window passes with no further change for that peer. Each
+// peer tracks its own deadline, so a peer that keeps flapping only ever
+// delays its own event, never a different peer's already-stable one. Peers
+// are flushed in the order they first started flapping, not map iteration
+// order, so a test feeding a synthetic stream can assert on it. window <= 0
+// uses defaultWatchDebounce. The returned channel is closed once in is
+// closed and any pending events have been flushed.
+func debounceEvents(ctx context.Context, in <-chan Event, window time.Duration) <-chan Event {
+	if window <= 0 {
+		window = defaultWatchDebounce
+	}
+
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+		pending := make(map[string]pendingEvent)
+		var order []string
+		var timer *time.Timer
+		var timerC <-chan time.Time
+
+		// flushDue sends every pending event whose deadline has passed (all
+		// of them, if now is the zero value), preserving flap order, and
+		// removes them from pending/order.
+		flushDue := func(now time.Time) bool {
+			remaining := order[:0]
+			for _, id := range order {
+				p, ok := pending[id]
+				if !ok {
+					continue
+				}
+				if !now.IsZero() && p.deadline.After(now) {
+					remaining = append(remaining, id)
+					continue
+				}
+				select {
+				case out <- p.ev:
+				case <-ctx.Done():
+					return false
+				}
+				delete(pending, id)
+			}
+			order = remaining
+			return true
+		}
+
+		// rearm (re)schedules timer to fire at the earliest deadline still
+		// in pending, stopping it if nothing is pending anymore.
+		rearm := func() {
+			if timer != nil {
+				timer.Stop()
+				timerC = nil
+			}
+			if len(pending) == 0 {
+				timer = nil
+				return
+			}
+			var next time.Time
+			for _, p := range pending {
+				if next.IsZero() || p.deadline.Before(next) {
+					next = p.deadline
+				}
+			}
+			timer = time.NewTimer(time.Until(next))
+			timerC = timer.C
+		}
+
+		for {
+			select {
+			case ev, ok := <-in:
+				if !ok {
+					if timer != nil {
+						timer.Stop()
+					}
+					flushDue(time.Time{})
+					return
+				}
+				if !isFlappable(ev.Kind) {
+					select {
+					case out <- ev:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+
+				if _, exists := pending[ev.PeerID]; !exists {
+					order = append(order, ev.PeerID)
+				}
+				pending[ev.PeerID] = pendingEvent{ev: ev, deadline: time.Now().Add(window)}
+				rearm()
+
+			case <-timerC:
+				if !flushDue(time.Now()) {
+					return
+				}
+				rearm()
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,200 @@
+// tailscale-mcp-server/internal/tailscale/events_test.go
+package tailscale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
+)
+
+func statusFixture(backendState string, health []string, peers map[string]schema.Peer) *schema.TailscaleStatus {
+	return &schema.TailscaleStatus{BackendState: backendState, Health: health, Peer: peers}
+}
+
+func TestDiffStatus(t *testing.T) {
+	t.Run("first observation emits a single NetMapUpdated", func(t *testing.T) {
+		next := statusFixture("Running", nil, nil)
+		events := diffStatus(nil, next)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventNetMapUpdated, events[0].Kind)
+		assert.Same(t, next, events[0].Status)
+	})
+
+	t.Run("peer removed, added, and online change, in that order", func(t *testing.T) {
+		prev := statusFixture("Running", nil, map[string]schema.Peer{
+			"a": {HostName: "laptop-a", Online: true},
+			"b": {HostName: "laptop-b", Online: false},
+		})
+		next := statusFixture("Running", nil, map[string]schema.Peer{
+			"b": {HostName: "laptop-b", Online: true},
+			"c": {HostName: "laptop-c", Online: true},
+		})
+
+		events := diffStatus(prev, next)
+
+		kinds := make([]EventKind, len(events))
+		for i, ev := range events {
+			kinds[i] = ev.Kind
+		}
+		assert.Equal(t, []EventKind{
+			EventPeerRemoved,
+			EventPeerAdded,
+			EventPeerOnlineChanged,
+			EventNetMapUpdated,
+		}, kinds)
+
+		assert.Equal(t, "a", events[0].PeerID)
+		assert.Equal(t, "c", events[1].PeerID)
+		assert.Equal(t, "b", events[2].PeerID)
+		assert.True(t, events[2].Online)
+	})
+
+	t.Run("exit node change", func(t *testing.T) {
+		prev := statusFixture("Running", nil, map[string]schema.Peer{"a": {ExitNode: false}})
+		next := statusFixture("Running", nil, map[string]schema.Peer{"a": {ExitNode: true}})
+
+		events := diffStatus(prev, next)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventExitNodeChanged, events[0].Kind)
+		assert.Equal(t, "a", events[0].ExitNodeID)
+	})
+
+	t.Run("backend state change", func(t *testing.T) {
+		prev := statusFixture("NeedsLogin", nil, nil)
+		next := statusFixture("Running", nil, nil)
+
+		events := diffStatus(prev, next)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventSelfBackendStateChanged, events[0].Kind)
+		assert.Equal(t, "Running", events[0].BackendState)
+	})
+
+	t.Run("health change", func(t *testing.T) {
+		prev := statusFixture("Running", nil, nil)
+		next := statusFixture("Running", []string{"not connected to the internet"}, nil)
+
+		events := diffStatus(prev, next)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventHealthChanged, events[0].Kind)
+		assert.Equal(t, []string{"not connected to the internet"}, events[0].Health)
+	})
+
+	t.Run("no change emits only the trailing NetMapUpdated", func(t *testing.T) {
+		status := statusFixture("Running", nil, map[string]schema.Peer{"a": {Online: true}})
+		events := diffStatus(status, status)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventNetMapUpdated, events[0].Kind)
+	})
+}
+
+// TestDebounceEvents feeds a synthetic stream of rapid per-peer flaps
+// through debounceEvents and checks that only the latest state per peer
+// survives, emitted in first-flapped order, while a non-flappable kind
+// passes straight through.
+func TestDebounceEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Event)
+	out := debounceEvents(ctx, in, 30*time.Millisecond)
+
+	go func() {
+		in <- Event{Kind: EventPeerOnlineChanged, PeerID: "a", Online: true}
+		in <- Event{Kind: EventPeerOnlineChanged, PeerID: "a", Online: false}
+		in <- Event{Kind: EventPeerOnlineChanged, PeerID: "a", Online: true}
+		in <- Event{Kind: EventPeerAdded, PeerID: "b", Online: true}
+		in <- Event{Kind: EventNetMapUpdated}
+		close(in)
+	}()
+
+	var got []Event
+	for ev := range out {
+		got = append(got, ev)
+	}
+
+	require.Len(t, got, 3)
+
+	// NetMapUpdated isn't debounced, so it reaches the reader before either
+	// peer's coalesced flaps, which only flush once the window (or the
+	// input closing) lets them.
+	assert.Equal(t, EventNetMapUpdated, got[0].Kind)
+
+	assert.Equal(t, EventPeerOnlineChanged, got[1].Kind)
+	assert.Equal(t, "a", got[1].PeerID)
+	assert.True(t, got[1].Online, "only the latest of peer a's flapped events should survive")
+
+	assert.Equal(t, EventPeerAdded, got[2].Kind)
+	assert.Equal(t, "b", got[2].PeerID)
+}
+
+// TestDebounceEventsDoesNotStarveStablePeer checks that a peer which keeps
+// flapping past the debounce window doesn't delay delivery of a different,
+// already-stable peer's pending event: each peer carries its own deadline,
+// so peer "b" flushes on schedule regardless of how long "a" keeps flapping.
+func TestDebounceEventsDoesNotStarveStablePeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const window = 30 * time.Millisecond
+
+	in := make(chan Event)
+	out := debounceEvents(ctx, in, window)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		in <- Event{Kind: EventPeerOnlineChanged, PeerID: "b", Online: false}
+
+		// Keep peer "a" flapping well past window, on an interval shorter
+		// than window so it never gets a chance to settle.
+		deadline := time.Now().Add(4 * window)
+		flap := true
+		for time.Now().Before(deadline) {
+			in <- Event{Kind: EventPeerOnlineChanged, PeerID: "a", Online: flap}
+			flap = !flap
+			time.Sleep(window / 3)
+		}
+		close(in)
+	}()
+
+	select {
+	case ev, ok := <-out:
+		require.True(t, ok)
+		assert.Equal(t, "b", ev.PeerID, "stable peer b must not be starved by peer a's continued flapping")
+	case <-time.After(2 * window):
+		t.Fatal("peer b's event was not delivered within 2x the debounce window")
+	}
+
+	<-done
+	for range out {
+		// Drain peer a's final coalesced event plus the close-triggered flush.
+	}
+}
+
+// TestDebounceEventsFlushesOnClose checks that a pending, not-yet-debounced
+// event is still delivered (rather than silently dropped) once the input
+// channel closes, even though the debounce window hasn't elapsed.
+func TestDebounceEventsFlushesOnClose(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan Event)
+	out := debounceEvents(ctx, in, time.Hour)
+
+	go func() {
+		in <- Event{Kind: EventPeerAdded, PeerID: "a"}
+		close(in)
+	}()
+
+	ev, ok := <-out
+	require.True(t, ok)
+	assert.Equal(t, "a", ev.PeerID)
+
+	_, ok = <-out
+	assert.False(t, ok, "channel should close once the input closes and pending events are flushed")
+}
@@ -7,7 +7,23 @@ import (
 	"syscall"
 )
 
-// setWinAttrs hides the console window that would otherwise pop up.
-func setWinAttrs(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+// setProcAttrs hides the console window that would otherwise pop up, and
+// puts the process in its own process group, isolated from the server's
+// own console. killTree still kills only the process itself (see below).
+func setProcAttrs(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// killTree forcibly terminates cmd's process. Windows has no real
+// equivalent of a POSIX process group kill short of a job object, so this
+// is the same hard kill exec.Cmd would otherwise do on context
+// cancellation; CREATE_NEW_PROCESS_GROUP above at least keeps it isolated.
+func killTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
 }
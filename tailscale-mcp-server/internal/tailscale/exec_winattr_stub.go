@@ -2,7 +2,22 @@
 
 package tailscale
 
-import "os/exec"
+import (
+	"os/exec"
+	"syscall"
+)
 
-// setWinAttrs is a no-op outside Windows so the code compiles everywhere.
-func setWinAttrs(cmd *exec.Cmd) {}
+// setProcAttrs puts the process in its own process group, so killTree can
+// signal the whole group instead of leaving orphaned children (e.g. a
+// `tailscale ping` that forked a helper) running past a shutdown deadline.
+func setProcAttrs(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killTree sends SIGKILL to cmd's whole process group.
+func killTree(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,321 @@
+// Package faketailscaled provides an in-process fake of tailscaled's
+// LocalAPI, listening on a real Unix domain socket the same way the real
+// daemon does, so internal/tailscale's localapiBackend (and everything
+// built on Client) can be exercised end-to-end without tailscaled actually
+// running. It complements package testfake, which fakes the `tailscale`
+// CLI binary: testfake stands in for one end of the process-spawn path,
+// faketailscaled stands in for the socket both the CLI and the LocalAPI
+// backend ultimately talk to.
+package faketailscaled
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Peer mirrors pkg/schema.Peer's JSON shape (see testfake.Peer for the
+// equivalent on the CLI-output side).
+type Peer struct {
+	ID           string   `json:"ID"`
+	HostName     string   `json:"HostName"`
+	DNSName      string   `json:"DNSName"`
+	TailscaleIPs []string `json:"TailscaleIPs"`
+	Online       bool     `json:"Online"`
+	ExitNode     bool     `json:"ExitNode"`
+}
+
+// Status mirrors pkg/schema.TailscaleStatus's JSON shape.
+type Status struct {
+	BackendState string           `json:"BackendState"`
+	Self         *Peer            `json:"Self"`
+	Peer         map[string]*Peer `json:"Peer"`
+}
+
+// Options configures a fake LocalAPI server.
+type Options struct {
+	// Status is served by GET /localapi/v0/status, sent to every new
+	// watch-ipn-bus watcher, and sent again on that stream whenever up,
+	// logout or a prefs change alters it. Defaults to a single
+	// "Running" self node with no peers and no exit node set.
+	Status Status
+
+	// BrowseToURL, if set, is emitted on the watch-ipn-bus stream right
+	// after LoginInteractive starts, the same way a real control server's
+	// OIDC/browser URL would be.
+	BrowseToURL string
+}
+
+// maskedPrefs mirrors the JSON shape localapiBackend.SetExitNode/
+// SetShieldsUp POST to /localapi/v0/prefs.
+type maskedPrefs struct {
+	ExitNodeID    string `json:"ExitNodeID"`
+	ExitNodeIDSet bool   `json:"ExitNodeIDSet"`
+	ShieldsUp     bool   `json:"ShieldsUp"`
+	ShieldsUpSet  bool   `json:"ShieldsUpSet"`
+}
+
+// Server is a running fake LocalAPI, reachable at SocketPath over the same
+// Unix socket path dialLocalAPI dials against a real tailscaled.
+type Server struct {
+	SocketPath string
+
+	mu          sync.Mutex
+	status      Status
+	browseToURL string
+	up          bool
+	shieldsUp   bool
+
+	listener net.Listener
+	watchers []chan []byte
+}
+
+// NewServer starts a fake LocalAPI listening on a Unix socket under a
+// per-test temp directory, and registers its shutdown via t.Cleanup.
+func NewServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+
+	if opts.Status.BackendState == "" {
+		opts.Status.BackendState = "Running"
+	}
+	if opts.Status.Self == nil {
+		opts.Status.Self = &Peer{ID: "self", HostName: "fake-self", TailscaleIPs: []string{"100.64.0.1"}}
+	}
+
+	s := &Server{
+		status:      opts.Status,
+		browseToURL: opts.BrowseToURL,
+	}
+
+	// tailscaled's real socket path is often too long for AF_UNIX's
+	// ~104-byte limit once nested under a test's generated temp dir, so
+	// this uses a short fixed name directly under a fresh top-level temp
+	// dir rather than t.TempDir()'s (deeper, test-name-derived) path.
+	dir, err := os.MkdirTemp("", "faketailscaled")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "tailscaled.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	s.listener = listener
+	s.SocketPath = socketPath
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/localapi/v0/status", s.handleStatus)
+	mux.HandleFunc("/localapi/v0/whois", s.handleWhoIs)
+	mux.HandleFunc("/localapi/v0/ping", s.handlePing)
+	mux.HandleFunc("/localapi/v0/netcheck", s.handleNetcheck)
+	mux.HandleFunc("/localapi/v0/up", s.handleUp)
+	mux.HandleFunc("/localapi/v0/logout", s.handleLogout)
+	mux.HandleFunc("/localapi/v0/prefs", s.handlePrefs)
+	mux.HandleFunc("/localapi/v0/login-interactive", s.handleLoginInteractive)
+	mux.HandleFunc("/localapi/v0/watch-ipn-bus", s.handleWatchIPNBus)
+
+	httpSrv := &http.Server{Handler: mux}
+	go func() { _ = httpSrv.Serve(listener) }()
+	t.Cleanup(func() { _ = httpSrv.Close() })
+
+	return s
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status := s.status
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (s *Server) handleWhoIs(w http.ResponseWriter, r *http.Request) {
+	addr := r.URL.Query().Get("addr")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.Self != nil {
+		for _, ip := range s.status.Self.TailscaleIPs {
+			if ip == addr {
+				writeJSON(w, map[string]string{"NodeName": s.status.Self.HostName, "NodeIP": ip, "UserLogin": "self@example.com"})
+				return
+			}
+		}
+	}
+	for _, peer := range s.status.Peer {
+		for _, ip := range peer.TailscaleIPs {
+			if ip == addr {
+				writeJSON(w, map[string]string{"NodeName": peer.HostName, "NodeIP": ip, "UserLogin": "peer@example.com"})
+				return
+			}
+		}
+	}
+
+	http.Error(w, "no match for "+addr, http.StatusNotFound)
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	ip := r.URL.Query().Get("ip")
+	_, _ = w.Write([]byte("pong from " + ip))
+}
+
+func (s *Server) handleNetcheck(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("Report:\n\t* UDP: true\n\t* IPv4: yes, fake\n"))
+}
+
+func (s *Server) handleUp(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.up = true
+	s.status.BackendState = "Running"
+	s.mu.Unlock()
+	s.broadcastStatus()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.up = false
+	s.status.BackendState = "NeedsLogin"
+	s.mu.Unlock()
+	s.broadcastStatus()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handlePrefs(w http.ResponseWriter, r *http.Request) {
+	var prefs maskedPrefs
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	if prefs.ExitNodeIDSet && s.status.Self != nil {
+		s.status.Self.ExitNode = prefs.ExitNodeID != ""
+	}
+	if prefs.ShieldsUpSet {
+		s.shieldsUp = prefs.ShieldsUp
+	}
+	s.mu.Unlock()
+	s.broadcastStatus()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleLoginInteractive(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	s.broadcastBrowseToURL()
+}
+
+// notify mirrors the subset of tailscaled's ipn.Notify localapiBackend
+// reads off the watch-ipn-bus stream.
+type notify struct {
+	Status      *Status `json:"Status,omitempty"`
+	BrowseToURL *string `json:"BrowseToURL,omitempty"`
+}
+
+func (s *Server) handleWatchIPNBus(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := make(chan []byte, 8)
+	s.mu.Lock()
+	s.watchers = append(s.watchers, ch)
+	status := s.status
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		for i, c := range s.watchers {
+			if c == ch {
+				s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	// Every new watcher immediately gets the current status, the same way
+	// a real tailscaled replays the last-known state to a fresh watcher.
+	writeNotify(w, flusher, notify{Status: &status})
+
+	for {
+		select {
+		case data, open := <-ch:
+			if !open {
+				return
+			}
+			if _, err := w.Write(data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) broadcastStatus() {
+	s.mu.Lock()
+	status := s.status
+	watchers := append([]chan []byte(nil), s.watchers...)
+	s.mu.Unlock()
+
+	data, err := json.Marshal(notify{Status: &status})
+	if err != nil {
+		return
+	}
+	for _, ch := range watchers {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func (s *Server) broadcastBrowseToURL() {
+	s.mu.Lock()
+	url := s.browseToURL
+	watchers := append([]chan []byte(nil), s.watchers...)
+	s.mu.Unlock()
+	if url == "" {
+		return
+	}
+
+	data, err := json.Marshal(notify{BrowseToURL: &url})
+	if err != nil {
+		return
+	}
+	for _, ch := range watchers {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func writeNotify(w http.ResponseWriter, flusher http.Flusher, n notify) {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write(data)
+	flusher.Flush()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,143 @@
+//go:build integration
+// +build integration
+
+package tailscale
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/testcontrol"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/testfake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests drive TailscaleCLI against the programmable fake `tailscale`
+// binary (package testfake), which in turn registers over plain HTTP
+// against an in-process control-plane fake (package testcontrol). That
+// gives an end-to-end register -> map -> status round trip without
+// bringing up a real tailscaled or paying for the noise-encrypted wire
+// protocol a real client speaks to control, which testcontrol does not
+// implement.
+const fakeNodeKey = "nodekey:integration-test"
+
+func newTestControlServer(t *testing.T, requireAuth bool) (*testcontrol.Server, *httptest.Server) {
+	t.Helper()
+
+	control := testcontrol.NewServer(testcontrol.Options{RequireAuth: requireAuth})
+	srv := httptest.NewServer(control)
+	t.Cleanup(srv.Close)
+
+	return control, srv
+}
+
+func TestCLIUpDownAgainstFakeControl(t *testing.T) {
+	require.NoError(t, logger.Initialize(0, ""))
+
+	control, srv := newTestControlServer(t, false)
+
+	testfake.UseFakeTailscale(t, testfake.Options{NodeKey: fakeNodeKey})
+
+	cli, err := NewTailscaleCLI()
+	require.NoError(t, err)
+
+	require.NoError(t, cli.Up(&UpOptions{LoginServer: srv.URL}))
+
+	AwaitRunning(t, cli)
+	require.NotEmpty(t, AwaitIP(t, cli))
+
+	nodes := control.Nodes()
+	require.Len(t, nodes, 1)
+	assert.True(t, nodes[0].Authorized)
+
+	MustCleanShutdown(t, cli)
+}
+
+// TestCLIExitNodeAndShieldsUp exercises SetExitNode and SetShieldsUp against
+// the fake `tailscale` binary. The binary's "set" handling is a no-op (real
+// prefs mutation lives in tailscaled, which this harness deliberately
+// doesn't run; see the package doc comment on testcontrol), so this asserts
+// on what the harness *can* observe: that both calls succeed and are
+// recorded with the arguments a real `tailscale set` invocation would get.
+func TestCLIExitNodeAndShieldsUp(t *testing.T) {
+	require.NoError(t, logger.Initialize(0, ""))
+
+	handle := testfake.UseFakeTailscale(t, testfake.Options{NodeKey: fakeNodeKey})
+
+	cli, err := NewTailscaleCLI()
+	require.NoError(t, err)
+
+	require.NoError(t, cli.SetExitNode("exit-node-1"))
+	require.NoError(t, cli.SetShieldsUp(true))
+
+	invocations := handle.Invocations(t)
+	require.Len(t, invocations, 2)
+	assert.Equal(t, []string{"set", "--exit-node", "exit-node-1"}, invocations[0].Argv)
+	assert.Equal(t, []string{"set", "--shields-up", "true"}, invocations[1].Argv)
+}
+
+// TestCLILoginInteractive exercises LoginInteractive/PollLogin against the
+// same register/authorize flow TestCLIUpRequiresAuthorization drives
+// directly. The fake `tailscale up` doesn't loop internally on
+// authorization like the real client does, so "the human finishes OIDC in
+// a browser" is simulated by authorizing the node then re-issuing `up`,
+// same as that test.
+func TestCLILoginInteractive(t *testing.T) {
+	require.NoError(t, logger.Initialize(0, ""))
+
+	control, srv := newTestControlServer(t, true)
+	testfake.UseFakeTailscale(t, testfake.Options{NodeKey: fakeNodeKey})
+
+	cli, err := NewTailscaleCLI()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := cli.LoginInteractive(ctx, &LoginOptions{LoginServer: srv.URL})
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.AuthURL)
+	assert.NotEmpty(t, session.SessionID)
+
+	require.True(t, control.Authorize(fakeNodeKey))
+	require.NoError(t, cli.Up(&UpOptions{LoginServer: srv.URL}))
+
+	state, err := cli.PollLogin(ctx, session)
+	require.NoError(t, err)
+	assert.Equal(t, LoginStateRunning, state)
+}
+
+func TestCLIUpRequiresAuthorization(t *testing.T) {
+	require.NoError(t, logger.Initialize(0, ""))
+
+	control, srv := newTestControlServer(t, true)
+
+	testfake.UseFakeTailscale(t, testfake.Options{NodeKey: fakeNodeKey})
+
+	cli, err := NewTailscaleCLI()
+	require.NoError(t, err)
+
+	// First attempt: the node registers but control hasn't authorized it
+	// yet, so `up` returns successfully (the fake mirrors real tailscale's
+	// non-blocking behavior here) but the backend stays down.
+	require.NoError(t, cli.Up(&UpOptions{LoginServer: srv.URL}))
+
+	status, err := cli.GetStatus()
+	require.NoError(t, err)
+	assert.Equal(t, "Stopped", status.BackendState)
+	require.NotEmpty(t, status.Health)
+
+	// Simulate visiting the returned auth URL.
+	require.True(t, control.Authorize(fakeNodeKey))
+
+	// Second attempt succeeds now that the node is authorized.
+	require.NoError(t, cli.Up(&UpOptions{LoginServer: srv.URL}))
+
+	status, err = cli.GetStatus()
+	require.NoError(t, err)
+	assert.Equal(t, "Running", status.BackendState)
+}
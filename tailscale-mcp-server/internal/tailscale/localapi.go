@@ -0,0 +1,472 @@
+// tailscale-mcp-server/internal/tailscale/localapi.go
+package tailscale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
+)
+
+// localAPIHost is a fake hostname used only to build well-formed request
+// URLs; the actual connection is routed to the local socket by the custom
+// DialContext below.
+const localAPIHost = "local-tailscaled.sock"
+
+// localapiBackend implements Client by speaking HTTP to tailscaled's
+// LocalAPI over its Unix socket (or named pipe on Windows), bypassing the
+// `tailscale` CLI and its shell-escaping surface entirely.
+type localapiBackend struct {
+	socketPath    string
+	httpClient    *http.Client
+	cache         statusCache
+	watchDebounce time.Duration
+}
+
+func newLocalAPIBackend(socketPath string, watchDebounce time.Duration) *localapiBackend {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialLocalAPI(ctx, socketPath)
+		},
+	}
+
+	return &localapiBackend{
+		socketPath:    socketPath,
+		httpClient:    &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		watchDebounce: watchDebounce,
+	}
+}
+
+// defaultLocalAPISocketPath returns the platform default tailscaled LocalAPI
+// socket/pipe path, honoring the TS_SOCKET override tailscaled itself
+// recognizes.
+func defaultLocalAPISocketPath() string {
+	if v := os.Getenv("TS_SOCKET"); v != "" {
+		return v
+	}
+
+	if runtime.GOOS == "windows" {
+		return `\\.\pipe\ProtectedPrefix\Administrators\Tailscale\tailscaled`
+	}
+	return "/var/run/tailscale/tailscaled.sock"
+}
+
+// isLocalAPIReachable does a best-effort dial to see whether a tailscaled
+// LocalAPI socket is present and accepting connections.
+func isLocalAPIReachable(socketPath string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), localAPIReachabilityTimeout)
+	defer cancel()
+
+	conn, err := dialLocalAPI(ctx, socketPath)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// do issues a LocalAPI request and returns the response body, treating any
+// non-200 status as an error.
+func (b *localapiBackend) do(ctx context.Context, method, path string, query url.Values, body io.Reader) ([]byte, error) {
+	u := url.URL{Scheme: "http", Host: localAPIHost, Path: path}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("building localapi request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("localapi %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading localapi response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("localapi %s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(data))
+	}
+
+	return data, nil
+}
+
+func (b *localapiBackend) Status(ctx context.Context) (*schema.TailscaleStatus, error) {
+	if cached := b.cache.get(); cached != nil {
+		return cached, nil
+	}
+
+	data, err := b.do(ctx, http.MethodGet, "/localapi/v0/status", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := schema.ParseSchema[schema.TailscaleStatus](string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing localapi status: %w", err)
+	}
+	return &status, nil
+}
+
+func (b *localapiBackend) WhoIs(ctx context.Context, addr string) (*WhoIsResponse, error) {
+	query := url.Values{"addr": []string{addr}}
+	data, err := b.do(ctx, http.MethodGet, "/localapi/v0/whois", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var who WhoIsResponse
+	if err := json.Unmarshal(data, &who); err != nil {
+		return nil, fmt.Errorf("parsing localapi whois response: %w", err)
+	}
+	return &who, nil
+}
+
+func (b *localapiBackend) Ping(ctx context.Context, target string, count int) (string, error) {
+	var results strings.Builder
+	for i := 0; i < count; i++ {
+		query := url.Values{"ip": []string{target}, "type": []string{"disco"}}
+		data, err := b.do(ctx, http.MethodPost, "/localapi/v0/ping", query, nil)
+		if err != nil {
+			return "", err
+		}
+		results.Write(data)
+		results.WriteByte('\n')
+	}
+	return strings.TrimSpace(results.String()), nil
+}
+
+func (b *localapiBackend) Netcheck(ctx context.Context) (string, error) {
+	data, err := b.do(ctx, http.MethodGet, "/localapi/v0/netcheck", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// localAPIUpRequest is a reduced view of the prefs LocalAPI's /up endpoint
+// accepts, covering the fields UpOptions exposes today.
+type localAPIUpRequest struct {
+	AcceptRoutes    bool     `json:"AcceptRoutes,omitempty"`
+	CorpDNS         bool     `json:"CorpDNS,omitempty"`
+	Hostname        string   `json:"Hostname,omitempty"`
+	AdvertiseRoutes []string `json:"AdvertiseRoutes,omitempty"`
+	ControlURL      string   `json:"ControlURL,omitempty"`
+}
+
+func (b *localapiBackend) Up(ctx context.Context, options *UpOptions) error {
+	if options != nil && options.AuthKey != "" {
+		// Auth-key login via LocalAPI requires the multi-step
+		// /localapi/v0/start + IPN-bus-watch flow, not a single /up call.
+		return fmt.Errorf("localapi backend does not yet support auth-key login; use --backend=cli")
+	}
+
+	req := localAPIUpRequest{}
+	if options != nil {
+		req.AcceptRoutes = options.AcceptRoutes
+		req.CorpDNS = options.AcceptDNS
+		req.Hostname = options.Hostname
+		req.AdvertiseRoutes = options.AdvertiseRoutes
+		req.ControlURL = options.LoginServer
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding up options: %w", err)
+	}
+
+	_, err = b.do(ctx, http.MethodPost, "/localapi/v0/up", nil, bytes.NewReader(body))
+	return err
+}
+
+func (b *localapiBackend) Logout(ctx context.Context) error {
+	_, err := b.do(ctx, http.MethodPost, "/localapi/v0/logout", nil, nil)
+	return err
+}
+
+// localAPIMaskedPrefs is a reduced view of the MaskedPrefs body
+// /localapi/v0/prefs expects: the preference values to apply, plus a
+// "...Set" bool per field marking which ones this request actually changes
+// (tailscaled leaves every unset field's preference untouched).
+type localAPIMaskedPrefs struct {
+	ExitNodeID    string `json:"ExitNodeID"`
+	ExitNodeIDSet bool   `json:"ExitNodeIDSet"`
+	ShieldsUp     bool   `json:"ShieldsUp"`
+	ShieldsUpSet  bool   `json:"ShieldsUpSet"`
+}
+
+func (b *localapiBackend) SetExitNode(ctx context.Context, nodeID string) error {
+	body, err := json.Marshal(localAPIMaskedPrefs{ExitNodeID: nodeID, ExitNodeIDSet: true})
+	if err != nil {
+		return fmt.Errorf("encoding exit node prefs: %w", err)
+	}
+	_, err = b.do(ctx, http.MethodPost, "/localapi/v0/prefs", nil, bytes.NewReader(body))
+	return err
+}
+
+func (b *localapiBackend) SetShieldsUp(ctx context.Context, enabled bool) error {
+	body, err := json.Marshal(localAPIMaskedPrefs{ShieldsUp: enabled, ShieldsUpSet: true})
+	if err != nil {
+		return fmt.Errorf("encoding shields-up prefs: %w", err)
+	}
+	_, err = b.do(ctx, http.MethodPost, "/localapi/v0/prefs", nil, bytes.NewReader(body))
+	return err
+}
+
+func (b *localapiBackend) ListPeers(ctx context.Context) ([]string, error) {
+	status, err := b.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var peers []string
+	for _, peer := range status.Peer {
+		if peer.HostName != "" {
+			peers = append(peers, peer.HostName)
+		}
+	}
+	return peers, nil
+}
+
+// ipnBusNotify is the subset of tailscaled's ipn.Notify this package reads
+// off the watch-ipn-bus stream. BrowseToURL carries the control server's
+// OIDC/interactive-login URL, same as the "To authenticate, visit:" line
+// the CLI prints when it gets the equivalent notification.
+type ipnBusNotify struct {
+	Status      *schema.TailscaleStatus `json:"Status,omitempty"`
+	BrowseToURL *string                 `json:"BrowseToURL,omitempty"`
+}
+
+// localAPILoginRequest is a reduced view of /localapi/v0/login-interactive's
+// request body, covering the fields LoginOptions exposes today.
+type localAPILoginRequest struct {
+	ControlURL  string `json:"ControlURL,omitempty"`
+	Hostname    string `json:"Hostname,omitempty"`
+	ForceReauth bool   `json:"ForceReauth,omitempty"`
+}
+
+// LoginInteractive starts an interactive login via tailscaled's LocalAPI,
+// then watches the IPN bus until it emits a BrowseToURL notification,
+// returning it as a LoginSession. PollLogin watches the same bus for the
+// Running notification that follows once the user completes the login.
+func (b *localapiBackend) LoginInteractive(ctx context.Context, options *LoginOptions) (*LoginSession, error) {
+	req := localAPILoginRequest{}
+	if options != nil {
+		req.ControlURL = options.LoginServer
+		req.Hostname = options.Hostname
+		req.ForceReauth = options.ForceReauth
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding login options: %w", err)
+	}
+
+	if _, err := b.do(ctx, http.MethodPost, "/localapi/v0/login-interactive", nil, bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("starting interactive login: %w", err)
+	}
+
+	notifyCh, err := b.watchIPNBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("watching for login URL: %w", err)
+	}
+
+	for n := range notifyCh {
+		if n.err != nil {
+			return nil, fmt.Errorf("watching for login URL: %w", n.err)
+		}
+		if n.notify.BrowseToURL != nil && *n.notify.BrowseToURL != "" {
+			return &LoginSession{
+				AuthURL:   *n.notify.BrowseToURL,
+				SessionID: generateLoginSessionID(),
+				Expiry:    time.Now().Add(loginSessionTTL),
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("watch-ipn-bus ended before a BrowseToURL notification arrived")
+}
+
+// PollLogin watches tailscaled's IPN bus until a notification reports
+// BackendState Running (the interactive login session identified, only
+// loosely, from LoginInteractive has completed) or ctx is done, whichever
+// comes first, also failing once session.Expiry has passed.
+func (b *localapiBackend) PollLogin(ctx context.Context, session *LoginSession) (LoginState, error) {
+	if session == nil {
+		return "", fmt.Errorf("login session cannot be nil")
+	}
+	if time.Now().After(session.Expiry) {
+		return "", fmt.Errorf("login session %s expired", session.SessionID)
+	}
+
+	notifyCh, err := b.watchIPNBus(ctx)
+	if err != nil {
+		return "", fmt.Errorf("watching login status: %w", err)
+	}
+
+	for n := range notifyCh {
+		if n.err != nil {
+			return "", fmt.Errorf("watching login status: %w", n.err)
+		}
+		if n.notify.Status != nil && LoginState(n.notify.Status.BackendState) == LoginStateRunning {
+			return LoginStateRunning, nil
+		}
+		if time.Now().After(session.Expiry) {
+			return "", fmt.Errorf("login session %s expired", session.SessionID)
+		}
+	}
+
+	return "", fmt.Errorf("watch-ipn-bus ended before reaching Running")
+}
+
+// ipnBusNotification pairs a decoded ipnBusNotify with the error that ended
+// the stream, if any, so watchIPNBus's channel can carry both without a
+// second error channel.
+type ipnBusNotification struct {
+	notify ipnBusNotify
+	err    error
+}
+
+// watchIPNBus opens a GET to /localapi/v0/watch-ipn-bus and streams decoded
+// notifications until ctx is done or the connection ends. Subscribe,
+// LoginInteractive, and PollLogin all build on this rather than opening
+// their own connections, since tailscaled only needs one watcher per
+// caller.
+func (b *localapiBackend) watchIPNBus(ctx context.Context) (<-chan ipnBusNotification, error) {
+	u := url.URL{Scheme: "http", Host: localAPIHost, Path: "/localapi/v0/watch-ipn-bus", RawQuery: "mask=0"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("building watch-ipn-bus request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("watch-ipn-bus: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("watch-ipn-bus: %s", resp.Status)
+	}
+
+	ch := make(chan ipnBusNotification)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		dec := json.NewDecoder(resp.Body)
+		for {
+			var n ipnBusNotify
+			if err := dec.Decode(&n); err != nil {
+				if ctx.Err() == nil {
+					select {
+					case ch <- ipnBusNotification{err: err}:
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+			select {
+			case ch <- ipnBusNotification{notify: n}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Subscribe streams status changes off tailscaled's IPN bus
+// (/localapi/v0/watch-ipn-bus), which pushes a new notification every time
+// the node's state changes instead of making callers poll Status. The
+// request, connection, and returned channel all end together when ctx is
+// canceled.
+func (b *localapiBackend) Subscribe(ctx context.Context) (<-chan StatusChange, error) {
+	notifyCh, err := b.watchIPNBus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan StatusChange)
+	go func() {
+		defer close(ch)
+		for n := range notifyCh {
+			if n.err != nil {
+				select {
+				case ch <- StatusChange{Err: n.err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+			if n.notify.Status == nil {
+				continue
+			}
+			select {
+			case ch <- StatusChange{Status: n.notify.Status}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// Watch streams typed Events derived from tailscaled's IPN bus: each Status
+// notification is diffed against the backend's cache (which Watch then
+// keeps warm for Status/ListPeers to read from), and each BrowseToURL
+// notification is forwarded directly. The raw diff stream is debounced (see
+// debounceEvents) before it reaches the caller.
+func (b *localapiBackend) Watch(ctx context.Context) (<-chan Event, error) {
+	notifyCh, err := b.watchIPNBus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(chan Event)
+	go func() {
+		defer close(raw)
+		for n := range notifyCh {
+			if n.err != nil {
+				return
+			}
+			if n.notify.BrowseToURL != nil && *n.notify.BrowseToURL != "" {
+				select {
+				case raw <- Event{Kind: EventBrowseToURL, BrowseToURL: *n.notify.BrowseToURL}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if n.notify.Status == nil {
+				continue
+			}
+			for _, ev := range eventsFromStatusChange(&b.cache, n.notify.Status) {
+				select {
+				case raw <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return debounceEvents(ctx, raw, b.watchDebounce), nil
+}
+
+var _ Client = (*localapiBackend)(nil)
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package tailscale
+
+import (
+	"context"
+	"net"
+)
+
+// dialLocalAPI dials tailscaled's LocalAPI Unix domain socket.
+func dialLocalAPI(ctx context.Context, socketPath string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", socketPath)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package tailscale
+
+import (
+	"context"
+	"net"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// dialLocalAPI dials tailscaled's LocalAPI named pipe.
+func dialLocalAPI(ctx context.Context, pipePath string) (net.Conn, error) {
+	return winio.DialPipeContext(ctx, pipePath)
+}
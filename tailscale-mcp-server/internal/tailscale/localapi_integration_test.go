@@ -0,0 +1,70 @@
+//go:build integration
+// +build integration
+
+package tailscale
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/faketailscaled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests drive localapiBackend (via Client) against an in-process fake
+// of tailscaled's own LocalAPI (package faketailscaled), the LocalAPI-side
+// counterpart to TestCLIUpDownAgainstFakeControl's CLI-side coverage in
+// integration_test.go. Exercising both backends this way is what makes
+// Client's contract (see client.go) actually verified rather than assumed
+// for whichever backend a given test happens to use.
+func TestLocalAPIStatusAndPing(t *testing.T) {
+	fake := faketailscaled.NewServer(t, faketailscaled.Options{
+		Status: faketailscaled.Status{
+			Self: &faketailscaled.Peer{ID: "self", HostName: "laptop", TailscaleIPs: []string{"100.64.0.1"}},
+			Peer: map[string]*faketailscaled.Peer{
+				"peer1": {ID: "peer1", HostName: "server", TailscaleIPs: []string{"100.64.0.2"}, Online: true},
+			},
+		},
+	})
+
+	backend := newLocalAPIBackend(fake.SocketPath, 0)
+	ctx := context.Background()
+
+	status, err := backend.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Running", status.BackendState)
+	assert.Equal(t, "laptop", status.Self.HostName)
+
+	peers, err := backend.ListPeers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"server"}, peers)
+
+	who, err := backend.WhoIs(ctx, "100.64.0.2")
+	require.NoError(t, err)
+	assert.Equal(t, "server", who.NodeName)
+
+	result, err := backend.Ping(ctx, "100.64.0.2", 1)
+	require.NoError(t, err)
+	assert.Contains(t, result, "100.64.0.2")
+}
+
+func TestLocalAPISetExitNode(t *testing.T) {
+	fake := faketailscaled.NewServer(t, faketailscaled.Options{})
+	backend := newLocalAPIBackend(fake.SocketPath, 0)
+	ctx := context.Background()
+
+	require.NoError(t, backend.SetExitNode(ctx, "nodekey:exit"))
+	require.NoError(t, backend.SetShieldsUp(ctx, true))
+}
+
+func TestLocalAPIUpAndLogout(t *testing.T) {
+	fake := faketailscaled.NewServer(t, faketailscaled.Options{
+		Status: faketailscaled.Status{BackendState: "NeedsLogin"},
+	})
+	backend := newLocalAPIBackend(fake.SocketPath, 0)
+	ctx := context.Background()
+
+	require.NoError(t, backend.Up(ctx, &UpOptions{Hostname: "laptop"}))
+	require.NoError(t, backend.Logout(ctx))
+}
@@ -0,0 +1,181 @@
+// tailscale-mcp-server/internal/tailscale/login.go
+package tailscale
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// loginSessionTTL bounds how long a LoginSession's AuthURL is expected to
+// stay valid; it mirrors the ~10 minute window tailscaled's control client
+// gives an interactive login before it must be restarted.
+const loginSessionTTL = 10 * time.Minute
+
+// loginPollInterval is how often PollLogin re-checks status while waiting
+// for the backend to report Running.
+const loginPollInterval = 2 * time.Second
+
+// authURLPattern extracts the auth URL `tailscale up` prints to stdout when
+// starting an interactive (OIDC or pre-auth-key-less) login, e.g.
+// "To authenticate, visit:\n\n\thttps://login.tailscale.com/a/0123456789ab\n".
+var authURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// LoginOptions configures LoginInteractive.
+type LoginOptions struct {
+	LoginServer string
+	Hostname    string
+	// ForceReauth starts a fresh login even if the node is already
+	// authenticated, via `tailscale up --force-reauth`.
+	ForceReauth bool
+}
+
+// LoginSession identifies a single in-progress interactive login, from the
+// moment LoginInteractive captures its AuthURL until PollLogin reports it
+// Running (or it expires).
+type LoginSession struct {
+	AuthURL   string
+	SessionID string
+	Expiry    time.Time
+}
+
+// LoginState mirrors the subset of tailscaled's BackendState values
+// relevant to a login in progress.
+type LoginState string
+
+const (
+	LoginStateNeedsLogin LoginState = "NeedsLogin"
+	LoginStateStarting   LoginState = "Starting"
+	LoginStateRunning    LoginState = "Running"
+)
+
+// LoginInteractive starts `tailscale up` with an interactive login (no
+// AuthKey), capturing the AuthURL the CLI prints once it reaches out to the
+// control server, and returns before the login itself completes — the
+// caller is expected to present AuthURL to a human and then call PollLogin.
+// The `up` invocation keeps running in the background until the login
+// completes or ctx is canceled.
+func (c *TailscaleCLI) LoginInteractive(ctx context.Context, options *LoginOptions) (*LoginSession, error) {
+	args := []string{"up"}
+
+	if options != nil {
+		if options.LoginServer != "" {
+			if err := c.validateStringInput(options.LoginServer, "loginServer"); err != nil {
+				return nil, fmt.Errorf("invalid login server: %w", err)
+			}
+			args = append(args, "--login-server", options.LoginServer)
+		}
+		if options.Hostname != "" {
+			if err := c.validateStringInput(options.Hostname, "hostname"); err != nil {
+				return nil, fmt.Errorf("invalid hostname: %w", err)
+			}
+			args = append(args, "--hostname", options.Hostname)
+		}
+		if options.ForceReauth {
+			args = append(args, "--force-reauth")
+		}
+	}
+
+	urlCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	go func() {
+		_, err := c.ExecuteCommandStreaming(ctx, args, nil, func(line string) {
+			if url := authURLPattern.FindString(line); url != "" {
+				select {
+				case urlCh <- url:
+				default:
+				}
+			}
+		})
+		if err != nil {
+			select {
+			case errCh <- err:
+			default:
+			}
+		}
+	}()
+
+	select {
+	case url := <-urlCh:
+		return &LoginSession{
+			AuthURL:   url,
+			SessionID: generateLoginSessionID(),
+			Expiry:    time.Now().Add(loginSessionTTL),
+		}, nil
+	case err := <-errCh:
+		return nil, fmt.Errorf("tailscale up: %w", err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PollLogin blocks until the node's BackendState reports Running (the
+// interactive login identified by session has completed) or ctx is done,
+// whichever comes first. It also fails once session.Expiry has passed,
+// since the AuthURL the caller showed the user is no longer good past
+// that point.
+func (c *TailscaleCLI) PollLogin(ctx context.Context, session *LoginSession) (LoginState, error) {
+	if session == nil {
+		return "", errors.New("login session cannot be nil")
+	}
+
+	ticker := time.NewTicker(loginPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if time.Now().After(session.Expiry) {
+			return "", fmt.Errorf("login session %s expired", session.SessionID)
+		}
+
+		status, err := c.GetStatus()
+		if err == nil {
+			switch LoginState(status.BackendState) {
+			case LoginStateRunning:
+				return LoginStateRunning, nil
+			case LoginStateNeedsLogin, LoginStateStarting:
+				// keep polling
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// LogoutAndRevoke logs the node out locally via `tailscale logout`, then —
+// when api is non-nil and deviceID is set — also expires the node's key
+// server-side via the admin API, so a copy of tailscaled's local state left
+// behind on disk can't be used to silently resume the session.
+func (c *TailscaleCLI) LogoutAndRevoke(ctx context.Context, api *APIClient, deviceID string) error {
+	if err := c.Logout(); err != nil {
+		return fmt.Errorf("local logout: %w", err)
+	}
+
+	if api == nil || deviceID == "" {
+		return nil
+	}
+
+	if resp := api.ExpireDeviceKey(ctx, deviceID); !resp.Success {
+		return fmt.Errorf("logged out locally, but revoking device key via admin API failed: %s", resp.Error)
+	}
+	return nil
+}
+
+// generateLoginSessionID mirrors generateSessionID in internal/server, used
+// for the same reason: an opaque ID a caller round-trips back to us without
+// us needing to trust anything about where it came from.
+func generateLoginSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "0000000000000000000000000000000000"
+	}
+	return hex.EncodeToString(buf)
+}
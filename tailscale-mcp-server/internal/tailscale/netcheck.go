@@ -0,0 +1,152 @@
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConnectivityReport is a structured view of a `tailscale netcheck` run, so
+// callers don't have to re-parse the CLI's human-readable or JSON output
+// themselves.
+type ConnectivityReport struct {
+	UDP                   bool                  `json:"udp"`
+	IPv4                  bool                  `json:"ipv4"`
+	IPv6                  bool                  `json:"ipv6"`
+	MappingVariesByDestIP bool                  `json:"mappingVariesByDestIP"`
+	HairPinning           *bool                 `json:"hairPinning,omitempty"`
+	PreferredDERP         int                   `json:"preferredDERP"`
+	RegionLatencies       map[int]time.Duration `json:"regionLatencies,omitempty"`
+	GlobalV4              string                `json:"globalV4,omitempty"`
+	GlobalV6              string                `json:"globalV6,omitempty"`
+	CaptivePortal         *bool                 `json:"captivePortal,omitempty"`
+}
+
+// ConnectivityReport runs `tailscale netcheck` and returns a structured
+// result, preferring the CLI's `--format=json` output and falling back to
+// parsing its human-readable text on older CLIs that don't support the
+// flag. onLine, if non-nil, is invoked once per line of output as it's
+// produced (see ExecuteCommandStreaming); pass nil for a non-streaming call.
+func (c *TailscaleCLI) ConnectivityReport(ctx context.Context, onLine func(line string)) (*ConnectivityReport, error) {
+	jsonOut, err := c.ExecuteCommandStreaming(ctx, []string{"netcheck", "--format=json"}, nil, onLine)
+	if err == nil {
+		if report, perr := parseNetcheckJSON(jsonOut); perr == nil {
+			return report, nil
+		}
+	}
+
+	textOut, err := c.ExecuteCommandStreaming(ctx, []string{"netcheck"}, nil, onLine)
+	if err != nil {
+		return nil, fmt.Errorf("netcheck failed: %w", err)
+	}
+	return parseNetcheckText(textOut), nil
+}
+
+// rawNetcheckJSON mirrors the fields `tailscale netcheck --format=json`
+// emits (tailscale.com/net/netcheck.Report), keyed by region ID.
+type rawNetcheckJSON struct {
+	UDP                   bool             `json:"UDP"`
+	IPv4                  bool             `json:"IPv4"`
+	IPv6                  bool             `json:"IPv6"`
+	MappingVariesByDestIP bool             `json:"MappingVariesByDestIP"`
+	HairPinning           *bool            `json:"HairPinning"`
+	PreferredDERP         int              `json:"PreferredDERP"`
+	RegionLatency         map[string]int64 `json:"RegionLatency"`
+	GlobalV4              string           `json:"GlobalV4"`
+	GlobalV6              string           `json:"GlobalV6"`
+	CaptivePortal         *bool            `json:"CaptivePortal"`
+}
+
+func parseNetcheckJSON(output string) (*ConnectivityReport, error) {
+	var raw rawNetcheckJSON
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("parsing netcheck JSON: %w", err)
+	}
+
+	report := &ConnectivityReport{
+		UDP:                   raw.UDP,
+		IPv4:                  raw.IPv4,
+		IPv6:                  raw.IPv6,
+		MappingVariesByDestIP: raw.MappingVariesByDestIP,
+		HairPinning:           raw.HairPinning,
+		PreferredDERP:         raw.PreferredDERP,
+		GlobalV4:              raw.GlobalV4,
+		GlobalV6:              raw.GlobalV6,
+		CaptivePortal:         raw.CaptivePortal,
+	}
+
+	for regionStr, nanos := range raw.RegionLatency {
+		region, err := strconv.Atoi(regionStr)
+		if err != nil {
+			continue
+		}
+		if report.RegionLatencies == nil {
+			report.RegionLatencies = make(map[int]time.Duration, len(raw.RegionLatency))
+		}
+		report.RegionLatencies[region] = time.Duration(nanos)
+	}
+
+	return report, nil
+}
+
+// netcheckDERPLatencyLine matches a "<region>: <duration>" latency line from
+// `tailscale netcheck`'s plain-text output, e.g. "  1: 12.3ms".
+var netcheckDERPLatencyLine = regexp.MustCompile(`^(\d+):\s*([\d.]+(?:ns|us|µs|ms|s))$`)
+
+// netcheckPreferredDERPLine matches the "PreferredDERP" summary line.
+var netcheckPreferredDERPLine = regexp.MustCompile(`(?i)PreferredDERP:\s*(\d+)`)
+
+// parseNetcheckText is a best-effort fallback for CLIs old enough to not
+// support `netcheck --format=json`. It covers the fields this package's
+// callers actually use; anything it can't confidently parse is left at its
+// zero value rather than guessed at.
+func parseNetcheckText(output string) *ConnectivityReport {
+	report := &ConnectivityReport{}
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rawLine), "*"))
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+
+		switch {
+		case strings.HasPrefix(lower, "udp:"):
+			report.UDP = netcheckTextIsTrue(line)
+		case strings.HasPrefix(lower, "ipv4:"):
+			report.IPv4 = netcheckTextIsTrue(line)
+		case strings.HasPrefix(lower, "ipv6:"):
+			report.IPv6 = netcheckTextIsTrue(line)
+		case strings.HasPrefix(lower, "mappingvariesbydestip:"):
+			report.MappingVariesByDestIP = netcheckTextIsTrue(line)
+		case strings.HasPrefix(lower, "preferredderp:"):
+			if m := netcheckPreferredDERPLine.FindStringSubmatch(line); m != nil {
+				report.PreferredDERP, _ = strconv.Atoi(m[1])
+			}
+		default:
+			if m := netcheckDERPLatencyLine.FindStringSubmatch(line); m != nil {
+				region, err := strconv.Atoi(m[1])
+				if err != nil {
+					continue
+				}
+				d, err := time.ParseDuration(m[2])
+				if err != nil {
+					continue
+				}
+				if report.RegionLatencies == nil {
+					report.RegionLatencies = make(map[int]time.Duration)
+				}
+				report.RegionLatencies[region] = d
+			}
+		}
+	}
+
+	return report
+}
+
+func netcheckTextIsTrue(line string) bool {
+	lower := strings.ToLower(line)
+	return strings.Contains(lower, "true") || strings.Contains(lower, "yes")
+}
@@ -0,0 +1,82 @@
+package tailscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// netcheckJSONFixture is a representative `tailscale netcheck --format=json`
+// response, trimmed to the fields ConnectivityReport cares about.
+const netcheckJSONFixture = `{
+	"UDP": true,
+	"IPv4": true,
+	"IPv6": false,
+	"MappingVariesByDestIP": false,
+	"HairPinning": true,
+	"PreferredDERP": 1,
+	"RegionLatency": {"1": 12300000, "2": 45600000},
+	"GlobalV4": "203.0.113.1:41641",
+	"CaptivePortal": false
+}`
+
+func TestParseNetcheckJSON(t *testing.T) {
+	t.Parallel()
+
+	report, err := parseNetcheckJSON(netcheckJSONFixture)
+	require.NoError(t, err)
+
+	assert.True(t, report.UDP)
+	assert.True(t, report.IPv4)
+	assert.False(t, report.IPv6)
+	assert.False(t, report.MappingVariesByDestIP)
+	require.NotNil(t, report.HairPinning)
+	assert.True(t, *report.HairPinning)
+	assert.Equal(t, 1, report.PreferredDERP)
+	assert.Equal(t, "203.0.113.1:41641", report.GlobalV4)
+	require.NotNil(t, report.CaptivePortal)
+	assert.False(t, *report.CaptivePortal)
+	assert.Equal(t, map[int]time.Duration{
+		1: 12300 * time.Microsecond,
+		2: 45600 * time.Microsecond,
+	}, report.RegionLatencies)
+}
+
+func TestParseNetcheckJSON_Malformed(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseNetcheckJSON("not json")
+	assert.Error(t, err)
+}
+
+// netcheckTextFixture approximates the plain-text output of an older
+// `tailscale netcheck` build that predates --format=json.
+const netcheckTextFixture = `
+Report:
+	* UDP: true
+	* IPv4: yes
+	* IPv6: false
+	* MappingVariesByDestIP: false
+	* PreferredDERP: 1
+	* Regions:
+	*     1: 12.3ms
+	*     2: 45.6ms
+`
+
+func TestParseNetcheckText(t *testing.T) {
+	t.Parallel()
+
+	report := parseNetcheckText(netcheckTextFixture)
+
+	assert.True(t, report.UDP)
+	assert.True(t, report.IPv4)
+	assert.False(t, report.IPv6)
+	assert.False(t, report.MappingVariesByDestIP)
+	assert.Equal(t, 1, report.PreferredDERP)
+	assert.Equal(t, map[int]time.Duration{
+		1: 12300 * time.Microsecond,
+		2: 45600 * time.Microsecond,
+	}, report.RegionLatencies)
+}
@@ -0,0 +1,66 @@
+// tailscale-mcp-server/internal/tailscale/observer.go
+package tailscale
+
+import "time"
+
+// RequestObserver is notified after every Tailscale API or CLI call
+// completes, so a caller outside this package (e.g. the tools package's
+// Prometheus metrics) can record latency without this package depending on
+// a metrics client library itself.
+type RequestObserver func(backend, method string, duration time.Duration, success bool)
+
+// requestObserver is nil until SetRequestObserver installs one, in which
+// case APIClient.request and TailscaleCLI.ExecuteCommand simply skip
+// observing their calls.
+var requestObserver RequestObserver
+
+// SetRequestObserver installs obs as the RequestObserver for every
+// subsequent call made through this package. Only one observer can be
+// installed at a time; the last call wins, matching logger.SetLevel's
+// single-global-setting convention.
+func SetRequestObserver(obs RequestObserver) {
+	requestObserver = obs
+}
+
+// observeRequest reports a call that started at start to requestObserver,
+// if one is installed.
+func observeRequest(backend, method string, start time.Time, success bool) {
+	if requestObserver != nil {
+		requestObserver(backend, method, time.Since(start), success)
+	}
+}
+
+// ResilienceEvent names a transport-level event from request's retry, rate
+// limiting, or circuit breaker logic, as opposed to RequestObserver's
+// per-call latency/success reporting.
+type ResilienceEvent string
+
+const (
+	ResilienceEventRetry           ResilienceEvent = "retry"
+	ResilienceEventRateLimited     ResilienceEvent = "rate_limited"
+	ResilienceEventCircuitOpened   ResilienceEvent = "circuit_opened"
+	ResilienceEventCircuitHalfOpen ResilienceEvent = "circuit_half_open"
+	ResilienceEventCircuitClosed   ResilienceEvent = "circuit_closed"
+)
+
+// ResilienceObserver is notified whenever request retries a call, waits on
+// the rate limiter, or the circuit breaker changes state.
+type ResilienceObserver func(event ResilienceEvent, backend string)
+
+// resilienceObserver is nil until SetResilienceObserver installs one.
+var resilienceObserver ResilienceObserver
+
+// SetResilienceObserver installs obs as the ResilienceObserver for every
+// subsequent call made through this package. Only one observer can be
+// installed at a time; the last call wins, matching SetRequestObserver.
+func SetResilienceObserver(obs ResilienceObserver) {
+	resilienceObserver = obs
+}
+
+// observeResilience reports event to resilienceObserver, if one is
+// installed.
+func observeResilience(event ResilienceEvent, backend string) {
+	if resilienceObserver != nil {
+		resilienceObserver(event, backend)
+	}
+}
@@ -0,0 +1,237 @@
+// tailscale-mcp-server/internal/tailscale/resilience.go
+package tailscale
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Retry tuning for request(): only idempotent calls are retried
+// automatically, since a failed POST/PATCH may or may not have taken
+// effect server-side.
+const (
+	retryMaxAttempts = 5
+	retryBaseDelay   = 250 * time.Millisecond
+	retryMaxDelay    = 8 * time.Second
+)
+
+// idempotentPOSTSuffixes are POST endpoints that, despite the method, are
+// safe to retry: re-sending one has the same effect as sending it once
+// (e.g. re-authorizing an already-authorized device is a no-op).
+var idempotentPOSTSuffixes = []string{"/authorized", "/key", "/key/expire"}
+
+// isIdempotent reports whether method/path is safe for request to retry
+// automatically after a transient failure.
+func isIdempotent(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		for _, suffix := range idempotentPOSTSuffixes {
+			if strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryableStatus reports whether a failed request is worth retrying: a
+// transport-level failure (statusCode 0, no response was ever received),
+// rate limiting, or a transient server-side failure.
+func retryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// backoff returns the delay before retry attempt n (0-based), using
+// exponential backoff with full jitter: a uniformly random duration between
+// 0 and min(retryMaxDelay, retryBaseDelay*2^n).
+func backoff(n int) time.Duration {
+	max := retryMaxDelay
+	if shifted := retryBaseDelay * time.Duration(uint64(1)<<uint(n)); shifted > 0 && shifted < max {
+		max = shifted
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// parseRetryAfter reads a Retry-After header (either a count of seconds or
+// an HTTP date), reporting ok=false if it's absent or unparseable.
+func parseRetryAfter(h http.Header) (d time.Duration, ok bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// tokenBucket is a minimal token-bucket rate limiter for APIClient's own
+// outbound requests. Unlike the ratelimit package (which throttles inbound
+// MCP tool calls per clientID/command, since many different callers share
+// one server), there's exactly one caller of the Tailscale API here, so a
+// single bucket per client is enough.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+// newTokenBucket creates a bucket starting full, refilling at ratePerSecond
+// up to burst tokens.
+func newTokenBucket(ratePerSecond float64, burst int) *tokenBucket {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 10
+	}
+	if burst <= 0 {
+		burst = 20
+	}
+	return &tokenBucket{
+		rate:     ratePerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is canceled.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.lastFill).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.lastFill = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// circuitState is a circuitBreaker's current state.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker opens after threshold consecutive server-side failures,
+// rejecting every request without making the HTTP call until openDuration
+// has passed, at which point it lets a single request through (half-open)
+// to probe whether the backend has recovered. Only the caller that wins
+// the open-to-half-open transition gets that probe; every other concurrent
+// caller keeps seeing the breaker as not-yet-allowed until recordResult
+// resolves the probe one way or the other. If the winning caller never
+// calls recordResult at all (e.g. its context is canceled between allow
+// and the request actually completing), probeDeadline bounds how long the
+// breaker waits for that resolution before reopening on its own, so a lost
+// probe can't wedge it in circuitHalfOpen forever.
+type circuitBreaker struct {
+	mu            sync.Mutex
+	state         circuitState
+	fails         int
+	openedAt      time.Time
+	probeDeadline time.Time
+	threshold     int
+	openFor       time.Duration
+}
+
+// newCircuitBreaker creates a closed circuit breaker.
+func newCircuitBreaker(threshold int, openFor time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if openFor <= 0 {
+		openFor = 30 * time.Second
+	}
+	return &circuitBreaker{threshold: threshold, openFor: openFor}
+}
+
+// allow reports whether a request may proceed. A closed breaker always
+// allows; an open breaker allows nothing until openFor has elapsed, at
+// which point exactly one caller wins the transition to half-open and gets
+// to probe, while every other caller (concurrent with that probe, or
+// arriving before recordResult resolves it) keeps being rejected. A probe
+// that doesn't resolve within probeDeadline (its caller never called
+// recordResult, e.g. because its context was canceled first) reopens the
+// breaker instead of leaving it wedged in circuitHalfOpen indefinitely.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitClosed:
+		return true
+	case circuitHalfOpen:
+		if time.Now().Before(b.probeDeadline) {
+			return false
+		}
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+		observeResilience(ResilienceEventCircuitOpened, "api")
+		return false
+	}
+
+	if time.Since(b.openedAt) < b.openFor {
+		return false
+	}
+	b.state = circuitHalfOpen
+	b.probeDeadline = time.Now().Add(b.openFor)
+	observeResilience(ResilienceEventCircuitHalfOpen, "api")
+	return true
+}
+
+// recordResult updates the breaker after a request completes. serverErr is
+// true for 5xx responses and transport-level failures; a 4xx or successful
+// response counts as a success for breaker purposes, since that's the
+// caller's fault, not the backend's.
+func (b *circuitBreaker) recordResult(serverErr bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if serverErr {
+		b.fails++
+		if b.state != circuitOpen && b.fails >= b.threshold {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+			observeResilience(ResilienceEventCircuitOpened, "api")
+		}
+		return
+	}
+
+	if b.state != circuitClosed {
+		observeResilience(ResilienceEventCircuitClosed, "api")
+	}
+	b.state = circuitClosed
+	b.fails = 0
+}
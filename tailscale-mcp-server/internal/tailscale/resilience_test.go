@@ -0,0 +1,125 @@
+// tailscale-mcp-server/internal/tailscale/resilience_test.go
+package tailscale
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsIdempotent(t *testing.T) {
+	assert.True(t, isIdempotent("GET", "/api/v2/devices"))
+	assert.True(t, isIdempotent("DELETE", "/api/v2/device/1"))
+	assert.True(t, isIdempotent("POST", "/api/v2/device/1/authorized"))
+	assert.True(t, isIdempotent("POST", "/api/v2/device/1/key"))
+	assert.True(t, isIdempotent("POST", "/api/v2/tailnet/t/key/expire"))
+	assert.False(t, isIdempotent("POST", "/api/v2/device/1/routes"))
+	assert.False(t, isIdempotent("PUT", "/api/v2/device/1"))
+}
+
+func TestRetryableStatus(t *testing.T) {
+	assert.True(t, retryableStatus(0))
+	assert.True(t, retryableStatus(429))
+	assert.True(t, retryableStatus(500))
+	assert.True(t, retryableStatus(503))
+	assert.False(t, retryableStatus(200))
+	assert.False(t, retryableStatus(404))
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		require.True(t, b.allow())
+		b.recordResult(true)
+	}
+
+	assert.False(t, b.allow(), "breaker should be open after threshold consecutive failures")
+}
+
+func TestCircuitBreakerClosesOnSuccess(t *testing.T) {
+	b := newCircuitBreaker(2, time.Hour)
+
+	require.True(t, b.allow())
+	b.recordResult(true)
+	require.True(t, b.allow())
+	b.recordResult(false) // success resets the failure count
+
+	require.True(t, b.allow())
+	b.recordResult(true)
+	require.True(t, b.allow())
+	b.recordResult(true)
+
+	assert.False(t, b.allow(), "two consecutive failures after the reset should reopen it")
+}
+
+// TestCircuitBreakerHalfOpenAllowsOnlyOneProbe simulates a burst of
+// concurrent callers arriving right as an open breaker's openFor elapses:
+// only one of them should see allow() return true for the half-open probe,
+// not all of them.
+func TestCircuitBreakerHalfOpenAllowsOnlyOneProbe(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+
+	require.True(t, b.allow())
+	b.recordResult(true) // opens the breaker
+
+	time.Sleep(20 * time.Millisecond) // past openFor
+
+	const callers = 50
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer wg.Done()
+			if b.allow() {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), allowed.Load(), "exactly one concurrent caller should win the half-open probe")
+}
+
+func TestCircuitBreakerHalfOpenRecovery(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+
+	require.True(t, b.allow())
+	b.recordResult(true) // opens
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.True(t, b.allow(), "probe should be let through once openFor elapses")
+	b.recordResult(false) // probe succeeds, breaker closes
+
+	assert.True(t, b.allow(), "breaker should be closed again after a successful probe")
+}
+
+// TestCircuitBreakerReopensAfterLostProbe simulates the winning half-open
+// caller never calling recordResult at all (e.g. its context was canceled
+// between allow() and the request completing). Without a probe deadline,
+// the breaker would stay in circuitHalfOpen - and therefore allow()
+// returning false for everyone - forever.
+func TestCircuitBreakerReopensAfterLostProbe(t *testing.T) {
+	b := newCircuitBreaker(1, 10*time.Millisecond)
+
+	require.True(t, b.allow())
+	b.recordResult(true) // opens
+
+	time.Sleep(20 * time.Millisecond)
+	require.True(t, b.allow(), "probe should be let through once openFor elapses")
+	// The probe's caller is never heard from again: no recordResult call.
+
+	assert.False(t, b.allow(), "breaker should still reject while the probe's deadline hasn't elapsed")
+
+	time.Sleep(20 * time.Millisecond) // past probeDeadline
+	assert.False(t, b.allow(), "a lost probe reopens the breaker rather than wedging it, so this call starts a fresh openFor wait")
+
+	time.Sleep(20 * time.Millisecond) // past the fresh openFor
+	assert.True(t, b.allow(), "breaker should offer a new probe once it has reopened and openFor elapses again")
+}
@@ -0,0 +1,86 @@
+package tailscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+)
+
+// PeerRoutes holds one node's advertised and accepted subnet routes, as
+// reported by `tailscale status --json`.
+type PeerRoutes struct {
+	PeerID           string         `json:"peerId"`
+	Name             string         `json:"name"`
+	AdvertisedRoutes []netip.Prefix `json:"advertisedRoutes,omitempty"`
+	AcceptedRoutes   []netip.Prefix `json:"acceptedRoutes,omitempty"`
+}
+
+// statusPeerJSON mirrors the subset of `tailscale status --json` peer
+// fields this package cares about (field names match ipnstate.PeerStatus;
+// see testfake.Peer for the fake binary's equivalent). AllowedIPs is the
+// route set a peer advertises; PrimaryRoutes is the subset of those actually
+// accepted and in use.
+type statusPeerJSON struct {
+	ID            string   `json:"ID"`
+	HostName      string   `json:"HostName"`
+	DNSName       string   `json:"DNSName"`
+	AllowedIPs    []string `json:"AllowedIPs"`
+	PrimaryRoutes []string `json:"PrimaryRoutes"`
+}
+
+type statusJSON struct {
+	Self *statusPeerJSON            `json:"Self"`
+	Peer map[string]*statusPeerJSON `json:"Peer"`
+}
+
+// GetRouteInfo returns the local node's and every peer's advertised and
+// accepted subnet routes, parsed from `tailscale status --json`.
+func (c *TailscaleCLI) GetRouteInfo(ctx context.Context) ([]PeerRoutes, error) {
+	output, err := c.ExecuteCommand(ctx, []string{"status", "--json"}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var status statusJSON
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse status JSON: %w", err)
+	}
+
+	var routes []PeerRoutes
+	if status.Self != nil {
+		routes = append(routes, peerRoutesFrom(status.Self))
+	}
+	for _, peer := range status.Peer {
+		routes = append(routes, peerRoutesFrom(peer))
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].PeerID < routes[j].PeerID })
+
+	return routes, nil
+}
+
+func peerRoutesFrom(p *statusPeerJSON) PeerRoutes {
+	name := p.HostName
+	if name == "" {
+		name = p.DNSName
+	}
+	return PeerRoutes{
+		PeerID:           p.ID,
+		Name:             name,
+		AdvertisedRoutes: parsePrefixes(p.AllowedIPs),
+		AcceptedRoutes:   parsePrefixes(p.PrimaryRoutes),
+	}
+}
+
+func parsePrefixes(raw []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, s := range raw {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			continue
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes
+}
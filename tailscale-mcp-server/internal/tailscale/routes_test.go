@@ -0,0 +1,40 @@
+package tailscale
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerRoutesFrom(t *testing.T) {
+	t.Parallel()
+
+	peer := &statusPeerJSON{
+		ID:            "peer1",
+		HostName:      "router1",
+		AllowedIPs:    []string{"10.0.0.0/24", "fd7a:115c::/48", "not-a-prefix"},
+		PrimaryRoutes: []string{"10.0.0.0/24"},
+	}
+
+	got := peerRoutesFrom(peer)
+
+	assert.Equal(t, "peer1", got.PeerID)
+	assert.Equal(t, "router1", got.Name)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("fd7a:115c::/48"),
+	}, got.AdvertisedRoutes)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}, got.AcceptedRoutes)
+}
+
+func TestPeerRoutesFrom_FallsBackToDNSName(t *testing.T) {
+	t.Parallel()
+
+	peer := &statusPeerJSON{ID: "peer2", DNSName: "peer2.tailnetxyz.ts.net."}
+	got := peerRoutesFrom(peer)
+
+	assert.Equal(t, "peer2.tailnetxyz.ts.net.", got.Name)
+	assert.Empty(t, got.AdvertisedRoutes)
+	assert.Empty(t, got.AcceptedRoutes)
+}
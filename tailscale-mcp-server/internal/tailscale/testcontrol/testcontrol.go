@@ -0,0 +1,568 @@
+// Package testcontrol provides a minimal in-process fake of the Tailscale
+// control plane's HTTP surface, for hermetic integration tests that need a
+// real registration round-trip without touching actual Tailscale
+// infrastructure. It is modeled on tailscale.com's own
+// tstest/integration/testcontrol, but deliberately scoped down to what this
+// repo's CLI wrapper needs to exercise: node registration, netmap
+// generation, and an optional RequireAuth gate. It does not implement the
+// noise-encrypted wire protocol a real tailscaled speaks to control; it
+// exchanges plain JSON, which is enough to drive the programmable fake
+// `tailscale` binary in package testfake end-to-end.
+//
+// The same Server also answers a scoped-down slice of the v2 management
+// REST API (devices, ACL, auth keys) under /api/v2, so a test can point
+// tailscale.APIClient at it via package testsupport and exercise
+// ListDevicesTool, ACLTool, and similar tools against the same nodes
+// registered through the coordination protocol above.
+package testcontrol
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Node is a single machine registered with the control server.
+type Node struct {
+	ID         string   `json:"id"`
+	Key        string   `json:"key"`
+	Hostname   string   `json:"hostname"`
+	IPs        []string `json:"ips"`
+	Authorized bool     `json:"authorized"`
+}
+
+// RegisterRequest is the body a client posts to /machine/register.
+type RegisterRequest struct {
+	NodeKey  string `json:"nodeKey"`
+	Hostname string `json:"hostname"`
+
+	// AuthKey, when it names a still-valid preauthorized key issued via
+	// the v2 API's POST /tailnet/{tailnet}/keys (handleCreateAuthKey), lets
+	// a node skip RequireAuth the same way a real preauth key does.
+	AuthKey string `json:"authKey,omitempty"`
+}
+
+// RegisterResponse is the control server's reply to a register request.
+type RegisterResponse struct {
+	NodeKeyAuthorized bool   `json:"nodeKeyAuthorized"`
+	MachineAuthorized bool   `json:"machineAuthorized"`
+	AuthURL           string `json:"authURL,omitempty"`
+}
+
+// MapRequest is the body a client posts to /machine/map to fetch its netmap.
+type MapRequest struct {
+	NodeKey string `json:"nodeKey"`
+}
+
+// MapResponse is a deliberately simplified netmap: the requesting node's own
+// record plus every other authorized peer.
+type MapResponse struct {
+	Self  Node   `json:"self"`
+	Peers []Node `json:"peers"`
+}
+
+// Options configures a Server.
+type Options struct {
+	// RequireAuth makes newly registered nodes wait for an explicit
+	// Authorize call before MachineAuthorized flips true, mirroring a
+	// tailnet with "require additional approval for new devices" enabled.
+	// A node presenting a valid preauthorized AuthKey skips this wait, same
+	// as against a real control plane.
+	RequireAuth bool
+
+	// APIKey, when non-empty, is the bearer token the v2 REST API (/api/v2)
+	// requires via the Authorization header. Empty means the v2 API is
+	// unauthenticated, which is fine for tests that don't care about that
+	// specifically.
+	APIKey string
+}
+
+// defaultACLPolicy seeds a fresh Server's ACL so GetACL has something to
+// return before any test calls SetACL.
+const defaultACLPolicy = `{
+	// Default-allow policy, seeded by testcontrol.
+	"acls": [
+		{"action": "accept", "src": ["*"], "dst": ["*:*"]},
+	],
+}
+`
+
+// Server is a minimal in-process Tailscale control server. It implements
+// http.Handler so tests can wire it up with httptest.NewServer.
+type Server struct {
+	// ControlKey is a per-instance key generated at startup, standing in
+	// for the control plane's long-term key; nothing here validates a real
+	// noise handshake against it, but tests can assert a client observed it.
+	ControlKey string
+
+	requireAuth bool
+	apiKey      string
+
+	mu         sync.Mutex
+	nodes      map[string]*Node
+	aclText    string
+	aclETag    string
+	authKeys   map[string]*authKeyState
+	dnsRecords []SetDNSRequest
+}
+
+// authKeyState is what a created-but-not-yet-consumed auth key needs to
+// gate a registration: whether it authorizes the node outright
+// (preauthorized) and whether it survives being used more than once.
+type authKeyState struct {
+	reusable      bool
+	preauthorized bool
+}
+
+// NewServer creates a Server with the given options.
+func NewServer(opts Options) *Server {
+	return &Server{
+		ControlKey:  generateControlKey(),
+		requireAuth: opts.RequireAuth,
+		apiKey:      opts.APIKey,
+		nodes:       make(map[string]*Node),
+		aclText:     defaultACLPolicy,
+		aclETag:     generateControlKey(),
+		authKeys:    make(map[string]*authKeyState),
+	}
+}
+
+func generateControlKey() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand failing is effectively unrecoverable, but a fixed
+		// fallback keeps test servers usable rather than panicking.
+		return "0000000000000000000000000000000000"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ServeHTTP dispatches the handful of control endpoints this fake
+// understands.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/key":
+		s.handleKey(w, r)
+	case r.URL.Path == "/machine/register":
+		s.handleRegister(w, r)
+	case r.URL.Path == "/machine/map":
+		s.handleMap(w, r)
+	case r.URL.Path == "/machine/set-dns":
+		s.handleSetDNS(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/v2/"):
+		s.handleAPIV2(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// keyResponse is the control server's reply to GET /key: just enough of the
+// real endpoint's shape (a control key and the noise protocol versions it
+// claims to speak) for a client to notice it answered at all. This fake
+// doesn't implement the noise-encrypted wire protocol those versions
+// describe; see the package doc comment.
+type keyResponse struct {
+	ControlKey    string `json:"controlKey"`
+	NoiseVersions []int  `json:"noiseVersions"`
+}
+
+func (s *Server) handleKey(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, keyResponse{ControlKey: s.ControlKey, NoiseVersions: []int{}})
+}
+
+// SetDNSRequest is the body a client posts to /machine/set-dns.
+type SetDNSRequest struct {
+	NodeKey string `json:"nodeKey"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Value   string `json:"value"`
+}
+
+func (s *Server) handleSetDNS(w http.ResponseWriter, r *http.Request) {
+	var req SetDNSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("bad set-dns request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	if _, ok := s.nodes[req.NodeKey]; !ok {
+		s.mu.Unlock()
+		http.Error(w, "unknown node", http.StatusForbidden)
+		return
+	}
+	s.dnsRecords = append(s.dnsRecords, req)
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]bool{"ok": true})
+}
+
+// DNSRecords returns every /machine/set-dns request recorded so far, so
+// tests can assert on DNS record pushes made via the node.
+func (s *Server) DNSRecords() []SetDNSRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make([]SetDNSRequest, len(s.dnsRecords))
+	copy(records, s.dnsRecords)
+	return records
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("bad register request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.NodeKey == "" {
+		http.Error(w, "nodeKey is required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	node, exists := s.nodes[req.NodeKey]
+	if !exists {
+		authorizedByKey := s.consumeAuthKeyLocked(req.AuthKey)
+		node = &Node{
+			ID:         fmt.Sprintf("node-%d", len(s.nodes)+1),
+			Key:        req.NodeKey,
+			Hostname:   req.Hostname,
+			IPs:        []string{fmt.Sprintf("100.64.0.%d", len(s.nodes)+1)},
+			Authorized: !s.requireAuth || authorizedByKey,
+		}
+		s.nodes[req.NodeKey] = node
+	}
+	authorized := node.Authorized
+	nodeID := node.ID
+	s.mu.Unlock()
+
+	resp := RegisterResponse{
+		NodeKeyAuthorized: true,
+		MachineAuthorized: authorized,
+	}
+	if !authorized {
+		resp.AuthURL = fmt.Sprintf("https://testcontrol.invalid/auth/%s", nodeID)
+	}
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleMap(w http.ResponseWriter, r *http.Request) {
+	var req MapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("bad map request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	self, ok := s.nodes[req.NodeKey]
+	if !ok {
+		s.mu.Unlock()
+		http.Error(w, "unknown node", http.StatusForbidden)
+		return
+	}
+	if !self.Authorized {
+		s.mu.Unlock()
+		http.Error(w, "node not authorized", http.StatusForbidden)
+		return
+	}
+
+	peers := make([]Node, 0, len(s.nodes)-1)
+	for key, n := range s.nodes {
+		if key == req.NodeKey || !n.Authorized {
+			continue
+		}
+		peers = append(peers, *n)
+	}
+	selfCopy := *self
+	s.mu.Unlock()
+
+	writeJSON(w, MapResponse{Self: selfCopy, Peers: peers})
+}
+
+// Authorize marks a previously registered node as authorized, simulating an
+// operator visiting the AuthURL a RequireAuth-mode register returned. It
+// reports false if the node hasn't registered yet.
+func (s *Server) Authorize(nodeKey string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	node, ok := s.nodes[nodeKey]
+	if !ok {
+		return false
+	}
+	node.Authorized = true
+	return true
+}
+
+// Nodes returns a snapshot of every node registered so far.
+func (s *Server) Nodes() []Node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes := make([]Node, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		nodes = append(nodes, *n)
+	}
+	return nodes
+}
+
+// consumeAuthKeyLocked reports whether authKey names a still-valid
+// preauthorized key issued via handleCreateAuthKey, consuming it unless
+// it's marked reusable. Must be called with s.mu held.
+func (s *Server) consumeAuthKeyLocked(authKey string) bool {
+	if authKey == "" {
+		return false
+	}
+	key, ok := s.authKeys[authKey]
+	if !ok || !key.preauthorized {
+		return false
+	}
+	if !key.reusable {
+		delete(s.authKeys, authKey)
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// v2 REST API (/api/v2)
+////////////////////////////////////////////////////////////////////////////////
+
+// APIDevice is the handful of tailscale.Device fields this fake reports for
+// a registered Node, in the v2 API's device list/get shape.
+type APIDevice struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Hostname   string   `json:"hostname"`
+	OS         string   `json:"os"`
+	Addresses  []string `json:"addresses"`
+	Authorized bool     `json:"authorized"`
+}
+
+func apiDeviceFromNode(n *Node) APIDevice {
+	return APIDevice{
+		ID:         n.ID,
+		Name:       n.Hostname,
+		Hostname:   n.Hostname,
+		OS:         "linux",
+		Addresses:  n.IPs,
+		Authorized: n.Authorized,
+	}
+}
+
+// handleAPIV2 dispatches the subset of the v2 management API this fake
+// answers, keyed off path segments since the tailnet name is caller-chosen.
+func (s *Server) handleAPIV2(w http.ResponseWriter, r *http.Request) {
+	if !s.checkAPIKey(w, r) {
+		return
+	}
+
+	segs := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v2"), "/"), "/")
+
+	switch {
+	case len(segs) == 2 && segs[0] == "device":
+		s.handleGetDevice(w, segs[1])
+	case len(segs) == 3 && segs[0] == "tailnet" && segs[2] == "devices":
+		s.handleListDevices(w)
+	case len(segs) == 3 && segs[0] == "tailnet" && segs[2] == "acl":
+		s.handleACL(w, r)
+	case len(segs) == 4 && segs[0] == "tailnet" && segs[2] == "acl" && segs[3] == "validate":
+		s.handleACLValidate(w, r)
+	case len(segs) == 4 && segs[0] == "tailnet" && segs[2] == "acl" && segs[3] == "preview":
+		s.handleACLPreview(w, r)
+	case len(segs) == 3 && segs[0] == "tailnet" && segs[2] == "keys":
+		s.handleCreateAuthKey(w, r)
+	case len(segs) == 2 && segs[0] == "tailnet":
+		s.handleTailnetInfo(w, segs[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// checkAPIKey enforces Options.APIKey as a bearer token, when configured. It
+// writes the API's standard error envelope and reports false on failure, so
+// callers can just `return` on a false result.
+func (s *Server) checkAPIKey(w http.ResponseWriter, r *http.Request) bool {
+	if s.apiKey == "" {
+		return true
+	}
+	if r.Header.Get("Authorization") == "Bearer "+s.apiKey {
+		return true
+	}
+	writeAPIError(w, http.StatusUnauthorized, "unauthorized")
+	return false
+}
+
+func (s *Server) handleListDevices(w http.ResponseWriter) {
+	s.mu.Lock()
+	devices := make([]APIDevice, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		devices = append(devices, apiDeviceFromNode(n))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{"devices": devices})
+}
+
+func (s *Server) handleGetDevice(w http.ResponseWriter, deviceID string) {
+	s.mu.Lock()
+	var found *Node
+	for _, n := range s.nodes {
+		if n.ID == deviceID {
+			found = n
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if found == nil {
+		writeAPIError(w, http.StatusNotFound, "device not found")
+		return
+	}
+	writeJSON(w, apiDeviceFromNode(found))
+}
+
+func (s *Server) handleTailnetInfo(w http.ResponseWriter, tailnet string) {
+	writeJSON(w, map[string]any{
+		"name":      tailnet,
+		"accountId": "test-account",
+		"createdAt": time.Unix(0, 0).UTC(),
+	})
+}
+
+func (s *Server) handleACL(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		text, etag := s.aclText, s.aclETag
+		s.mu.Unlock()
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/hujson")
+		_, _ = io.WriteString(w, text)
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("reading body: %v", err))
+			return
+		}
+
+		s.mu.Lock()
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != s.aclETag {
+			s.mu.Unlock()
+			writeAPIError(w, http.StatusPreconditionFailed, "ACL has changed since last fetch")
+			return
+		}
+		s.aclText = string(body)
+		s.aclETag = generateControlKey()
+		etag := s.aclETag
+		s.mu.Unlock()
+
+		w.Header().Set("ETag", etag)
+		writeJSON(w, map[string]string{"message": "ACL updated"})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleACLValidate answers both ACL shapes the real /acl/validate
+// endpoint accepts: a full policy document, or a JSON array of ACL test
+// entries run against the currently stored policy. This fake doesn't
+// actually evaluate either one; it just requires a non-empty body, which is
+// enough to exercise the request/response plumbing tools rely on.
+func (s *Server) handleACLValidate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("reading body: %v", err))
+		return
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "empty ACL validation request")
+		return
+	}
+
+	writeJSON(w, map[string]string{"message": "ok"})
+}
+
+// handleACLPreview reports every registered node as a match, regardless of
+// previewFor or the submitted policy; it exists to exercise the
+// request/response flow PreviewACL drives, not to evaluate real policy.
+func (s *Server) handleACLPreview(w http.ResponseWriter, r *http.Request) {
+	if _, err := io.ReadAll(r.Body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("reading body: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	matches := make([]map[string]string, 0, len(s.nodes))
+	for _, n := range s.nodes {
+		matches = append(matches, map[string]string{"type": "node", "name": n.Hostname})
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]any{"matches": matches})
+}
+
+// createAuthKeyRequest mirrors the fields of tailscale.AuthKeyRequest this
+// fake actually looks at.
+type createAuthKeyRequest struct {
+	Capabilities struct {
+		Devices struct {
+			Create struct {
+				Reusable      bool `json:"reusable"`
+				Preauthorized bool `json:"preauthorized"`
+			} `json:"create"`
+		} `json:"devices"`
+	} `json:"capabilities"`
+	Description string `json:"description"`
+}
+
+// handleCreateAuthKey issues a key a test can hand to testfake.Options so a
+// subsequent `up --authkey=...` registers preauthorized, letting
+// RequireAuth-mode tests exercise the pre-auth handoff instead of always
+// calling Authorize directly.
+func (s *Server) handleCreateAuthKey(w http.ResponseWriter, r *http.Request) {
+	var req createAuthKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("bad key request: %v", err))
+		return
+	}
+
+	key := "tskey-auth-" + generateControlKey()
+
+	s.mu.Lock()
+	s.authKeys[key] = &authKeyState{
+		reusable:      req.Capabilities.Devices.Create.Reusable,
+		preauthorized: req.Capabilities.Devices.Create.Preauthorized,
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, map[string]string{
+		"id":          key[len(key)-8:],
+		"key":         key,
+		"description": req.Description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeAPIError writes the v2 API's standard {"message": ...} error
+// envelope, matching what APIClient's request() helper expects to decode.
+func writeAPIError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
@@ -0,0 +1,373 @@
+package testfake
+
+// fakeMainSource is the source of the fake `tailscale` binary. It is kept
+// dependency-free (stdlib only) so it builds without the module's own
+// go.mod/dependencies being available on PATH.
+const fakeMainSource = `package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+type peer struct {
+	ID           string   ` + "`json:\"ID\"`" + `
+	HostName     string   ` + "`json:\"HostName\"`" + `
+	DNSName      string   ` + "`json:\"DNSName\"`" + `
+	TailscaleIPs []string ` + "`json:\"TailscaleIPs\"`" + `
+	Online       bool     ` + "`json:\"Online\"`" + `
+	OS           string   ` + "`json:\"OS\"`" + `
+}
+
+type currentTailnet struct {
+	Name            string ` + "`json:\"Name\"`" + `
+	MagicDNSSuffix  string ` + "`json:\"MagicDNSSuffix\"`" + `
+	MagicDNSEnabled bool   ` + "`json:\"MagicDNSEnabled\"`" + `
+}
+
+type clientVersion struct {
+	RunningLatest bool ` + "`json:\"RunningLatest\"`" + `
+}
+
+type status struct {
+	Version        string          ` + "`json:\"Version\"`" + `
+	BackendState   string          ` + "`json:\"BackendState\"`" + `
+	Self           peer            ` + "`json:\"Self\"`" + `
+	Health         []string        ` + "`json:\"Health,omitempty\"`" + `
+	MagicDNSSuffix string          ` + "`json:\"MagicDNSSuffix,omitempty\"`" + `
+	CurrentTailnet *currentTailnet ` + "`json:\"CurrentTailnet,omitempty\"`" + `
+	Peer           map[string]peer ` + "`json:\"Peer,omitempty\"`" + `
+	ClientVersion  *clientVersion  ` + "`json:\"ClientVersion,omitempty\"`" + `
+}
+
+type receivedFile struct {
+	Name string ` + "`json:\"Name\"`" + `
+	Size int64  ` + "`json:\"Size\"`" + `
+}
+
+type state struct {
+	Self           *peer          ` + "`json:\"Self\"`" + `
+	Peers          []*peer        ` + "`json:\"Peers\"`" + `
+	MagicDNSSuffix string         ` + "`json:\"MagicDNSSuffix\"`" + `
+	Health         []string       ` + "`json:\"Health\"`" + `
+	Up             bool           ` + "`json:\"Up\"`" + `
+	Version        string         ` + "`json:\"Version\"`" + `
+	ReceivedFiles  []receivedFile ` + "`json:\"ReceivedFiles\"`" + `
+}
+
+type options struct {
+	State     state             ` + "`json:\"State\"`" + `
+	ExitCodes map[string]int    ` + "`json:\"ExitCodes\"`" + `
+	Errors    map[string]string ` + "`json:\"Errors\"`" + `
+	// NodeKey identifies this fake node to a testcontrol server when "up"
+	// is invoked with --login-server. Ignored otherwise.
+	NodeKey string ` + "`json:\"NodeKey\"`" + `
+}
+
+type invocation struct {
+	Argv  []string ` + "`json:\"argv\"`" + `
+	Env   []string ` + "`json:\"env\"`" + `
+	Stdin string   ` + "`json:\"stdin\"`" + `
+}
+
+// registerRequest/registerResponse/mapRequest/mapResponse mirror the JSON
+// wire shape of internal/tailscale/testcontrol, so this binary can register
+// against one without importing it (this source builds standalone).
+type registerRequest struct {
+	NodeKey  string ` + "`json:\"nodeKey\"`" + `
+	Hostname string ` + "`json:\"hostname\"`" + `
+	AuthKey  string ` + "`json:\"authKey,omitempty\"`" + `
+}
+
+type registerResponse struct {
+	NodeKeyAuthorized bool   ` + "`json:\"nodeKeyAuthorized\"`" + `
+	MachineAuthorized bool   ` + "`json:\"machineAuthorized\"`" + `
+	AuthURL           string ` + "`json:\"authURL,omitempty\"`" + `
+}
+
+type mapRequest struct {
+	NodeKey string ` + "`json:\"nodeKey\"`" + `
+}
+
+type mapNode struct {
+	ID  string   ` + "`json:\"id\"`" + `
+	IPs []string ` + "`json:\"ips\"`" + `
+}
+
+type mapResponse struct {
+	Self mapNode ` + "`json:\"self\"`" + `
+}
+
+func main() {
+	var opts options
+	stateFile := os.Getenv("TESTFAKE_STATE_FILE")
+	if stateFile != "" {
+		if data, err := os.ReadFile(stateFile); err == nil {
+			_ = json.Unmarshal(data, &opts)
+		}
+	}
+
+	stdin, _ := io.ReadAll(os.Stdin)
+	recordInvocation(stdin)
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "fake tailscale: no command specified")
+		os.Exit(1)
+	}
+	cmd := os.Args[1]
+
+	if msg, ok := opts.Errors[cmd]; ok {
+		fmt.Fprintln(os.Stderr, msg)
+		code := opts.ExitCodes[cmd]
+		if code == 0 {
+			code = 1
+		}
+		os.Exit(code)
+	}
+	if code, ok := opts.ExitCodes[cmd]; ok && code != 0 {
+		fmt.Fprintf(os.Stderr, "fake tailscale: %s failed\n", cmd)
+		os.Exit(code)
+	}
+
+	switch cmd {
+	case "version":
+		fmt.Println(versionOrDefault(opts.State.Version))
+	case "ip":
+		printIPs(opts.State.Self)
+	case "netcheck":
+		fmt.Println("Report:")
+		fmt.Println("\t* UDP: true")
+		fmt.Println("\t* Latency: 10ms")
+	case "ping":
+		fmt.Printf("pong from %s via fake\n", argOrDefault(os.Args, 2, "peer"))
+	case "up":
+		handleUp(os.Args[2:], &opts, stateFile)
+	case "down", "logout", "set", "share":
+		// "share" (add/remove/rename/list) isn't modeled; it succeeds and is
+		// recorded like "set", which is enough for tests asserting on argv.
+		if cmd == "down" {
+			opts.State.Up = false
+			saveState(stateFile, opts)
+		}
+	case "file":
+		handleFile(os.Args[2:], opts)
+	case "status":
+		printStatus(opts.State)
+	default:
+		fmt.Fprintf(os.Stderr, "fake tailscale: unsupported command %q\n", cmd)
+		os.Exit(1)
+	}
+}
+
+// handleFile handles "file cp" (a no-op success, mirroring "set") and "file
+// get" (prints opts.State.ReceivedFiles in the real CLI's "<name>, <size>
+// bytes" line format).
+func handleFile(args []string, opts options) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "fake tailscale: file: missing subcommand")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "cp":
+		// no-op
+	case "get":
+		for _, f := range opts.State.ReceivedFiles {
+			fmt.Printf("%s, %d bytes\n", f.Name, f.Size)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "fake tailscale: file: unsupported subcommand %q\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// handleUp brings the fake node up. With no --login-server it just flips
+// State.Up, matching the old stub behavior. With --login-server it registers
+// against a testcontrol server over plain HTTP, printing an auth URL and
+// leaving State.Up false until the node has been authorized there.
+func handleUp(args []string, opts *options, stateFile string) {
+	loginServer := flagValue(args, "--login-server")
+	if loginServer == "" {
+		opts.State.Up = true
+		saveState(stateFile, *opts)
+		fmt.Println("Success.")
+		return
+	}
+
+	nodeKey := opts.NodeKey
+	if nodeKey == "" {
+		nodeKey = "nodekey:testfake"
+	}
+	hostname := "test-self"
+	if opts.State.Self != nil && opts.State.Self.HostName != "" {
+		hostname = opts.State.Self.HostName
+	}
+
+	var regResp registerResponse
+	regReq := registerRequest{NodeKey: nodeKey, Hostname: hostname, AuthKey: os.Getenv("TS_AUTHKEY")}
+	if err := postJSON(loginServer+"/machine/register", regReq, &regResp); err != nil {
+		fmt.Fprintf(os.Stderr, "fake tailscale: register failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !regResp.MachineAuthorized {
+		fmt.Printf("To authenticate, visit:\n\n\t%s\n\n", regResp.AuthURL)
+		opts.State.Up = false
+		opts.State.Health = []string{"needs-login: visit " + regResp.AuthURL}
+		saveState(stateFile, *opts)
+		return
+	}
+
+	var mapResp mapResponse
+	if err := postJSON(loginServer+"/machine/map", mapRequest{NodeKey: nodeKey}, &mapResp); err != nil {
+		fmt.Fprintf(os.Stderr, "fake tailscale: map failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts.State.Up = true
+	opts.State.Health = nil
+	opts.State.Self = &peer{
+		ID:           mapResp.Self.ID,
+		HostName:     hostname,
+		TailscaleIPs: mapResp.Self.IPs,
+		Online:       true,
+		OS:           "linux",
+	}
+	saveState(stateFile, *opts)
+	fmt.Println("Success.")
+}
+
+func flagValue(args []string, name string) string {
+	for i, a := range args {
+		if a == name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func postJSON(url string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func saveState(stateFile string, opts options) {
+	if stateFile == "" {
+		return
+	}
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(stateFile, data, 0o644)
+}
+
+func recordInvocation(stdin []byte) {
+	recordFile := os.Getenv("TESTFAKE_RECORD_FILE")
+	if recordFile == "" {
+		return
+	}
+
+	inv := invocation{Argv: os.Args[1:], Env: os.Environ(), Stdin: string(stdin)}
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(recordFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, _ = f.Write(data)
+	_, _ = f.Write([]byte("\n"))
+}
+
+func versionOrDefault(v string) string {
+	if v == "" {
+		return "1.99.0-testfake"
+	}
+	return v
+}
+
+func printIPs(self *peer) {
+	if self != nil && len(self.TailscaleIPs) > 0 {
+		for _, ip := range self.TailscaleIPs {
+			fmt.Println(ip)
+		}
+		return
+	}
+	fmt.Println("100.64.0.1")
+}
+
+func argOrDefault(args []string, i int, def string) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return def
+}
+
+func printStatus(s state) {
+	self := peer{HostName: "test-self", TailscaleIPs: []string{"100.64.0.1"}, Online: true, OS: "linux"}
+	if s.Self != nil {
+		self = *s.Self
+	}
+
+	backendState := "Stopped"
+	if s.Up {
+		backendState = "Running"
+	}
+
+	st := status{
+		Version:        versionOrDefault(s.Version),
+		BackendState:   backendState,
+		Self:           self,
+		Health:         s.Health,
+		MagicDNSSuffix: s.MagicDNSSuffix,
+		ClientVersion:  &clientVersion{RunningLatest: true},
+	}
+
+	if s.MagicDNSSuffix != "" {
+		st.CurrentTailnet = &currentTailnet{
+			Name:            "test-tailnet." + s.MagicDNSSuffix,
+			MagicDNSSuffix:  s.MagicDNSSuffix,
+			MagicDNSEnabled: true,
+		}
+	}
+
+	if len(s.Peers) > 0 {
+		st.Peer = make(map[string]peer, len(s.Peers))
+		for i, p := range s.Peers {
+			if p == nil {
+				continue
+			}
+			st.Peer[fmt.Sprintf("peer%d", i)] = *p
+		}
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+`
@@ -0,0 +1,210 @@
+// Package testfake provides a programmable fake `tailscale` binary for
+// hermetic tests. Unlike a plain exit-1 stub, the fake binary is a small
+// compiled Go program that dispatches on os.Args[1] and returns canned
+// output driven by a State value, so tests can exercise success paths
+// (GetStatus parsing, Up/Down transitions) in addition to validation and
+// failure paths.
+//
+// The fake binary is compiled once per test binary run (not once per test)
+// to avoid paying the relink cost on every test, then copied into a
+// per-test PATH directory so each test can supply its own state and record
+// file without interfering with other tests.
+package testfake
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Peer describes a single node as returned by `tailscale status --json`.
+// Field names intentionally mirror pkg/schema.Peer so canned
+// output round-trips through the real schema once it is parsed.
+type Peer struct {
+	ID           string   `json:"ID"`
+	HostName     string   `json:"HostName"`
+	DNSName      string   `json:"DNSName"`
+	TailscaleIPs []string `json:"TailscaleIPs"`
+	Online       bool     `json:"Online"`
+	OS           string   `json:"OS"`
+}
+
+// State describes the tailnet state the fake binary should report.
+type State struct {
+	Self           *Peer          `json:"Self"`
+	Peers          []*Peer        `json:"Peers"`
+	MagicDNSSuffix string         `json:"MagicDNSSuffix"`
+	Health         []string       `json:"Health"`
+	Up             bool           `json:"Up"`
+	Version        string         `json:"Version"`
+	ReceivedFiles  []ReceivedFile `json:"ReceivedFiles"`
+}
+
+// ReceivedFile describes a single file `tailscale file get` should report as
+// waiting, mirroring the "<name>, <size> bytes" line the real CLI prints.
+type ReceivedFile struct {
+	Name string `json:"Name"`
+	Size int64  `json:"Size"`
+}
+
+// Options configures a fake binary instance.
+type Options struct {
+	State State `json:"State"`
+
+	// ExitCodes overrides the process exit code for a given subcommand
+	// (e.g. "up" -> 1 to simulate a daemon connection failure).
+	ExitCodes map[string]int `json:"ExitCodes"`
+
+	// Errors, when set for a subcommand, is written to stderr verbatim and
+	// causes a non-zero exit (1, unless ExitCodes also sets one).
+	Errors map[string]string `json:"Errors"`
+
+	// NodeKey identifies this fake node when "up" is called with
+	// --login-server, e.g. against a testcontrol.Server. Ignored otherwise.
+	NodeKey string `json:"NodeKey"`
+}
+
+// Invocation records a single call made against the fake binary.
+type Invocation struct {
+	Argv  []string `json:"argv"`
+	Env   []string `json:"env"`
+	Stdin string   `json:"stdin"`
+}
+
+// Handle gives a test access to the invocations recorded by a fake binary
+// installed via UseFakeTailscale.
+type Handle struct {
+	Dir        string
+	RecordPath string
+}
+
+// Invocations returns every invocation recorded so far, in call order.
+func (h *Handle) Invocations(t *testing.T) []Invocation {
+	t.Helper()
+
+	data, err := os.ReadFile(h.RecordPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+
+	var invocations []Invocation
+	for _, line := range splitNonEmptyLines(data) {
+		var inv Invocation
+		require.NoError(t, json.Unmarshal(line, &inv))
+		invocations = append(invocations, inv)
+	}
+	return invocations
+}
+
+var (
+	buildMu   sync.Mutex
+	builtPath string
+)
+
+// UseFakeTailscale builds (or reuses a previously built) fake `tailscale`
+// binary, installs it as the first entry on PATH for the duration of the
+// test, and configures it via opts. It returns a Handle that can be used to
+// inspect recorded invocations.
+func UseFakeTailscale(t *testing.T, opts Options) *Handle {
+	t.Helper()
+
+	builtBinary := buildFakeBinary(t)
+
+	tmpDir := t.TempDir()
+	binDir := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0o755))
+
+	installedBinary := filepath.Join(binDir, filepath.Base(builtBinary))
+	copyExecutable(t, builtBinary, installedBinary)
+
+	stateFile := filepath.Join(tmpDir, "options.json")
+	data, err := json.Marshal(opts)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(stateFile, data, 0o644))
+
+	recordFile := filepath.Join(tmpDir, "invocations.jsonl")
+
+	oldPath := os.Getenv("PATH")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+oldPath)
+	t.Setenv("TESTFAKE_STATE_FILE", stateFile)
+	t.Setenv("TESTFAKE_RECORD_FILE", recordFile)
+
+	return &Handle{Dir: tmpDir, RecordPath: recordFile}
+}
+
+// buildFakeBinary compiles the fake binary once per test binary run and
+// caches the resulting path; `go build` relinks cost ~600ms+, which is not
+// worth paying per test.
+func buildFakeBinary(t *testing.T) string {
+	t.Helper()
+
+	buildMu.Lock()
+	defer buildMu.Unlock()
+
+	if builtPath != "" {
+		if _, err := os.Stat(builtPath); err == nil {
+			return builtPath
+		}
+	}
+
+	srcDir, err := os.MkdirTemp("", "tailscale-testfake-src-")
+	require.NoError(t, err)
+
+	srcPath := filepath.Join(srcDir, "main.go")
+	require.NoError(t, os.WriteFile(srcPath, []byte(fakeMainSource), 0o644))
+
+	binName := "tailscale"
+	if runtime.GOOS == "windows" {
+		binName = "tailscale.exe"
+	}
+	outPath := filepath.Join(srcDir, binName)
+
+	cmd := exec.Command("go", "build", "-o", outPath, srcPath)
+	cmd.Dir = srcDir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Run(), "failed to build fake tailscale binary")
+
+	builtPath = outPath
+	return builtPath
+}
+
+func copyExecutable(t *testing.T, src, dst string) {
+	t.Helper()
+
+	in, err := os.Open(src)
+	require.NoError(t, err)
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	require.NoError(t, err)
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	require.NoError(t, err)
+}
+
+func splitNonEmptyLines(data []byte) [][]byte {
+	var lines [][]byte
+	start := 0
+	for i, b := range data {
+		if b == '\n' {
+			if i > start {
+				lines = append(lines, data[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		lines = append(lines, data[start:])
+	}
+	return lines
+}
@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package tailscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// awaitPollInterval and awaitTimeout bound how long AwaitRunning/AwaitIP poll
+// GetStatus before giving up; the fake control plane and fake `tailscale`
+// binary both respond immediately, so these exist only as a safety margin,
+// not to tolerate real network convergence time.
+const (
+	awaitPollInterval = 10 * time.Millisecond
+	awaitTimeout      = 5 * time.Second
+)
+
+// AwaitRunning polls cli.GetStatus until BackendState reports "Running",
+// failing the test if awaitTimeout elapses first.
+func AwaitRunning(t *testing.T, cli *TailscaleCLI) *schema.TailscaleStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(awaitTimeout)
+	for {
+		status, err := cli.GetStatus()
+		require.NoError(t, err)
+		if status.BackendState == "Running" {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("node did not reach Running within %s (last state: %s)", awaitTimeout, status.BackendState)
+		}
+		time.Sleep(awaitPollInterval)
+	}
+}
+
+// AwaitIP polls cli.GetStatus until the node reports at least one Tailscale
+// IP, failing the test if awaitTimeout elapses first. It returns that IP.
+func AwaitIP(t *testing.T, cli *TailscaleCLI) string {
+	t.Helper()
+
+	deadline := time.Now().Add(awaitTimeout)
+	for {
+		status, err := cli.GetStatus()
+		require.NoError(t, err)
+		if len(status.Self.TailscaleIPs) > 0 {
+			return status.Self.TailscaleIPs[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("node did not report a Tailscale IP within %s", awaitTimeout)
+		}
+		time.Sleep(awaitPollInterval)
+	}
+}
+
+// MustCleanShutdown brings cli down and asserts it reaches BackendState
+// "Stopped", failing the test otherwise.
+func MustCleanShutdown(t *testing.T, cli *TailscaleCLI) {
+	t.Helper()
+
+	require.NoError(t, cli.Down())
+
+	status, err := cli.GetStatus()
+	require.NoError(t, err)
+	require.Equal(t, "Stopped", status.BackendState)
+}
@@ -0,0 +1,77 @@
+// Package testsupport wires package testcontrol's fake control plane up as
+// an *tailscale.APIClient, so tests of tools that talk to the v2 management
+// API (ListDevicesTool, ACLTool, and similar) can drive real request/
+// response flows against an in-process server instead of needing a live
+// tailnet or API key. It deliberately lives outside package tailscale
+// itself: tailscale's own tests (e.g. integration_test.go, which already
+// depends on testcontrol) must never import this package, since that would
+// create an import cycle back through the APIClient it wires up.
+package testsupport
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/config"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/testcontrol"
+)
+
+// Options configures the fake control plane a Handle wraps.
+type Options struct {
+	// RequireAuth mirrors testcontrol.Options.RequireAuth.
+	RequireAuth bool
+
+	// APIKey is the bearer token the v2 REST API requires, and that API is
+	// configured with. Defaults to "test-api-key" when empty.
+	APIKey string
+
+	// Tailnet is the tailnet name the v2 REST API reports, and that API is
+	// configured with. Defaults to "test-tailnet" when empty.
+	Tailnet string
+}
+
+// Handle gives a test access to both layers of the fake control plane: the
+// coordination protocol a registered node (via testfake) speaks to Control,
+// and the v2 REST API the same server answers through API.
+type Handle struct {
+	// Control is the underlying fake control server, for node registration
+	// assertions (Nodes, Authorize) alongside API-level ones.
+	Control *testcontrol.Server
+
+	// Server is the httptest.Server hosting Control; its URL is what API
+	// was configured against.
+	Server *httptest.Server
+
+	// API is a tailscale.APIClient wired up against Server, ready to pass
+	// into any tool under test that takes one.
+	API *tailscale.APIClient
+}
+
+// Start spins up a fake control plane on a random port and returns a Handle
+// wired to it. The server is closed automatically via t.Cleanup.
+func Start(t *testing.T, opts Options) *Handle {
+	t.Helper()
+
+	if opts.APIKey == "" {
+		opts.APIKey = "test-api-key"
+	}
+	if opts.Tailnet == "" {
+		opts.Tailnet = "test-tailnet"
+	}
+
+	control := testcontrol.NewServer(testcontrol.Options{
+		RequireAuth: opts.RequireAuth,
+		APIKey:      opts.APIKey,
+	})
+	srv := httptest.NewServer(control)
+	t.Cleanup(srv.Close)
+
+	api := tailscale.NewAPIClient(&config.Config{
+		TailscaleAPIKey:     opts.APIKey,
+		TailscaleTailnet:    opts.Tailnet,
+		TailscaleAPIBaseURL: srv.URL,
+	})
+
+	return &Handle{Control: control, Server: srv, API: api}
+}
@@ -2,7 +2,9 @@
 package tailscale
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -31,6 +33,66 @@ type Device struct {
 	TailnetLockError          string              `json:"tailnetLockError,omitempty"`
 	TailnetLockKey            string              `json:"tailnetLockKey,omitempty"`
 	User                      string              `json:"user"`
+
+	// Capabilities holds this device's current node capability grants
+	// (capver 100+): peer capabilities, typically source-IP-scoped,
+	// produced by a "grants" rule's "app" map in the ACL policy. Keyed by
+	// capability name to its raw grant payload(s); use HasCapability or
+	// CapabilityValues rather than reading this directly. Populated from
+	// whichever of the API's "capabilities" (current) or "capMap" (older)
+	// response fields is present; see UnmarshalJSON.
+	Capabilities map[string][]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a Device, additionally populating Capabilities from
+// whichever of the API's "capabilities" or "capMap" fields the response
+// used (the API renamed the field; this repo's supported server versions
+// span both).
+func (d *Device) UnmarshalJSON(data []byte) error {
+	type alias Device
+	aux := struct {
+		*alias
+		CapMap       map[string][]json.RawMessage `json:"capMap,omitempty"`
+		Capabilities map[string][]json.RawMessage `json:"capabilities,omitempty"`
+	}{alias: (*alias)(d)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Capabilities != nil {
+		d.Capabilities = aux.Capabilities
+	} else {
+		d.Capabilities = aux.CapMap
+	}
+	return nil
+}
+
+// HasCapability reports whether the device currently holds a grant for the
+// named capability.
+func (d Device) HasCapability(name string) bool {
+	_, ok := d.Capabilities[name]
+	return ok
+}
+
+// CapabilityValues unmarshals every grant payload for the named capability
+// into T, e.g. CapabilityValues[MyCapStruct](d, "example.com/cap/database").
+// Returns an empty slice, not an error, if the device doesn't hold name.
+func CapabilityValues[T any](d Device, name string) ([]T, error) {
+	raw, ok := d.Capabilities[name]
+	if !ok {
+		return nil, nil
+	}
+
+	values := make([]T, 0, len(raw))
+	for _, r := range raw {
+		var v T
+		if err := json.Unmarshal(r, &v); err != nil {
+			return nil, fmt.Errorf("unmarshaling capability %q: %w", name, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
 }
 
 // String returns a human-readable representation of the device
@@ -170,6 +232,27 @@ type AuthKeyRequest struct {
 	Description   string              `json:"description,omitempty"`
 }
 
+// NewEphemeralAuthKeyRequest builds the AuthKeyRequest for a single-use,
+// pre-authorized, ephemeral key - the shape an OAuth client authorized for
+// only the devices:core scope can create via CreateAuthKey, without also
+// needing the broader admin scope a reusable or non-ephemeral key requires.
+func NewEphemeralAuthKeyRequest(description string, tags []string, expirySeconds int) AuthKeyRequest {
+	return AuthKeyRequest{
+		Description:   description,
+		ExpirySeconds: expirySeconds,
+		Capabilities: AuthKeyCapabilities{
+			Devices: AuthKeyDeviceCapabilities{
+				Create: AuthKeyDeviceCreateCapabilities{
+					Reusable:      false,
+					Ephemeral:     true,
+					Preauthorized: true,
+					Tags:          tags,
+				},
+			},
+		},
+	}
+}
+
 // DeviceListResponse represents the response from listing devices
 type DeviceListResponse struct {
 	Devices []Device `json:"devices"`
@@ -202,6 +285,78 @@ func (d DeviceListResponse) OnlineDevices() []Device {
 	return online
 }
 
+// HARouteGroup is a subnet route (CIDR) advertised by more than one device,
+// i.e. a highly-available subnet router pair/group. PrimaryDeviceID is the
+// device currently carrying the route (the one with it in EnabledRoutes);
+// the rest of DeviceIDs are standby routers advertising but not enabled for
+// it.
+type HARouteGroup struct {
+	CIDR            string   `json:"cidr"`
+	PrimaryDeviceID string   `json:"primaryDeviceId"`
+	DeviceIDs       []string `json:"deviceIds"`
+}
+
+// HARouteGroups returns, for every CIDR advertised by more than one device,
+// an HARouteGroup describing the redundant routers for it. The primary is
+// whichever device has the route in EnabledRoutes; if none (or more than
+// one) does, PrimaryDeviceID is left empty.
+func (d DeviceListResponse) HARouteGroups() []HARouteGroup {
+	byCIDR := make(map[string][]string)
+	for _, device := range d.Devices {
+		for _, cidr := range device.AdvertisedRoutes {
+			byCIDR[cidr] = append(byCIDR[cidr], device.ID)
+		}
+	}
+
+	var groups []HARouteGroup
+	for cidr, deviceIDs := range byCIDR {
+		if len(deviceIDs) < 2 {
+			continue
+		}
+
+		group := HARouteGroup{CIDR: cidr, DeviceIDs: deviceIDs}
+		for _, device := range d.Devices {
+			if containsString(device.EnabledRoutes, cidr) {
+				if group.PrimaryDeviceID != "" {
+					// More than one device has the route enabled; there's
+					// no single primary to report.
+					group.PrimaryDeviceID = ""
+					break
+				}
+				group.PrimaryDeviceID = device.ID
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].CIDR < groups[j].CIDR })
+	return groups
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// FailoverEvent records an automatic or simulated promotion of one HA
+// subnet router to primary in place of another.
+type FailoverEvent struct {
+	CIDR         string    `json:"cidr"`
+	FromDeviceID string    `json:"fromDeviceId"`
+	ToDeviceID   string    `json:"toDeviceId"`
+	Reason       string    `json:"reason"`
+	Time         time.Time `json:"time"`
+}
+
+// String returns a human-readable representation of the failover event.
+func (e FailoverEvent) String() string {
+	return fmt.Sprintf("Failover{CIDR: %s, From: %s, To: %s, Reason: %s}", e.CIDR, e.FromDeviceID, e.ToDeviceID, e.Reason)
+}
+
 // AuthKeyListResponse represents the response from listing auth keys
 type AuthKeyListResponse struct {
 	Keys []AuthKey `json:"keys"`
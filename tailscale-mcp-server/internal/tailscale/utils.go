@@ -42,6 +42,41 @@ func (l *limitWriter) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// lineWriter tees everything written to it through to dst unchanged, while
+// additionally invoking onLine once per complete line (stripped of its
+// trailing \n or \r\n) as soon as one appears, so a caller can observe a
+// long-running command's output incrementally instead of only once it
+// finishes and dst holds the whole thing.
+type lineWriter struct {
+	dst    io.Writer
+	onLine func(string)
+	buf    bytes.Buffer
+}
+
+func newLineWriter(dst io.Writer, onLine func(string)) *lineWriter {
+	return &lineWriter{dst: dst, onLine: onLine}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	w.buf.Write(p)
+	for {
+		line, readErr := w.buf.ReadString('\n')
+		if readErr != nil {
+			// Incomplete line: leave it buffered for the next Write.
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.onLine(strings.TrimRight(line, "\r\n"))
+	}
+	return n, nil
+}
+
 // getTailscaleFallbackPaths returns platform-specific fallback paths for the Tailscale binary
 func getTailscaleFallbackPaths() []string {
 	switch runtime.GOOS {
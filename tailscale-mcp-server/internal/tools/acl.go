@@ -3,10 +3,19 @@ package tools
 import (
 	"context"
 	"fmt"
+	"net/http"
 
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
 )
 
+// maxACLPreviewDevices bounds how many devices diffACL's who-is-affected
+// list runs the preview endpoint against, so one diff request against a
+// large tailnet doesn't turn into hundreds of API calls. When this cap is
+// hit, a warning is logged; the diff result itself does not say that the
+// list was cut short.
+const maxACLPreviewDevices = 25
+
 // ACLTool provides Access Control List management functionality
 type ACLTool struct {
 	cli *tailscale.TailscaleCLI
@@ -28,7 +37,7 @@ func (a *ACLTool) Name() string {
 
 // Description returns the tool description
 func (a *ACLTool) Description() string {
-	return "Access Control List management including viewing, updating, and validating ACL policies"
+	return "Access Control List management including viewing (as HuJSON or parsed acls/tests/autoApprovers/ssh/nodeAttrs sections), updating, validating, testing, diffing, and previewing reachability under a proposed ACL policy"
 }
 
 // InputSchema returns the JSON schema for tool input
@@ -39,23 +48,37 @@ func (a *ACLTool) InputSchema() map[string]interface{} {
 			"action": map[string]interface{}{
 				"type":        "string",
 				"description": "ACL action to perform",
-				"enum":        []string{"get", "set", "validate", "test"},
+				"enum":        []string{"get", "set", "validate", "test", "diff", "preview_match"},
 			},
 			"policy": map[string]interface{}{
 				"type":        "string",
-				"description": "ACL policy JSON for set operations",
+				"description": "ACL policy for set/validate/diff/preview_match operations, as JSON or HuJSON (comments and trailing commas are preserved)",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"description": "For get: \"json\" additionally parses the policy into its acls/tests/autoApprovers/ssh/nodeAttrs sections; anything else (including omitted) returns HuJSON only",
+				"enum":        []string{"hujson", "json"},
+			},
+			"ifMatch": map[string]interface{}{
+				"type":        "string",
+				"description": "ETag from a prior get/diff to make set (or diff's staleness check) fail instead of silently overwriting a concurrent edit",
 			},
 			"source": map[string]interface{}{
 				"type":        "string",
-				"description": "Source IP or user for ACL testing",
+				"description": "Source IP or user for ACL testing/preview_match",
 			},
 			"destination": map[string]interface{}{
 				"type":        "string",
-				"description": "Destination IP or service for ACL testing",
+				"description": "Destination IP or service for ACL testing/preview_match",
 			},
 			"port": map[string]interface{}{
 				"type":        "integer",
-				"description": "Port number for ACL testing",
+				"description": "Port number for ACL testing/preview_match",
+			},
+			"previewType": map[string]interface{}{
+				"type":        "string",
+				"description": "For preview_match: whether source identifies an IP (\"ipport\", the default) or a login name (\"user\")",
+				"enum":        []string{"ipport", "user"},
 			},
 		},
 		"required": []string{"action"},
@@ -71,10 +94,12 @@ func (a *ACLTool) Execute(ctx context.Context, input map[string]interface{}) (in
 
 	switch action {
 	case "get":
-		return a.getACL(ctx)
+		format, _ := input["format"].(string)
+		return a.getACL(ctx, format)
 	case "set":
 		policy, _ := input["policy"].(string)
-		return a.setACL(ctx, policy)
+		ifMatch, _ := input["ifMatch"].(string)
+		return a.setACL(ctx, policy, ifMatch)
 	case "validate":
 		policy, _ := input["policy"].(string)
 		return a.validateACL(ctx, policy)
@@ -83,43 +108,198 @@ func (a *ACLTool) Execute(ctx context.Context, input map[string]interface{}) (in
 		destination, _ := input["destination"].(string)
 		port, _ := input["port"].(float64)
 		return a.testACL(ctx, source, destination, int(port))
+	case "diff":
+		policy, _ := input["policy"].(string)
+		ifMatch, _ := input["ifMatch"].(string)
+		return a.diffACL(ctx, policy, ifMatch)
+	case "preview_match":
+		policy, _ := input["policy"].(string)
+		source, _ := input["source"].(string)
+		destination, _ := input["destination"].(string)
+		port, _ := input["port"].(float64)
+		previewType, _ := input["previewType"].(string)
+		return a.previewMatch(ctx, policy, source, destination, int(port), previewType)
 	default:
 		return nil, fmt.Errorf("unsupported action: %s", action)
 	}
 }
 
-func (a *ACLTool) getACL(ctx context.Context) (interface{}, error) {
+// getACL fetches the tailnet's current ACL. format is passed straight
+// through to GetPolicyFile; "json" additionally populates a parsed
+// ACLDocument alongside the raw HuJSON, anything else (including empty)
+// returns HuJSON only.
+func (a *ACLTool) getACL(ctx context.Context, format string) (interface{}, error) {
 	if a.api != nil {
-		acl := a.api.GetACL(ctx) // ← single result
-		return acl, nil
+		return a.api.GetPolicyFile(ctx, format), nil
 	}
 
 	return nil, fmt.Errorf("ACL retrieval not available – requires API access")
 }
 
-func (a *ACLTool) setACL(ctx context.Context, policy string) (interface{}, error) {
+func (a *ACLTool) setACL(ctx context.Context, policy, ifMatch string) (interface{}, error) {
 	if policy == "" {
 		return nil, fmt.Errorf("policy is required for set action")
 	}
+	if a.api == nil {
+		return nil, fmt.Errorf("ACL modification not available – requires API access")
+	}
+
+	resp := a.api.SetACL(ctx, policy, ifMatch)
+	if !resp.Success {
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return nil, fmt.Errorf("ACL changed since ifMatch was read, refusing to overwrite (status %d): %s", resp.StatusCode, resp.Error)
+		}
+		return nil, fmt.Errorf("failed to set ACL: %s", resp.Error)
+	}
 
-	// SetACL method not implemented in API client yet
-	return nil, fmt.Errorf("ACL modification not available - SetACL method not implemented")
+	return map[string]interface{}{
+		"success": true,
+		"etag":    resp.ETag,
+	}, nil
 }
 
 func (a *ACLTool) validateACL(ctx context.Context, policy string) (interface{}, error) {
 	if policy == "" {
 		return nil, fmt.Errorf("policy is required for validate action")
 	}
+	if a.api == nil {
+		return nil, fmt.Errorf("ACL validation not available – requires API access")
+	}
 
-	// ValidateACL method not implemented in API client yet
-	return nil, fmt.Errorf("ACL validation not available - ValidateACL method not implemented")
+	return a.api.ValidateACL(ctx, policy), nil
 }
 
 func (a *ACLTool) testACL(ctx context.Context, source, destination string, port int) (interface{}, error) {
 	if source == "" || destination == "" {
 		return nil, fmt.Errorf("source and destination are required for test action")
 	}
+	if a.api == nil {
+		return nil, fmt.Errorf("ACL testing not available – requires API access")
+	}
+
+	dst := destination
+	if port > 0 {
+		dst = fmt.Sprintf("%s:%d", destination, port)
+	}
+
+	return a.api.RunACLTests(ctx, []tailscale.ACLTestEntry{
+		{Src: source, Accept: []string{dst}},
+	}), nil
+}
+
+// diffACL fetches the current ACL, diffs it against policy, and reports
+// which devices' reachability the change would affect. ifMatch, if given,
+// must equal the fetched ETag, so a stale diff (computed against a policy
+// someone else has since replaced) is rejected rather than presented as
+// current.
+func (a *ACLTool) diffACL(ctx context.Context, policy, ifMatch string) (interface{}, error) {
+	if policy == "" {
+		return nil, fmt.Errorf("policy is required for diff action")
+	}
+	if a.api == nil {
+		return nil, fmt.Errorf("ACL diff not available – requires API access")
+	}
+
+	current := a.api.GetACL(ctx)
+	if !current.Success {
+		return nil, fmt.Errorf("failed to fetch current ACL: %s", current.Error)
+	}
+
+	if ifMatch != "" && ifMatch != current.ETag {
+		return nil, fmt.Errorf("ifMatch %q does not match current ACL etag %q; refetch before diffing", ifMatch, current.ETag)
+	}
+
+	diff := unifiedDiff("current", "proposed", current.Data.HuJSON, policy)
+
+	affected, err := a.whoIsAffected(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("computing who-is-affected: %w", err)
+	}
+
+	return map[string]interface{}{
+		"etag":          current.ETag,
+		"diff":          diff,
+		"changed":       diff != "",
+		"whoIsAffected": affected,
+	}, nil
+}
+
+// previewMatch reports whether source would be able to reach destination
+// (optionally :port, included for readability only - the preview endpoint
+// has no notion of ports, so it isn't part of the match itself) under
+// policy, a not-yet-committed proposed ACL. Unlike testACL, which runs an
+// acltest against the tailnet's *currently stored* policy, this previews
+// the *proposed* policy passed in, the same endpoint diffACL's
+// whoIsAffected uses but scoped to one source/destination pair instead of
+// every device.
+func (a *ACLTool) previewMatch(ctx context.Context, policy, source, destination string, port int, previewType string) (interface{}, error) {
+	if policy == "" || source == "" || destination == "" {
+		return nil, fmt.Errorf("policy, source, and destination are required for preview_match action")
+	}
+	if a.api == nil {
+		return nil, fmt.Errorf("ACL preview not available – requires API access")
+	}
+
+	preview := a.api.PreviewACL(ctx, policy, source, previewType)
+	if !preview.Success {
+		return nil, fmt.Errorf("failed to preview ACL: %s", preview.Error)
+	}
+
+	matched := false
+	for _, m := range preview.Data {
+		if m.Name == destination {
+			matched = true
+			break
+		}
+	}
+
+	dst := destination
+	if port > 0 {
+		dst = fmt.Sprintf("%s:%d", destination, port)
+	}
+
+	return map[string]interface{}{
+		"source":      source,
+		"destination": dst,
+		"matched":     matched,
+		"matches":     preview.Data,
+	}, nil
+}
+
+// whoIsAffected runs the preview endpoint against policy for each device's
+// primary address, up to maxACLPreviewDevices, reporting what it would be
+// able to reach if policy were committed.
+func (a *ACLTool) whoIsAffected(ctx context.Context, policy string) ([]map[string]interface{}, error) {
+	devices := a.api.ListDevices(ctx)
+	if !devices.Success {
+		return nil, fmt.Errorf("listing devices: %s", devices.Error)
+	}
+
+	deviceList := devices.Data.Devices
+	truncated := len(deviceList) > maxACLPreviewDevices
+	if truncated {
+		deviceList = deviceList[:maxACLPreviewDevices]
+	}
+
+	affected := make([]map[string]interface{}, 0, len(deviceList))
+	for _, device := range deviceList {
+		if len(device.Addresses) == 0 {
+			continue
+		}
+
+		preview := a.api.PreviewACL(ctx, policy, device.Addresses[0], "")
+		entry := map[string]interface{}{"device": device.Name}
+		if preview.Success {
+			entry["matches"] = preview.Data
+		} else {
+			entry["error"] = preview.Error
+		}
+		affected = append(affected, entry)
+	}
+
+	if truncated {
+		logger.Warn("ACL diff who-is-affected truncated", append([]any{"shown", len(deviceList), "total", devices.Data.Count()}, CorrelationFields(ctx)...)...)
+	}
 
-	// TestACL method not implemented in API client yet
-	return nil, fmt.Errorf("ACL testing not available - TestACL method not implemented")
+	return affected, nil
 }
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// unifiedDiff produces a minimal unified-style diff between from and to,
+// labeled fromLabel/toLabel, for ACLTool's "diff" action. It returns "" when
+// the two are identical. This isn't meant to match GNU diff's hunk-splitting
+// exactly, just to give a caller a readable summary of what a submitted ACL
+// policy would change, comments and all, since the inputs are the raw
+// HuJSON text rather than a parsed/normalized policy.
+func unifiedDiff(fromLabel, toLabel, from, to string) string {
+	ops := diffLines(strings.Split(from, "\n"), strings.Split(to, "\n"))
+	if !diffHasChanges(ops) {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n", fromLabel)
+	fmt.Fprintf(&b, "+++ %s\n", toLabel)
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			b.WriteString("  " + op.line + "\n")
+		case diffRemove:
+			b.WriteString("- " + op.line + "\n")
+		case diffAdd:
+			b.WriteString("+ " + op.line + "\n")
+		}
+	}
+	return b.String()
+}
+
+type diffKind int
+
+const (
+	diffEqual diffKind = iota
+	diffRemove
+	diffAdd
+)
+
+type diffOp struct {
+	kind diffKind
+	line string
+}
+
+// diffLines computes a line-level diff via the standard LCS dynamic
+// program. O(n*m) time and space, which is fine for ACL policy files
+// (typically well under a thousand lines).
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{diffEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{diffRemove, a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{diffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{diffRemove, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{diffAdd, b[j]})
+	}
+	return ops
+}
+
+func diffHasChanges(ops []diffOp) bool {
+	for _, op := range ops {
+		if op.kind != diffEqual {
+			return true
+		}
+	}
+	return false
+}
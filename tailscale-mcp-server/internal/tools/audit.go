@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// ToolAuditRecord is a single structured audit entry for one tool
+// invocation. Args is hashed rather than recorded verbatim: several tools
+// accept credentials or device identifiers an audit sink shouldn't have to
+// treat as sensitive on its own. RequestID and SessionID are omitted when
+// ctx carried none (e.g. stdio mode), so they let a record be correlated
+// with the MCP request and HTTP session it came from wherever those exist.
+type ToolAuditRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	CallerID   string    `json:"callerId"`
+	RequestID  string    `json:"requestId,omitempty"`
+	SessionID  string    `json:"sessionId,omitempty"`
+	Tool       string    `json:"tool"`
+	ArgsHash   string    `json:"argsHash"`
+	DurationMS int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// ToolAuditSink receives every record emitted by the audit logging
+// middleware, mirroring internal/tailscale/audit.Sink.
+type ToolAuditSink interface {
+	Write(ToolAuditRecord) error
+}
+
+// LoggerAuditSink is the default ToolAuditSink: it mirrors records to the
+// existing structured logger, Warn for failed invocations (the ones an
+// operator needs to notice) and Debug otherwise.
+type LoggerAuditSink struct {
+	logger logger.Service
+}
+
+// NewLoggerAuditSink creates a LoggerAuditSink logging through svc.
+func NewLoggerAuditSink(svc logger.Service) LoggerAuditSink {
+	return LoggerAuditSink{logger: svc}
+}
+
+func (s LoggerAuditSink) Write(rec ToolAuditRecord) error {
+	fields := []any{
+		"caller_id", rec.CallerID,
+		"tool", rec.Tool,
+		"args_hash", rec.ArgsHash,
+		"duration_ms", rec.DurationMS,
+	}
+	if rec.RequestID != "" {
+		fields = append(fields, "mcp_request_id", rec.RequestID)
+	}
+	if rec.SessionID != "" {
+		fields = append(fields, "session_id", rec.SessionID)
+	}
+	if rec.Error != "" {
+		fields = append(fields, "error", rec.Error)
+		s.logger.Warn("tool invocation failed", fields...)
+	} else {
+		s.logger.Debug("tool invocation succeeded", fields...)
+	}
+	return nil
+}
+
+// CorrelationFields returns structured logging fields (mcp_request_id,
+// session_id, caller_id) for a tool's own log calls to attach, matching
+// what auditLoggingMiddleware already records for the invocation as a
+// whole. Fields are omitted when unset, e.g. stdio mode never sets a
+// session ID.
+func CorrelationFields(ctx context.Context) []any {
+	var fields []any
+	if id := audit.RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, "mcp_request_id", id)
+	}
+	if id := audit.SessionIDFromContext(ctx); id != "" {
+		fields = append(fields, "session_id", id)
+	}
+	if id := tailscale.CallerIDFromContext(ctx); id != tailscale.UnknownCallerID {
+		fields = append(fields, "caller_id", id)
+	}
+	return fields
+}
+
+// hashArgs fingerprints args for ToolAuditRecord.ArgsHash without recording
+// the (possibly sensitive) arguments themselves.
+func hashArgs(args json.RawMessage) string {
+	sum := sha256.Sum256(args)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditLoggingMiddleware records a ToolAuditRecord to sink for every
+// invocation, attributing it to the caller identity attached via
+// tailscale.WithCallerID (falling back to "unknown" when none was set, same
+// as the TailscaleCLI audit trail).
+func auditLoggingMiddleware(sink ToolAuditSink) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			start := time.Now()
+			result, err := next(ctx, name, args)
+
+			rec := ToolAuditRecord{
+				Timestamp:  start,
+				CallerID:   tailscale.CallerIDFromContext(ctx),
+				RequestID:  audit.RequestIDFromContext(ctx),
+				SessionID:  audit.SessionIDFromContext(ctx),
+				Tool:       name,
+				ArgsHash:   hashArgs(args),
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				rec.Error = err.Error()
+			}
+			_ = sink.Write(rec)
+
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/authz"
+)
+
+// AuthorizationMiddleware checks every tool call against authorizer, keyed
+// by the caller's tailnet identity (tailscale.CallerIDFromContext, set once
+// per HTTP session from a WhoIs lookup — see internal/server/httpsession.go).
+// A denied call returns its error straight from ExecuteTool, same as any
+// other tool failure, so it surfaces to the client as an MCP-level error
+// rather than an HTTP status code.
+func AuthorizationMiddleware(authorizer authz.Authorizer) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			identity := tailscale.CallerIDFromContext(ctx)
+			if identity == tailscale.UnknownCallerID {
+				identity = ""
+			}
+			if err := authorizer.Authorize(ctx, identity, name, args); err != nil {
+				return "", err
+			}
+			return next(ctx, name, args)
+		}
+	}
+}
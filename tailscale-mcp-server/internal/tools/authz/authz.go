@@ -0,0 +1,54 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Authorizer decides whether identity (the caller's resolved Tailscale
+// identity, or "" if none could be resolved) may invoke tool with args.
+type Authorizer interface {
+	Authorize(ctx context.Context, identity, tool string, args json.RawMessage) error
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(ctx context.Context, identity, tool string, args json.RawMessage) error
+
+// Authorize calls f.
+func (f AuthorizerFunc) Authorize(ctx context.Context, identity, tool string, args json.RawMessage) error {
+	return f(ctx, identity, tool, args)
+}
+
+// Noop allows every call. It's the right default for stdio mode, where the
+// caller is already trusted (a single local MCP client), and for any
+// deployment with no policy file configured.
+var Noop Authorizer = AuthorizerFunc(func(context.Context, string, string, json.RawMessage) error {
+	return nil
+})
+
+// PolicyAuthorizer enforces a Policy loaded from a tools.policy.hujson
+// file, the tailnet-aware authorizer HTTP and tsnet modes wire up when a
+// policy file is configured.
+type PolicyAuthorizer struct {
+	policy *Policy
+}
+
+// NewPolicyAuthorizer wraps policy as an Authorizer.
+func NewPolicyAuthorizer(policy *Policy) *PolicyAuthorizer {
+	return &PolicyAuthorizer{policy: policy}
+}
+
+// Authorize denies the call unless policy has a rule granting identity
+// access to tool.
+func (a *PolicyAuthorizer) Authorize(ctx context.Context, identity, tool string, args json.RawMessage) error {
+	if a.policy.Allows(identity, tool) {
+		return nil
+	}
+	if identity == "" {
+		return fmt.Errorf("tool %q denied: caller's tailnet identity could not be resolved", tool)
+	}
+	return fmt.Errorf("tool %q denied: %q is not authorized to call it", tool, identity)
+}
+
+var _ Authorizer = (*PolicyAuthorizer)(nil)
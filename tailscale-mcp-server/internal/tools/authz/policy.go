@@ -0,0 +1,102 @@
+// Package authz implements tool-call authorization keyed by tailnet
+// identity, enforced by a HuJSON policy file (tools.policy.hujson) in the
+// same spirit as the ACL policy it sits alongside: a list of rules mapping
+// src (a literal tailnet user login or node name, exactly as resolveIdentity
+// reports it — no group or ACL tag expansion) to the tool names that
+// identity may invoke. LoadPolicyFile rejects a Src entry shaped like a
+// group or tag outright, rather than loading a rule that would silently
+// never match anyone.
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// Rule grants every entry in Src access to the tools listed in Tools. Src
+// entries are matched against the caller's identity by exact string
+// equality only — a user login or node name, not a group or ACL tag. "*"
+// in Tools matches any tool name.
+type Rule struct {
+	Src   []string `json:"src"`
+	Tools []string `json:"tools"`
+}
+
+// Policy is the parsed form of a tools.policy.hujson file.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadPolicyFile reads and parses path as a tool authorization policy.
+// HuJSON (comments, trailing commas) is accepted, matching the ACL policy
+// format tailnet admins already write by hand.
+func LoadPolicyFile(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tool policy file: %w", err)
+	}
+
+	std, err := hujson.Standardize(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing tool policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(std, &policy); err != nil {
+		return nil, fmt.Errorf("decoding tool policy file: %w", err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("validating tool policy file %s: %w", path, err)
+	}
+	return &policy, nil
+}
+
+// validate rejects a Policy containing a Src entry shaped like a tailnet
+// ACL group ("group:...") or tag ("tag:..."). Allows only ever does literal
+// string equality against a resolved login or node name, so a rule written
+// against a group or tag would parse cleanly and then silently never match
+// anyone - failing LoadPolicyFile here turns that gap into a loud error an
+// operator sees at startup, instead of a policy that looks like it grants
+// access but doesn't.
+func (p *Policy) validate() error {
+	for i, rule := range p.Rules {
+		for _, src := range rule.Src {
+			if strings.HasPrefix(src, "group:") || strings.HasPrefix(src, "tag:") {
+				return fmt.Errorf("rules[%d].src %q looks like an ACL group or tag, but Allows only matches a literal user login or node name - group/tag membership is not resolved", i, src)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether identity may call tool under p. identity is
+// matched against each rule's Src by exact string equality only (a group
+// name or ACL tag in Src will never match a resolved user login or node
+// name). An identity that matches no rule is denied, matching the ACL's
+// own default-deny posture.
+func (p *Policy) Allows(identity, tool string) bool {
+	for _, rule := range p.Rules {
+		if !containsString(rule.Src, identity) {
+			continue
+		}
+		for _, allowed := range rule.Tools {
+			if allowed == "*" || allowed == tool {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
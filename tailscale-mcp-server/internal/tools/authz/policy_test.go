@@ -0,0 +1,87 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicyFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tools.policy.hujson")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestPolicyAllowsExactLoginMatch(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Src: []string{"alice@example.com"}, Tools: []string{"list_devices"}},
+	}}
+
+	assert.True(t, policy.Allows("alice@example.com", "list_devices"))
+}
+
+func TestPolicyAllowsWildcardTool(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Src: []string{"alice@example.com"}, Tools: []string{"*"}},
+	}}
+
+	assert.True(t, policy.Allows("alice@example.com", "manage_device"))
+}
+
+// TestPolicyDoesNotResolveGroupsOrTags locks in that Src is matched by
+// exact string equality only: a rule written against a group or ACL tag
+// name never matches an individual caller's resolved login or node name,
+// since Allows performs no group/tag membership lookup.
+func TestPolicyDoesNotResolveGroupsOrTags(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Src: []string{"group:eng"}, Tools: []string{"list_devices"}},
+		{Src: []string{"tag:server"}, Tools: []string{"list_devices"}},
+	}}
+
+	assert.False(t, policy.Allows("alice@example.com", "list_devices"),
+		"a group rule must not match a member's resolved login")
+	assert.False(t, policy.Allows("host-1", "list_devices"),
+		"a tag rule must not match a tagged node's resolved node name")
+}
+
+func TestPolicyDeniesUnmatchedIdentity(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Src: []string{"alice@example.com"}, Tools: []string{"list_devices"}},
+	}}
+
+	assert.False(t, policy.Allows("bob@example.com", "list_devices"))
+	assert.False(t, policy.Allows("", "list_devices"))
+}
+
+func TestPolicyDeniesToolNotListed(t *testing.T) {
+	policy := &Policy{Rules: []Rule{
+		{Src: []string{"alice@example.com"}, Tools: []string{"list_devices"}},
+	}}
+
+	assert.False(t, policy.Allows("alice@example.com", "manage_device"))
+}
+
+func TestLoadPolicyFileAcceptsLiteralSrc(t *testing.T) {
+	path := writePolicyFile(t, `{"rules":[{"src":["alice@example.com"],"tools":["*"]}]}`)
+
+	policy, err := LoadPolicyFile(path)
+	require.NoError(t, err)
+	assert.True(t, policy.Allows("alice@example.com", "list_devices"))
+}
+
+// TestLoadPolicyFileRejectsGroupOrTagSrc locks in that a group or tag
+// shaped Src entry fails loudly at load time, since Allows would otherwise
+// silently never match anyone for a rule written that way.
+func TestLoadPolicyFileRejectsGroupOrTagSrc(t *testing.T) {
+	for _, src := range []string{"group:eng", "tag:server"} {
+		path := writePolicyFile(t, `{"rules":[{"src":["`+src+`"],"tools":["*"]}]}`)
+
+		_, err := LoadPolicyFile(path)
+		require.Error(t, err, "src %q should be rejected", src)
+		assert.Contains(t, err.Error(), "group or tag")
+	}
+}
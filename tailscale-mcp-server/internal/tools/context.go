@@ -12,6 +12,7 @@ const (
 	registryKey  contextKey = "toolRegistry"
 	apiClientKey contextKey = "apiClient"
 	cliClientKey contextKey = "cliClient"
+	clientKey    contextKey = "client"
 )
 
 // NewContext creates a new context with the tool registry and clients.
@@ -19,6 +20,7 @@ func NewContext(ctx context.Context, r *ToolRegistry) context.Context {
 	ctx = context.WithValue(ctx, registryKey, r)
 	ctx = context.WithValue(ctx, apiClientKey, r.api)
 	ctx = context.WithValue(ctx, cliClientKey, r.cli)
+	ctx = context.WithValue(ctx, clientKey, r.client)
 	return ctx
 }
 
@@ -39,3 +41,10 @@ func CLIClientFromContext(ctx context.Context) (*tailscale.TailscaleCLI, bool) {
 	cli, ok := ctx.Value(cliClientKey).(*tailscale.TailscaleCLI)
 	return cli, ok
 }
+
+// ClientFromContext retrieves the backend-agnostic Tailscale node client
+// (cli or localapi, per configuration) from the context.
+func ClientFromContext(ctx context.Context) (tailscale.Client, bool) {
+	client, ok := ctx.Value(clientKey).(tailscale.Client)
+	return client, ok
+}
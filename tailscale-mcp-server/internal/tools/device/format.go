@@ -0,0 +1,54 @@
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// formatDevice renders d as the multi-line, human-readable text every
+// device tool in this package returns. includeRoutes additionally prints
+// enabled/advertised subnet routes.
+func formatDevice(d tailscale.Device, includeRoutes bool) string {
+	authStatus := "authorized"
+	if !d.Authorized {
+		authStatus = "unauthorized"
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Device: %s (%s) - %s\n", d.Name, d.ID, authStatus)
+	fmt.Fprintf(&out, "  Hostname: %s, OS: %s, Version: %s", d.Hostname, d.OS, d.ClientVersion)
+	if d.UpdateAvailable {
+		out.WriteString(" (update available)")
+	}
+	out.WriteString("\n")
+	fmt.Fprintf(&out, "  Addresses: %s\n", strings.Join(d.Addresses, ", "))
+	fmt.Fprintf(&out, "  Created: %s, Last Seen: %s, Expires: %s\n",
+		d.Created.Format("2006-01-02 15:04:05"), d.LastSeen.Format("2006-01-02 15:04:05"), d.Expires.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&out, "  Key Expiry Disabled: %t, External: %t\n", d.KeyExpiryDisabled, d.IsExternal)
+	fmt.Fprintf(&out, "  Machine Key: %s, Node Key: %s\n", d.MachineKey, d.NodeKey)
+	if len(d.Tags) > 0 {
+		fmt.Fprintf(&out, "  Tags: %s\n", strings.Join(d.Tags, ", "))
+	}
+
+	if includeRoutes {
+		fmt.Fprintf(&out, "  Enabled Routes: %s\n", strings.Join(d.EnabledRoutes, ", "))
+		fmt.Fprintf(&out, "  Advertised Routes: %s\n", strings.Join(d.AdvertisedRoutes, ", "))
+	}
+
+	if cc := d.ClientConnectivity; cc != nil {
+		fmt.Fprintf(&out, "  Endpoints: %s\n", strings.Join(cc.Endpoints, ", "))
+		fmt.Fprintf(&out, "  DERP: %s, Mapping Varies By Dest IP: %t\n", cc.Derp, cc.MappingVariesByDestIP)
+		if len(cc.Latency) > 0 {
+			out.WriteString("  DERP Latency:\n")
+			for node, lat := range cc.Latency {
+				for region, seconds := range lat.DERPLatency {
+					fmt.Fprintf(&out, "    %s via %s: %.1fms\n", node, region, seconds*1000)
+				}
+			}
+		}
+	}
+
+	return out.String()
+}
@@ -5,14 +5,53 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
-	"github.com/hexsleeves/tailscale-mcp-server/internal/tools"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
 )
 
 // ListDevicesInput defines the input schema for the list_devices tool
 type ListDevicesInput struct {
 	IncludeRoutes bool `json:"includeRoutes" description:"Include route information for each device"`
+
+	// Filter parameters, ANDed together; all are optional and default to
+	// "no filter" when empty/zero.
+	Tag        string `json:"tag,omitempty" description:"Only include devices carrying this ACL tag (e.g. \"tag:server\")"`
+	OS         string `json:"os,omitempty" description:"Only include devices whose OS matches exactly, case-insensitive"`
+	OnlineOnly bool   `json:"onlineOnly,omitempty" description:"Only include devices seen within the last 5 minutes"`
+	StaleSince string `json:"staleSince,omitempty" description:"Only include devices last seen longer ago than this duration (e.g. \"72h\")"`
+}
+
+// matches reports whether d satisfies every filter set on input. staleSince,
+// if non-empty, must already have been validated by the caller.
+func (input ListDevicesInput) matches(d tailscale.Device, staleSince time.Duration, hasStaleSince bool) bool {
+	if input.Tag != "" {
+		tagged := false
+		for _, t := range d.Tags {
+			if strings.EqualFold(t, input.Tag) {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if input.OS != "" && !strings.EqualFold(d.OS, input.OS) {
+		return false
+	}
+
+	if input.OnlineOnly && !d.IsOnline() {
+		return false
+	}
+
+	if hasStaleSince && time.Since(d.LastSeen) < staleSince {
+		return false
+	}
+
+	return true
 }
 
 // ListDevicesTool is a tool for listing devices in a Tailscale tailnet.
@@ -34,7 +73,7 @@ func (t *ListDevicesTool) Name() string {
 
 // Description returns a description of the tool
 func (t *ListDevicesTool) Description() string {
-	return "Lists all devices in the tailnet, with an option to include route information."
+	return "Lists all devices in the tailnet, optionally filtered by tag, OS, online status, or staleness, with an option to include route information."
 }
 
 // InputSchema returns the input schema for the tool
@@ -49,31 +88,36 @@ func (t *ListDevicesTool) Execute(ctx context.Context, args json.RawMessage) (st
 		return "", fmt.Errorf("failed to unmarshal input: %w", err)
 	}
 
+	var staleSince time.Duration
+	hasStaleSince := input.StaleSince != ""
+	if hasStaleSince {
+		var err error
+		staleSince, err = time.ParseDuration(input.StaleSince)
+		if err != nil {
+			return "", fmt.Errorf("invalid staleSince %q: %w", input.StaleSince, err)
+		}
+	}
+
 	resp := t.Client.ListDevices(ctx)
 	if !resp.Success {
 		return "", fmt.Errorf("failed to list devices: %s", resp.Error)
 	}
 
 	var output strings.Builder
-	for _, device := range resp.Data.Devices {
-		authStatus := "authorized"
-		if !device.Authorized {
-			authStatus = "unauthorized"
-		}
-
-		output.WriteString(fmt.Sprintf("Device: %s (%s) - %s\n", device.Name, device.ID, authStatus))
-		output.WriteString(fmt.Sprintf("  OS: %s, Version: %s\n", device.OS, device.ClientVersion))
-		output.WriteString(fmt.Sprintf("  Addresses: %s\n", strings.Join(device.Addresses, ", ")))
-		output.WriteString(fmt.Sprintf("  Last Seen: %s\n", device.LastSeen.Format("2006-01-02 15:04:05")))
-
-		if input.IncludeRoutes {
-			output.WriteString(fmt.Sprintf("  Enabled Routes: %s\n", strings.Join(device.EnabledRoutes, ", ")))
-			output.WriteString(fmt.Sprintf("  Advertised Routes: %s\n", strings.Join(device.AdvertisedRoutes, ", ")))
+	matched := 0
+	for _, d := range resp.Data.Devices {
+		if !input.matches(d, staleSince, hasStaleSince) {
+			continue
 		}
+		matched++
+		output.WriteString(formatDevice(d, input.IncludeRoutes))
 		output.WriteString("\n")
 	}
 
+	if matched == 0 {
+		return "No devices matched the given filters.\n", nil
+	}
 	return output.String(), nil
 }
 
-var _ tools.Tool = (*ListDevicesTool)(nil)
+var _ toolapi.Tool = (*ListDevicesTool)(nil)
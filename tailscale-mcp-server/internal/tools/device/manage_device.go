@@ -0,0 +1,103 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// ManageDeviceInput defines the input schema for the manage_device tool.
+type ManageDeviceInput struct {
+	Action   string   `json:"action" description:"One of: authorize, deauthorize, delete, expire_key, set_tags, set_name"`
+	DeviceID string   `json:"deviceId" description:"The device ID to act on"`
+	Tags     []string `json:"tags,omitempty" description:"ACL tags to set (set_tags only)"`
+	Name     string   `json:"name,omitempty" description:"New tailnet hostname (set_name only)"`
+}
+
+// ManageDeviceTool performs administrative actions (authorize, deauthorize,
+// delete, expire a node key, set tags, rename) against a single device.
+type ManageDeviceTool struct {
+	Client *tailscale.APIClient
+}
+
+// NewManageDeviceTool creates a new ManageDeviceTool.
+func NewManageDeviceTool(client *tailscale.APIClient) *ManageDeviceTool {
+	return &ManageDeviceTool{
+		Client: client,
+	}
+}
+
+// Name returns the name of the tool
+func (t *ManageDeviceTool) Name() string {
+	return "manage_device"
+}
+
+// Description returns a description of the tool
+func (t *ManageDeviceTool) Description() string {
+	return "Authorizes, deauthorizes, deletes, expires the node key of, retags, or renames a single tailnet device."
+}
+
+// InputSchema returns the input schema for the tool
+func (t *ManageDeviceTool) InputSchema() any {
+	return ManageDeviceInput{}
+}
+
+// Execute runs the tool
+func (t *ManageDeviceTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input ManageDeviceInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	if input.DeviceID == "" {
+		return "", fmt.Errorf("deviceId is required")
+	}
+
+	switch input.Action {
+	case "authorize":
+		if resp := t.Client.SetDeviceAuthorized(ctx, input.DeviceID, true); !resp.Success {
+			return "", fmt.Errorf("failed to authorize device: %s", resp.Error)
+		}
+	case "deauthorize":
+		if resp := t.Client.SetDeviceAuthorized(ctx, input.DeviceID, false); !resp.Success {
+			return "", fmt.Errorf("failed to deauthorize device: %s", resp.Error)
+		}
+	case "delete":
+		resp := t.Client.DeleteDevice(ctx, input.DeviceID)
+		if !resp.Success {
+			return "", fmt.Errorf("failed to delete device: %s", resp.Error)
+		}
+		return fmt.Sprintf("Device %s deleted.\n", input.DeviceID), nil
+	case "expire_key":
+		if resp := t.Client.ExpireDeviceKey(ctx, input.DeviceID); !resp.Success {
+			return "", fmt.Errorf("failed to expire device key: %s", resp.Error)
+		}
+	case "set_tags":
+		if resp := t.Client.SetDeviceTags(ctx, input.DeviceID, input.Tags); !resp.Success {
+			return "", fmt.Errorf("failed to set device tags: %s", resp.Error)
+		}
+	case "set_name":
+		if input.Name == "" {
+			return "", fmt.Errorf("name is required for set_name")
+		}
+		if resp := t.Client.SetDeviceName(ctx, input.DeviceID, input.Name); !resp.Success {
+			return "", fmt.Errorf("failed to set device name: %s", resp.Error)
+		}
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+
+	// The admin API's mutation routes return no useful body of their own,
+	// so fetch the device fresh to report its post-mutation state.
+	resp := t.Client.GetDevice(ctx, input.DeviceID)
+	if !resp.Success {
+		return "", fmt.Errorf("%s succeeded but fetching updated device failed: %s", input.Action, resp.Error)
+	}
+
+	return formatDevice(resp.Data, true), nil
+}
+
+var _ toolapi.Tool = (*ManageDeviceTool)(nil)
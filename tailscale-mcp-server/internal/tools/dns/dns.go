@@ -0,0 +1,124 @@
+// Package dns exposes the tailnet's DNS configuration: global
+// nameservers, the MagicDNS on/off preference, search paths, and
+// per-domain split-DNS routes, closing the gap where an agent could
+// enumerate devices and ACLs but had no way to change how the tailnet
+// resolves names.
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// DNSInput defines the input schema for the dns tool. Only the fields the
+// chosen action uses are read; the rest are ignored.
+type DNSInput struct {
+	Action      string              `json:"action" description:"One of: get_nameservers, set_nameservers, get_preferences, set_preferences, get_searchpaths, set_searchpaths, get_split_dns, patch_split_dns"`
+	Nameservers []string            `json:"nameservers,omitempty" description:"set_nameservers: the tailnet's global DNS nameservers, each a literal IP address"`
+	MagicDNS    bool                `json:"magicDNS,omitempty" description:"set_preferences: whether MagicDNS should be enabled for the tailnet"`
+	SearchPaths []string            `json:"searchPaths,omitempty" description:"set_searchpaths: the tailnet's DNS search paths"`
+	SplitDNS    map[string][]string `json:"splitDNS,omitempty" description:"patch_split_dns: domain -> nameservers to route queries for it to; an empty/absent list for a domain already configured removes that domain's route"`
+}
+
+// DNSTool gets and updates the tailnet's DNS configuration.
+type DNSTool struct {
+	api    *tailscale.APIClient
+	client tailscale.Client
+}
+
+// NewDNSTool creates a new DNSTool. client, used only by set_preferences to
+// check whether the change has already propagated locally, may be nil (the
+// warning is then simply omitted).
+func NewDNSTool(api *tailscale.APIClient, client tailscale.Client) *DNSTool {
+	return &DNSTool{api: api, client: client}
+}
+
+// Name returns the name of the tool.
+func (t *DNSTool) Name() string {
+	return "dns"
+}
+
+// Description returns a description of the tool.
+func (t *DNSTool) Description() string {
+	return "Gets and updates the tailnet's DNS configuration: global nameservers, MagicDNS on/off, search paths, and per-domain split-DNS routes."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *DNSTool) InputSchema() any {
+	return DNSInput{}
+}
+
+// Execute runs the tool.
+func (t *DNSTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input DNSInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	switch input.Action {
+	case "get_nameservers":
+		return marshalResponse(t.api.GetDNSNameservers(ctx))
+	case "set_nameservers":
+		return marshalResponse(t.api.SetDNSNameservers(ctx, input.Nameservers))
+	case "get_preferences":
+		return marshalResponse(t.api.GetDNSPreferences(ctx))
+	case "set_preferences":
+		return t.setPreferences(ctx, input.MagicDNS)
+	case "get_searchpaths":
+		return marshalResponse(t.api.GetDNSSearchPaths(ctx))
+	case "set_searchpaths":
+		return marshalResponse(t.api.SetDNSSearchPaths(ctx, input.SearchPaths))
+	case "get_split_dns":
+		return marshalResponse(t.api.GetSplitDNS(ctx))
+	case "patch_split_dns":
+		return marshalResponse(t.api.PatchSplitDNS(ctx, input.SplitDNS))
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+}
+
+// setPreferences applies the MagicDNS on/off change, then, if t.client is
+// available, checks the local node's own status to warn the caller when it
+// hasn't picked up the change yet rather than letting them assume it's
+// immediate.
+func (t *DNSTool) setPreferences(ctx context.Context, magicDNS bool) (string, error) {
+	resp := t.api.SetDNSPreferences(ctx, magicDNS)
+	if !resp.Success {
+		return "", fmt.Errorf("failed to set DNS preferences: %s", resp.Error)
+	}
+
+	result := map[string]any{"preferences": resp.Data}
+
+	if t.client != nil {
+		if status, err := t.client.Status(ctx); err == nil && status != nil && status.CurrentTailnet != nil {
+			if status.CurrentTailnet.MagicDNSEnabled != magicDNS {
+				result["warning"] = "local node hasn't picked up this change yet; it may take a few minutes to propagate"
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(out), nil
+}
+
+// marshalResponse renders an APIResponse as the tool's string result,
+// erroring out if the call itself failed.
+func marshalResponse[T any](resp tailscale.APIResponse[T]) (string, error) {
+	if !resp.Success {
+		return "", fmt.Errorf("request failed: %s", resp.Error)
+	}
+	out, err := json.MarshalIndent(resp.Data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(out), nil
+}
+
+var _ toolapi.Tool = (*DNSTool)(nil)
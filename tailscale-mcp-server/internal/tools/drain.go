@@ -0,0 +1,85 @@
+// tailscale-mcp-server/internal/tools/drain.go
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// forceCancelGrace is how long Drain waits for a force-canceled call to
+// actually unwind (e.g. TailscaleCLI.ExecuteCommand's exec.Cmd.WaitDelay
+// forcing a SIGKILL) before giving up on it and returning anyway.
+const forceCancelGrace = 5 * time.Second
+
+// inFlightCall records one currently-running Tool.Execute invocation, so
+// Drain can report which tool blocked a shutdown and force-cancel it.
+type inFlightCall struct {
+	tool   string
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+// trackInFlight registers a running call and returns the ID to pass to
+// untrackInFlight once it finishes.
+func (r *ToolRegistry) trackInFlight(tool string, cancel context.CancelFunc) int64 {
+	r.wg.Add(1)
+
+	r.inFlightMu.Lock()
+	defer r.inFlightMu.Unlock()
+
+	r.nextCallID++
+	id := r.nextCallID
+	r.inFlight[id] = &inFlightCall{tool: tool, start: time.Now(), cancel: cancel}
+	return id
+}
+
+// untrackInFlight marks a call tracked via trackInFlight as finished.
+func (r *ToolRegistry) untrackInFlight(id int64) {
+	r.inFlightMu.Lock()
+	delete(r.inFlight, id)
+	r.inFlightMu.Unlock()
+
+	r.wg.Done()
+}
+
+// Drain stops ExecuteTool from accepting new tool calls, then waits up to
+// timeout for in-flight Tool.Execute calls to finish on their own. Survivors
+// past the deadline are logged individually with the tool name and how long
+// they'd been running, then have their per-call context force-canceled
+// (which e.g. unblocks TailscaleCLI.ExecuteCommand's exec.CommandContext,
+// killing the underlying process tree via killTree), so an operator can see
+// exactly which tool blocked shutdown instead of the process just hanging.
+func (r *ToolRegistry) Drain(timeout time.Duration) {
+	r.draining.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-time.After(timeout):
+	}
+
+	r.inFlightMu.Lock()
+	survivors := make([]*inFlightCall, 0, len(r.inFlight))
+	for _, c := range r.inFlight {
+		survivors = append(survivors, c)
+	}
+	r.inFlightMu.Unlock()
+
+	for _, c := range survivors {
+		r.logger.Warn("tool call still running at shutdown deadline, force-canceling",
+			"tool", c.tool, "running_for", time.Since(c.start).String())
+		c.cancel()
+	}
+
+	select {
+	case <-done:
+	case <-time.After(forceCancelGrace):
+		r.logger.Warn("tool calls did not unwind within the force-cancel grace period, proceeding with shutdown anyway")
+	}
+}
@@ -0,0 +1,317 @@
+// Package exitnode ranks candidate exit nodes by DERP latency and applies
+// the choice via the Tailscale CLI, mirroring upstream Tailscale's "auto
+// exit node" syspolicy.
+package exitnode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// defaultStickyMarginPercent is how much worse the current exit node's
+// latency may be than the best alternative before pick_exit_node switches
+// away from it, to avoid flapping between two close candidates.
+const defaultStickyMarginPercent = 30.0
+
+// offlinePenaltySeconds/unauthorizedPenaltySeconds bias ranking away from
+// candidates that are unreachable or not yet approved, without excluding
+// them outright (an operator can still force one via DeviceID).
+const (
+	offlinePenaltySeconds      = 10.0
+	unauthorizedPenaltySeconds = 5.0
+)
+
+// stateFileName is the state file's name, placed next to Config.LogFile.
+const stateFileName = "exit-node-state.json"
+
+// ExitNodeInput defines the input schema for the exit_node tool.
+type ExitNodeInput struct {
+	Action          string  `json:"action" description:"One of: list_exit_nodes, pick_exit_node, set_auto_exit_node"`
+	PreferredRegion string  `json:"preferredRegion,omitempty" description:"DERP region ID to rank candidates by latency to"`
+	DeviceID        string  `json:"deviceId,omitempty" description:"Force this device as the exit node instead of the computed best candidate (pick_exit_node)"`
+	Enabled         bool    `json:"enabled,omitempty" description:"Whether sticky auto-selection is enabled (set_auto_exit_node)"`
+	StickyMargin    float64 `json:"stickyMarginPercent,omitempty" description:"Override the sticky margin, default 30 (set_auto_exit_node)"`
+}
+
+// exitNodeState is persisted to statePath between runs so restarts don't
+// re-evaluate from a blank slate and churn the exit node.
+type exitNodeState struct {
+	AutoEnabled         bool      `json:"autoEnabled"`
+	StickyMarginPercent float64   `json:"stickyMarginPercent"`
+	CurrentDeviceID     string    `json:"currentDeviceId"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+// ExitNodeTool ranks devices advertising a default route (0.0.0.0/0 or
+// ::/0) by DERP latency to a preferred region, and applies the chosen one
+// via `tailscale set --exit-node`.
+type ExitNodeTool struct {
+	api       *tailscale.APIClient
+	cli       *tailscale.TailscaleCLI
+	statePath string
+
+	mu    sync.Mutex
+	state exitNodeState
+}
+
+// NewExitNodeTool creates a new ExitNodeTool. logFile is Config.LogFile;
+// state is persisted next to it, or under the working directory if empty.
+func NewExitNodeTool(api *tailscale.APIClient, cli *tailscale.TailscaleCLI, logFile string) *ExitNodeTool {
+	dir := "."
+	if logFile != "" {
+		dir = filepath.Dir(logFile)
+	}
+
+	t := &ExitNodeTool{
+		api:       api,
+		cli:       cli,
+		statePath: filepath.Join(dir, stateFileName),
+		state:     exitNodeState{StickyMarginPercent: defaultStickyMarginPercent},
+	}
+	t.loadState()
+	return t
+}
+
+// Name returns the name of the tool.
+func (t *ExitNodeTool) Name() string {
+	return "exit_node"
+}
+
+// Description returns a description of the tool.
+func (t *ExitNodeTool) Description() string {
+	return "Ranks candidate exit nodes by DERP latency and applies the best one via the Tailscale CLI, with a sticky mode to avoid flapping between close candidates."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *ExitNodeTool) InputSchema() any {
+	return ExitNodeInput{}
+}
+
+// Execute runs the tool.
+func (t *ExitNodeTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input ExitNodeInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	switch input.Action {
+	case "list_exit_nodes":
+		return t.listExitNodes(ctx, input.PreferredRegion)
+	case "pick_exit_node":
+		return t.pickExitNode(ctx, input.PreferredRegion, input.DeviceID)
+	case "set_auto_exit_node":
+		return t.setAutoExitNode(input.Enabled, input.StickyMargin)
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+}
+
+// candidate is a ranked exit node candidate.
+type candidate struct {
+	Device tailscale.Device `json:"-"`
+	ID     string           `json:"deviceId"`
+	Name   string           `json:"name"`
+	IP     string           `json:"ip"`
+	Score  float64          `json:"score"`
+}
+
+func (t *ExitNodeTool) rankCandidates(ctx context.Context, preferredRegion string) ([]candidate, error) {
+	resp := t.api.ListDevices(ctx)
+	if !resp.Success {
+		return nil, fmt.Errorf("failed to list devices: %s", resp.Error)
+	}
+
+	var candidates []candidate
+	for _, d := range resp.Data.Devices {
+		if !isExitNodeCandidate(d) {
+			continue
+		}
+		if len(d.Addresses) == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			Device: d,
+			ID:     d.ID,
+			Name:   d.Name,
+			IP:     d.Addresses[0],
+			Score:  scoreCandidate(d, preferredRegion),
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score < candidates[j].Score })
+	return candidates, nil
+}
+
+// isExitNodeCandidate reports whether d advertises a default route.
+func isExitNodeCandidate(d tailscale.Device) bool {
+	for _, r := range d.AdvertisedRoutes {
+		if r == "0.0.0.0/0" || r == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+// derpLatencySeconds averages d's reported latency to region across its
+// ClientConnectivity.Latency entries. ok is false if d has no data for it.
+func derpLatencySeconds(d tailscale.Device, region string) (seconds float64, ok bool) {
+	if d.ClientConnectivity == nil || region == "" {
+		return 0, false
+	}
+
+	var sum float64
+	var n int
+	for _, lat := range d.ClientConnectivity.Latency {
+		if v, exists := lat.DERPLatency[region]; exists {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// scoreCandidate ranks d for exit node selection: lower is better. Missing
+// latency data and the offline/unauthorized penalties are all expressed in
+// the same "seconds" unit as DERP latency, so they compose by addition.
+func scoreCandidate(d tailscale.Device, preferredRegion string) float64 {
+	latency, ok := derpLatencySeconds(d, preferredRegion)
+	if !ok {
+		latency = offlinePenaltySeconds
+	}
+	if !d.IsOnline() {
+		latency += offlinePenaltySeconds
+	}
+	if !d.Authorized {
+		latency += unauthorizedPenaltySeconds
+	}
+	return latency
+}
+
+func (t *ExitNodeTool) listExitNodes(ctx context.Context, preferredRegion string) (string, error) {
+	candidates, err := t.rankCandidates(ctx, preferredRegion)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(candidates, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal exit node candidates: %w", err)
+	}
+	return string(out), nil
+}
+
+func (t *ExitNodeTool) pickExitNode(ctx context.Context, preferredRegion, deviceID string) (string, error) {
+	candidates, err := t.rankCandidates(ctx, preferredRegion)
+	if err != nil {
+		return "", err
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no devices advertise a default route (0.0.0.0/0 or ::/0)")
+	}
+
+	chosen := candidates[0]
+	reason := "lowest latency"
+
+	if deviceID != "" {
+		found := false
+		for _, c := range candidates {
+			if c.ID == deviceID {
+				chosen = c
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("device %s is not an exit node candidate", deviceID)
+		}
+		reason = "forced by deviceId"
+	} else {
+		t.mu.Lock()
+		sticky := t.state.AutoEnabled
+		margin := t.state.StickyMarginPercent
+		current := t.state.CurrentDeviceID
+		t.mu.Unlock()
+
+		if sticky && current != "" {
+			for _, c := range candidates {
+				if c.ID == current && c.Score <= chosen.Score*(1+margin/100) {
+					chosen = c
+					reason = "sticky: within margin of best candidate"
+					break
+				}
+			}
+		}
+	}
+
+	if err := t.cli.SetExitNode(chosen.IP); err != nil {
+		return "", fmt.Errorf("failed to set exit node: %w", err)
+	}
+
+	t.mu.Lock()
+	t.state.CurrentDeviceID = chosen.ID
+	t.state.UpdatedAt = time.Now()
+	t.saveStateLocked()
+	t.mu.Unlock()
+
+	return fmt.Sprintf("Selected exit node %s (%s), score %.3f (%s).\n", chosen.Name, chosen.ID, chosen.Score, reason), nil
+}
+
+func (t *ExitNodeTool) setAutoExitNode(enabled bool, stickyMargin float64) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.state.AutoEnabled = enabled
+	if stickyMargin > 0 {
+		t.state.StickyMarginPercent = stickyMargin
+	}
+	t.state.UpdatedAt = time.Now()
+	if err := t.saveStateLocked(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Auto exit node selection: enabled=%t, stickyMarginPercent=%.1f\n", t.state.AutoEnabled, t.state.StickyMarginPercent), nil
+}
+
+// loadState reads statePath into t.state, leaving the defaults
+// NewExitNodeTool set (defaultStickyMarginPercent) in place if the file
+// doesn't exist yet or fails to parse.
+func (t *ExitNodeTool) loadState() {
+	data, err := os.ReadFile(t.statePath)
+	if err != nil {
+		return
+	}
+
+	var state exitNodeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		logger.Warn("exit_node: failed to parse state file, starting fresh", "path", t.statePath, "error", err)
+		return
+	}
+	t.state = state
+}
+
+// saveStateLocked writes t.state to statePath. Callers must hold t.mu.
+func (t *ExitNodeTool) saveStateLocked() error {
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal exit node state: %w", err)
+	}
+	if err := os.WriteFile(t.statePath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write exit node state file: %w", err)
+	}
+	return nil
+}
+
+var _ toolapi.Tool = (*ExitNodeTool)(nil)
@@ -0,0 +1,162 @@
+// Package login exposes an interactive (OIDC/browser) Tailscale login as a
+// pair of MCP tools: tailscale_login_start returns an AuthURL for an agent
+// to present to a human, and tailscale_login_poll waits for them to finish
+// it in a browser. This mirrors TailscaleCLI.LoginInteractive/PollLogin
+// one-to-one; the split exists because an MCP tool call can't itself block
+// for however long a human takes to authenticate.
+package login
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// sessions holds every LoginSession started by this process, keyed by
+// SessionID, so a later tailscale_login_poll call can find the session a
+// prior tailscale_login_start call created. It's shared by both tools in
+// this package rather than threaded through the registry, much as the
+// exit_node tool keeps its selection state next to the CLI calls it drives
+// (there in a field of the tool itself rather than a package variable).
+var sessions = struct {
+	mu sync.Mutex
+	m  map[string]*tailscale.LoginSession
+}{m: make(map[string]*tailscale.LoginSession)}
+
+func putSession(s *tailscale.LoginSession) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+	sessions.m[s.SessionID] = s
+}
+
+func getSession(id string) (*tailscale.LoginSession, bool) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+	s, ok := sessions.m[id]
+	return s, ok
+}
+
+func dropSession(id string) {
+	sessions.mu.Lock()
+	defer sessions.mu.Unlock()
+	delete(sessions.m, id)
+}
+
+// StartInput defines the input schema for the tailscale_login_start tool.
+type StartInput struct {
+	LoginServer string `json:"loginServer,omitempty" description:"Control server URL to authenticate against, e.g. a Headscale instance"`
+	Hostname    string `json:"hostname,omitempty" description:"Hostname to advertise to the control server"`
+	ForceReauth bool   `json:"forceReauth,omitempty" description:"Start a fresh login even if the node is already authenticated"`
+}
+
+// StartTool begins an interactive login and returns its AuthURL.
+type StartTool struct {
+	cli *tailscale.TailscaleCLI
+}
+
+// NewStartTool creates a new StartTool.
+func NewStartTool(cli *tailscale.TailscaleCLI) *StartTool {
+	return &StartTool{cli: cli}
+}
+
+// Name returns the name of the tool.
+func (t *StartTool) Name() string {
+	return "tailscale_login_start"
+}
+
+// Description returns a description of the tool.
+func (t *StartTool) Description() string {
+	return "Starts an interactive Tailscale login (OIDC or browser-based) and returns an AuthURL " +
+		"to present to a human, plus a sessionId to pass to tailscale_login_poll."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *StartTool) InputSchema() any {
+	return StartInput{}
+}
+
+// Execute runs the tool.
+func (t *StartTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input StartInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	session, err := t.cli.LoginInteractive(ctx, &tailscale.LoginOptions{
+		LoginServer: input.LoginServer,
+		Hostname:    input.Hostname,
+		ForceReauth: input.ForceReauth,
+	})
+	if err != nil {
+		return "", fmt.Errorf("starting interactive login: %w", err)
+	}
+	putSession(session)
+
+	return fmt.Sprintf(
+		"Visit this URL to finish logging in, then call tailscale_login_poll with sessionId %q:\n\n%s\n\n(expires %s)",
+		session.SessionID, session.AuthURL, session.Expiry.Format(time.RFC3339),
+	), nil
+}
+
+var _ toolapi.Tool = (*StartTool)(nil)
+
+// PollInput defines the input schema for the tailscale_login_poll tool.
+type PollInput struct {
+	SessionID string `json:"sessionId" description:"Session ID returned by tailscale_login_start"`
+}
+
+// PollTool waits for a session started by StartTool to complete.
+type PollTool struct {
+	cli *tailscale.TailscaleCLI
+}
+
+// NewPollTool creates a new PollTool.
+func NewPollTool(cli *tailscale.TailscaleCLI) *PollTool {
+	return &PollTool{cli: cli}
+}
+
+// Name returns the name of the tool.
+func (t *PollTool) Name() string {
+	return "tailscale_login_poll"
+}
+
+// Description returns a description of the tool.
+func (t *PollTool) Description() string {
+	return "Blocks until a login started by tailscale_login_start completes, or the tool call's own timeout elapses."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *PollTool) InputSchema() any {
+	return PollInput{}
+}
+
+// Execute runs the tool.
+func (t *PollTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input PollInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+	if input.SessionID == "" {
+		return "", fmt.Errorf("sessionId is required")
+	}
+
+	session, ok := getSession(input.SessionID)
+	if !ok {
+		return "", fmt.Errorf("unknown sessionId %q; call tailscale_login_start first", input.SessionID)
+	}
+
+	state, err := t.cli.PollLogin(ctx, session)
+	if err != nil {
+		return "", fmt.Errorf("polling login: %w", err)
+	}
+	dropSession(input.SessionID)
+
+	return fmt.Sprintf("login session %s reached state %s", input.SessionID, state), nil
+}
+
+var _ toolapi.Tool = (*PollTool)(nil)
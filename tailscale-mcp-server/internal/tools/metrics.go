@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// MetricsRegistry is the Prometheus registry backing HTTPServer's /metrics
+// endpoint: the tool-call and Tailscale-request collectors this package
+// owns, plus Register so individual tools can add their own collectors to
+// the same registry instead of each standing up a separate one.
+type MetricsRegistry struct {
+	registry *prometheus.Registry
+
+	toolCallsTotal           *prometheus.CounterVec
+	toolCallDuration         *prometheus.HistogramVec
+	tailscaleRequestDuration *prometheus.HistogramVec
+	tailscaleResilienceTotal *prometheus.CounterVec
+}
+
+// NewMetricsRegistry creates a MetricsRegistry with its built-in collectors
+// already registered, and installs itself as the tailscale package's
+// RequestObserver so every APIClient/TailscaleCLI call made through a
+// ToolRegistry built with it is reflected in
+// tailscale_request_duration_seconds.
+func NewMetricsRegistry() *MetricsRegistry {
+	m := &MetricsRegistry{
+		registry: prometheus.NewRegistry(),
+		toolCallsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_tool_calls_total",
+			Help: "Total number of MCP tool calls, by tool and result.",
+		}, []string{"tool", "result"}),
+		toolCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "mcp_tool_call_duration_seconds",
+			Help: "MCP tool call duration in seconds, by tool.",
+		}, []string{"tool"}),
+		tailscaleRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "tailscale_request_duration_seconds",
+			Help: "Tailscale API/CLI call duration in seconds, by backend and method.",
+		}, []string{"backend", "method"}),
+		tailscaleResilienceTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tailscale_resilience_events_total",
+			Help: "Retries, rate-limit waits, and circuit breaker transitions in the Tailscale API client, by backend and event.",
+		}, []string{"backend", "event"}),
+	}
+
+	m.registry.MustRegister(m.toolCallsTotal, m.toolCallDuration, m.tailscaleRequestDuration, m.tailscaleResilienceTotal)
+
+	tailscale.SetRequestObserver(func(backend, method string, duration time.Duration, success bool) {
+		m.tailscaleRequestDuration.WithLabelValues(backend, method).Observe(duration.Seconds())
+	})
+	tailscale.SetResilienceObserver(func(event tailscale.ResilienceEvent, backend string) {
+		m.tailscaleResilienceTotal.WithLabelValues(backend, string(event)).Inc()
+	})
+
+	return m
+}
+
+// Register adds collector to the registry, so a tool's own metrics are
+// exposed on the same /metrics endpoint as everything else.
+func (m *MetricsRegistry) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Gatherer returns the underlying prometheus.Gatherer, for promhttp to
+// serve HTTPServer's /metrics endpoint from.
+func (m *MetricsRegistry) Gatherer() prometheus.Gatherer {
+	return m.registry
+}
+
+func (m *MetricsRegistry) record(tool string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.toolCallsTotal.WithLabelValues(tool, result).Inc()
+	m.toolCallDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// metricsMiddleware records mcp_tool_calls_total and
+// mcp_tool_call_duration_seconds for every invocation into m.
+func metricsMiddleware(m *MetricsRegistry) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			start := time.Now()
+			result, err := next(ctx, name, args)
+			m.record(name, time.Since(start), err)
+			return result, err
+		}
+	}
+}
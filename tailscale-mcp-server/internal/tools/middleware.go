@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/ratelimit"
+)
+
+// ToolHandler executes a single tool invocation. ExecuteTool's own lookup-
+// and-execute step is the innermost ToolHandler; every ToolMiddleware wraps
+// it (or the next middleware) with cross-cutting behavior.
+type ToolHandler func(ctx context.Context, name string, args json.RawMessage) (string, error)
+
+// ToolMiddleware wraps a ToolHandler, mirroring the layered zapcore.Core
+// composition in internal/logger/sink.go: each middleware decides whether
+// and how to call next, so ordering in Use determines nesting (the first
+// middleware registered is outermost).
+type ToolMiddleware func(next ToolHandler) ToolHandler
+
+// Use appends mw to the registry's middleware chain and recomposes it once,
+// so ExecuteTool walks a prebuilt closure instead of re-composing on every
+// call.
+func (r *ToolRegistry) Use(mw ...ToolMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, mw...)
+	r.rebuildChainLocked()
+}
+
+// rebuildChainLocked recomposes r.chain from r.middlewares around
+// r.invokeTool. Callers must hold r.mu.
+func (r *ToolRegistry) rebuildChainLocked() {
+	handler := r.invokeTool
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+	r.chain = handler
+}
+
+// timeoutMiddleware bounds every tool invocation to d, so a single stuck
+// tool (a hung CLI call, an unresponsive API request) can't block the
+// dispatcher indefinitely.
+func timeoutMiddleware(d time.Duration) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, name, args)
+		}
+	}
+}
+
+// rateLimitMiddleware enforces limiter's token buckets per (caller ID, tool
+// name), reusing the same token-bucket implementation TailscaleCLI uses for
+// its command rate limits.
+func rateLimitMiddleware(limiter *ratelimit.Limiter) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			callerID := tailscale.CallerIDFromContext(ctx)
+			if !limiter.Allow(callerID, name) {
+				return "", fmt.Errorf("tool %q: rate limit exceeded", name)
+			}
+			return next(ctx, name, args)
+		}
+	}
+}
@@ -2,11 +2,21 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
 )
 
+// NetworkInput defines the input schema for the network tool.
+type NetworkInput struct {
+	Action string `json:"action" description:"Network action to perform: ping, routes, connectivity, ip"`
+	Target string `json:"target,omitempty" description:"Target host or IP for the ping action"`
+	Count  int    `json:"count,omitempty" description:"Number of ping packets to send (default 4)"`
+}
+
 // NetworkTool provides network management functionality
 type NetworkTool struct {
 	cli *tailscale.TailscaleCLI
@@ -28,48 +38,33 @@ func (n *NetworkTool) Name() string {
 
 // Description returns the tool description
 func (n *NetworkTool) Description() string {
-	return "Network operations including ping, connectivity tests, and route management"
+	return "Network operations including ping, connectivity tests, and route management. " +
+		"connectivity returns a JSON-encoded tailscale.ConnectivityReport; routes returns a " +
+		"JSON-encoded []tailscale.PeerRoutes."
 }
 
-// InputSchema returns the JSON schema for tool input
-func (n *NetworkTool) InputSchema() map[string]interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"action": map[string]interface{}{
-				"type":        "string",
-				"description": "Network action to perform",
-				"enum":        []string{"ping", "routes", "connectivity", "ip"},
-			},
-			"target": map[string]interface{}{
-				"type":        "string",
-				"description": "Target host or IP for network operations",
-			},
-			"count": map[string]interface{}{
-				"type":        "integer",
-				"description": "Number of ping packets to send",
-				"default":     4,
-			},
-		},
-		"required": []string{"action"},
-	}
+// InputSchema returns the input schema for the tool
+func (n *NetworkTool) InputSchema() any {
+	return NetworkInput{}
 }
 
-// Execute runs the network tool
-func (n *NetworkTool) Execute(ctx context.Context, input map[string]interface{}) (interface{}, error) {
-	action, ok := input["action"].(string)
-	if !ok {
-		return nil, fmt.Errorf("action is required and must be a string")
+// Execute runs the network tool. When the call carries a progress token
+// (see ProgressReporterFromContext), ping and connectivity stream a
+// notifications/progress update per reply line instead of only returning
+// once the underlying command finishes.
+func (n *NetworkTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input NetworkInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
 	}
 
-	switch action {
+	switch input.Action {
 	case "ping":
-		target, _ := input["target"].(string)
-		count, _ := input["count"].(float64)
+		count := input.Count
 		if count == 0 {
 			count = 4
 		}
-		return n.ping(ctx, target, int(count))
+		return n.ping(ctx, input.Target, count)
 	case "routes":
 		return n.getRoutes(ctx)
 	case "connectivity":
@@ -77,55 +72,88 @@ func (n *NetworkTool) Execute(ctx context.Context, input map[string]interface{})
 	case "ip":
 		return n.getIP(ctx)
 	default:
-		return nil, fmt.Errorf("unsupported action: %s", action)
+		return "", fmt.Errorf("unsupported action: %s", input.Action)
 	}
 }
 
-func (n *NetworkTool) ping(ctx context.Context, target string, count int) (interface{}, error) {
+// pingReplySeq matches the icmp_seq field of a `tailscale ping` reply line.
+var pingReplySeq = regexp.MustCompile(`icmp_seq=(\d+)`)
+
+func (n *NetworkTool) ping(ctx context.Context, target string, count int) (string, error) {
 	if target == "" {
-		return nil, fmt.Errorf("target is required for ping action")
+		return "", fmt.Errorf("target is required for ping action")
 	}
 
-	result, err := n.cli.Ping(target, count)
-	if err != nil {
-		return nil, fmt.Errorf("ping failed: %w", err)
+	onLine := func(line string) {}
+	if report, ok := ProgressReporterFromContext(ctx); ok {
+		total := float64(count)
+		onLine = func(line string) {
+			m := pingReplySeq.FindStringSubmatch(line)
+			if m == nil {
+				return
+			}
+			seq, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				return
+			}
+			_ = report(ctx, seq, total)
+		}
 	}
 
+	result, err := n.cli.PingStreaming(ctx, target, count, onLine)
+	if err != nil {
+		return "", fmt.Errorf("ping failed: %w", err)
+	}
 	return result, nil
 }
 
-func (n *NetworkTool) getRoutes(ctx context.Context) (interface{}, error) {
-	// Implementation for getting routes
-	result, err := n.cli.GetStatus()
+func (n *NetworkTool) getRoutes(ctx context.Context) (string, error) {
+	result, err := n.cli.GetRouteInfo(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get routes: %w", err)
+		return "", fmt.Errorf("failed to get routes: %w", err)
 	}
 
-	return map[string]interface{}{
-		"routes":  result,
-		"message": "Route information retrieved",
-	}, nil
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode route information: %w", err)
+	}
+	return string(data), nil
 }
 
-func (n *NetworkTool) testConnectivity(ctx context.Context) (interface{}, error) {
-	// Test basic connectivity using netcheck
-	result, err := n.cli.Netcheck()
+func (n *NetworkTool) testConnectivity(ctx context.Context) (string, error) {
+	onLine := func(line string) {}
+	if report, ok := ProgressReporterFromContext(ctx); ok {
+		var sample float64
+		onLine = func(line string) {
+			if line == "" {
+				return
+			}
+			sample++
+			// netcheck's total run length isn't known in advance, so total
+			// is left at 0 per notifications/progress's "indeterminate"
+			// convention (see ProgressReporter's doc comment).
+			_ = report(ctx, sample, 0)
+		}
+	}
+
+	result, err := n.cli.ConnectivityReport(ctx, onLine)
 	if err != nil {
-		return nil, fmt.Errorf("connectivity test failed: %w", err)
+		return "", fmt.Errorf("connectivity test failed: %w", err)
 	}
 
-	return map[string]interface{}{
-		"connected": true,
-		"result":    result,
-		"message":   "Connectivity test successful",
-	}, nil
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode connectivity report: %w", err)
+	}
+	return string(data), nil
 }
 
-func (n *NetworkTool) getIP(ctx context.Context) (interface{}, error) {
+func (n *NetworkTool) getIP(ctx context.Context) (string, error) {
 	result, err := n.cli.IP()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get IP: %w", err)
+		return "", fmt.Errorf("failed to get IP: %w", err)
 	}
-
 	return result, nil
 }
+
+var _ Tool = (*NetworkTool)(nil)
@@ -0,0 +1,152 @@
+// Package policy answers fine-grained authorization questions from parsed
+// ACL grants and live node capabilities, so MCP clients don't have to
+// re-parse HuJSON policy themselves to find e.g. which devices currently
+// hold a given capability.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tailscale/hujson"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// ACLGrant is a single "grants" rule from the tailnet ACL policy, the
+// mechanism (capver 100+) behind node capability grants.
+type ACLGrant struct {
+	Src []string                     `json:"src"`
+	Dst []string                     `json:"dst"`
+	IP  []string                     `json:"ip,omitempty"`
+	App map[string][]json.RawMessage `json:"app,omitempty"`
+}
+
+// aclGrantPolicy is the subset of the full ACL policy this package parses;
+// every other top-level key (acls, tagOwners, groups, ...) is ignored.
+type aclGrantPolicy struct {
+	Grants []ACLGrant `json:"grants"`
+}
+
+// PolicyInput defines the input schema for the policy tool.
+type PolicyInput struct {
+	Action     string `json:"action" description:"One of: list_grants_for_capability, devices_with_capability"`
+	Capability string `json:"capability" description:"Capability name, e.g. example.com/cap/database"`
+}
+
+// PolicyTool answers capability-scoped authorization queries against the
+// tailnet's current ACL grants and device capabilities.
+type PolicyTool struct {
+	api *tailscale.APIClient
+}
+
+// NewPolicyTool creates a new PolicyTool.
+func NewPolicyTool(api *tailscale.APIClient) *PolicyTool {
+	return &PolicyTool{api: api}
+}
+
+// Name returns the name of the tool.
+func (t *PolicyTool) Name() string {
+	return "policy"
+}
+
+// Description returns a description of the tool.
+func (t *PolicyTool) Description() string {
+	return "Answers capability-scoped authorization questions from the tailnet's ACL grants and devices' current node capabilities, without the caller having to parse HuJSON policy itself."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *PolicyTool) InputSchema() any {
+	return PolicyInput{}
+}
+
+// Execute runs the tool.
+func (t *PolicyTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input PolicyInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+	if input.Capability == "" {
+		return "", fmt.Errorf("capability is required")
+	}
+
+	switch input.Action {
+	case "list_grants_for_capability":
+		return t.listGrantsForCapability(ctx, input.Capability)
+	case "devices_with_capability":
+		return t.devicesWithCapability(ctx, input.Capability)
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+}
+
+// parseGrants fetches and parses the tailnet's ACL policy into its grants.
+func (t *PolicyTool) parseGrants(ctx context.Context) ([]ACLGrant, error) {
+	resp := t.api.GetACL(ctx)
+	if !resp.Success {
+		return nil, fmt.Errorf("failed to fetch ACL policy: %s", resp.Error)
+	}
+
+	std, err := hujson.Standardize([]byte(resp.Data.HuJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ACL policy: %w", err)
+	}
+
+	var policy aclGrantPolicy
+	if err := json.Unmarshal(std, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode ACL policy: %w", err)
+	}
+	return policy.Grants, nil
+}
+
+func (t *PolicyTool) listGrantsForCapability(ctx context.Context, capability string) (string, error) {
+	grants, err := t.parseGrants(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var matching []ACLGrant
+	for _, g := range grants {
+		if _, ok := g.App[capability]; ok {
+			matching = append(matching, g)
+		}
+	}
+
+	out, err := json.MarshalIndent(matching, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal grants: %w", err)
+	}
+	return string(out), nil
+}
+
+// deviceCapability names a device currently holding the queried capability,
+// along with its raw grant payload(s).
+type deviceCapability struct {
+	DeviceID string            `json:"deviceId"`
+	Name     string            `json:"name"`
+	Values   []json.RawMessage `json:"values"`
+}
+
+func (t *PolicyTool) devicesWithCapability(ctx context.Context, capability string) (string, error) {
+	resp := t.api.ListDevices(ctx)
+	if !resp.Success {
+		return "", fmt.Errorf("failed to list devices: %s", resp.Error)
+	}
+
+	var holders []deviceCapability
+	for _, d := range resp.Data.Devices {
+		if values, ok := d.Capabilities[capability]; ok {
+			holders = append(holders, deviceCapability{DeviceID: d.ID, Name: d.Name, Values: values})
+		}
+	}
+
+	out, err := json.MarshalIndent(holders, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal devices: %w", err)
+	}
+	return string(out), nil
+}
+
+var _ toolapi.Tool = (*PolicyTool)(nil)
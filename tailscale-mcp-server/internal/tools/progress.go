@@ -0,0 +1,30 @@
+// tailscale-mcp-server/internal/tools/progress.go
+package tools
+
+import "context"
+
+// ProgressReporter lets a Tool.Execute implementation report progress on a
+// long-running call (e.g. a multi-step `tailscale netcheck` run) back to
+// whatever transport the call arrived on. total is 0 when the operation's
+// length isn't known in advance, matching notifications/progress's own
+// optional total field.
+type ProgressReporter func(ctx context.Context, progress, total float64) error
+
+type progressReporterKey struct{}
+
+// WithProgressReporter attaches r to ctx. Only package mcp sets this, for a
+// CallTool request that carried a progress token; tools read it back via
+// ProgressReporterFromContext without needing to know about MCP's
+// notifications/progress wire format.
+func WithProgressReporter(ctx context.Context, r ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, r)
+}
+
+// ProgressReporterFromContext returns the reporter attached via
+// WithProgressReporter, or false if the caller never asked for progress
+// updates (no progress token on the request, or a transport that can't
+// deliver them).
+func ProgressReporterFromContext(ctx context.Context) (ProgressReporter, bool) {
+	r, ok := ctx.Value(progressReporterKey{}).(ProgressReporter)
+	return r, ok
+}
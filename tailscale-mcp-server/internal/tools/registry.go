@@ -5,39 +5,105 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale/ratelimit"
 	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/device"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/dns"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/exitnode"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/login"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/policy"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/routing"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/share"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/tailnetsettings"
 )
 
+// defaultToolTimeout bounds a single tool invocation when TS_MCP_TOOL_TIMEOUT
+// isn't set.
+const defaultToolTimeout = 30 * time.Second
+
 // ToolRegistry holds all registered tools with thread-safety and lifecycle management.
 type ToolRegistry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
-	api   *tailscale.APIClient
-	cli   *tailscale.TailscaleCLI
+	mu          sync.RWMutex
+	tools       map[string]Tool
+	api         *tailscale.APIClient
+	cli         *tailscale.TailscaleCLI
+	client      tailscale.Client
+	logFile     string
+	logger      logger.Service
+	middlewares []ToolMiddleware
+	chain       ToolHandler
+	metrics     *MetricsRegistry
+
+	draining   atomic.Bool
+	wg         sync.WaitGroup
+	inFlightMu sync.Mutex
+	inFlight   map[int64]*inFlightCall
+	nextCallID int64
 }
 
-// NewToolRegistry creates a new tool registry with the given clients.
-func NewToolRegistry(api *tailscale.APIClient, cli *tailscale.TailscaleCLI) *ToolRegistry {
+// NewToolRegistry creates a new tool registry with the given clients, logging
+// through svc instead of the package-level logger shims. logFile is
+// Config.LogFile, used only to place small per-tool state files (e.g. the
+// exit_node tool's last-selection state) next to the server's own log
+// file; it may be empty. ExecuteTool runs every invocation through a
+// default middleware chain (audit logging, metrics, a per-tool timeout,
+// and rate limiting); call Use to add more.
+func NewToolRegistry(api *tailscale.APIClient, cli *tailscale.TailscaleCLI, client tailscale.Client, logFile string, svc logger.Service) *ToolRegistry {
 	registry := &ToolRegistry{
-		tools: make(map[string]Tool),
-		api:   api,
-		cli:   cli,
+		tools:    make(map[string]Tool),
+		api:      api,
+		cli:      cli,
+		client:   client,
+		logFile:  logFile,
+		logger:   svc,
+		metrics:  NewMetricsRegistry(),
+		inFlight: make(map[int64]*inFlightCall),
 	}
 
 	// Register built-in tools
 	registry.registerBuiltinTools()
 
+	registry.Use(
+		auditLoggingMiddleware(NewLoggerAuditSink(svc)),
+		metricsMiddleware(registry.metrics),
+		timeoutMiddleware(toolTimeoutFromEnv()),
+		rateLimitMiddleware(ratelimit.NewLimiter(map[string]ratelimit.Rate{})),
+	)
+
 	return registry
 }
 
+// toolTimeoutFromEnv reads TS_MCP_TOOL_TIMEOUT (a time.ParseDuration string,
+// e.g. "45s"), falling back to defaultToolTimeout when unset or invalid.
+func toolTimeoutFromEnv() time.Duration {
+	if s := os.Getenv("TS_MCP_TOOL_TIMEOUT"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultToolTimeout
+}
+
 // registerBuiltinTools registers all the built-in tools
 func (r *ToolRegistry) registerBuiltinTools() {
 	// Device management tools
 	r.Register(device.NewListDevicesTool(r.api))
+	r.Register(device.NewManageDeviceTool(r.api))
+	r.Register(dns.NewDNSTool(r.api, r.client))
+	r.Register(NewNetworkTool(r.cli, r.api))
+	r.Register(routing.NewRoutingTool(r.api))
+	r.Register(exitnode.NewExitNodeTool(r.api, r.cli, r.logFile))
+	r.Register(policy.NewPolicyTool(r.api))
+	r.Register(tailnetsettings.NewSettingsTool(r.api))
+	r.Register(share.NewShareTool(r.cli))
+	r.Register(login.NewStartTool(r.cli))
+	r.Register(login.NewPollTool(r.cli))
 	// Add more tools here as they're implemented
 }
 
@@ -56,11 +122,11 @@ func (r *ToolRegistry) Register(tool Tool) error {
 	defer r.mu.Unlock()
 
 	if _, exists := r.tools[name]; exists {
-		logger.Warn("Overriding existing tool", "name", name)
+		r.logger.Warn("Overriding existing tool", "name", name)
 	}
 
 	r.tools[name] = tool
-	logger.Debug("Registered tool", "name", name, "description", tool.Description())
+	r.logger.Debug("Registered tool", "name", name, "description", tool.Description())
 	return nil
 }
 
@@ -105,33 +171,59 @@ func (r *ToolRegistry) Count() int {
 	return len(r.tools)
 }
 
-// ExecuteTool executes a tool with the given arguments.
+// Metrics returns the registry's Prometheus MetricsRegistry, so
+// HTTPServer's /metrics endpoint can serve it and individual tools can
+// register their own collectors via MetricsRegistry.Register.
+func (r *ToolRegistry) Metrics() *MetricsRegistry {
+	return r.metrics
+}
+
+// ExecuteTool executes a tool with the given arguments, running it through
+// the registry's middleware chain (see Use).
 func (r *ToolRegistry) ExecuteTool(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	r.mu.RLock()
+	chain := r.chain
+	r.mu.RUnlock()
+	return chain(ctx, name, args)
+}
+
+// invokeTool is the innermost ToolHandler: it looks name up and runs it.
+// It's wrapped by every middleware registered via Use to form r.chain. Every
+// call is tracked in r.wg/r.inFlight for the duration of tool.Execute, so
+// Drain can wait for it to finish on its own, or force-cancel it past a
+// shutdown deadline.
+func (r *ToolRegistry) invokeTool(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	if r.draining.Load() {
+		return "", fmt.Errorf("server is shutting down, not accepting new tool calls")
+	}
+
 	tool, ok := r.GetTool(name)
 	if !ok {
 		return "", fmt.Errorf("tool %q not found", name)
 	}
 
-	// Create context with registry and clients
-	toolCtx := NewContext(ctx, r)
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	id := r.trackInFlight(name, cancel)
+	defer r.untrackInFlight(id)
 
-	logger.Debug("Executing tool", "name", name)
+	toolCtx := NewContext(callCtx, r)
 	result, err := tool.Execute(toolCtx, args)
 	if err != nil {
-		logger.Error("Tool execution failed", "name", name, "error", err)
 		return "", fmt.Errorf("tool %q execution failed: %w", name, err)
 	}
-
-	logger.Debug("Tool executed successfully", "name", name)
 	return result, nil
 }
 
-// Close gracefully shuts down the registry and any resources.
+// Close gracefully shuts down the registry and any resources. Callers that
+// want in-flight Tool.Execute calls drained first should call Drain before
+// Close; Close itself no longer waits for them.
 func (r *ToolRegistry) Close() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	logger.Debug("Closing tool registry", "tool_count", len(r.tools))
+	r.logger.Debug("Closing tool registry", "tool_count", len(r.tools))
 
 	// Clear the tools map
 	r.tools = make(map[string]Tool)
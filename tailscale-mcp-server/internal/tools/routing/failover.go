@@ -0,0 +1,153 @@
+package routing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// unhealthyAfter is how long a primary router may go unseen before the
+// monitor considers it a failover candidate.
+const unhealthyAfter = 5 * time.Minute
+
+// FailoverMonitor periodically checks every HA route group's primary
+// device for health and, when enabled, promotes the next healthy standby
+// in its place. Health is judged by LastSeen alone (see deviceHealthy);
+// ClientConnectivity.Latency is not consulted.
+type FailoverMonitor struct {
+	api      *tailscale.APIClient
+	interval time.Duration
+	onEvent  func(tailscale.FailoverEvent)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewFailoverMonitor creates a FailoverMonitor that polls at interval. If
+// onEvent is non-nil, it's called for every automatic promotion the
+// monitor performs.
+func NewFailoverMonitor(api *tailscale.APIClient, interval time.Duration, onEvent func(tailscale.FailoverEvent)) *FailoverMonitor {
+	return &FailoverMonitor{api: api, interval: interval, onEvent: onEvent}
+}
+
+// Start begins polling in the background. It is safe to call more than
+// once; subsequent calls are no-ops while the monitor is already running.
+func (m *FailoverMonitor) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go m.run(runCtx)
+}
+
+// Stop halts polling and waits for the current tick, if any, to finish.
+// It is safe to call even if Start was never called.
+func (m *FailoverMonitor) Stop() {
+	m.mu.Lock()
+	cancel := m.cancel
+	done := m.done
+	m.cancel = nil
+	m.done = nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (m *FailoverMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.tick(ctx)
+		}
+	}
+}
+
+// tick checks every HA route group's primary and promotes a healthy
+// standby in its place if the primary looks unhealthy.
+func (m *FailoverMonitor) tick(ctx context.Context) {
+	resp := m.api.ListDevices(ctx)
+	if !resp.Success {
+		logger.Warn("failover monitor: failed to list devices", "error", resp.Error)
+		return
+	}
+
+	byID := make(map[string]tailscale.Device, len(resp.Data.Devices))
+	for _, d := range resp.Data.Devices {
+		byID[d.ID] = d
+	}
+
+	for _, group := range resp.Data.HARouteGroups() {
+		primary, ok := byID[group.PrimaryDeviceID]
+		if !ok || deviceHealthy(primary) {
+			continue
+		}
+
+		standby, ok := healthyStandby(group, byID)
+		if !ok {
+			logger.Warn("failover monitor: primary unhealthy but no healthy standby available",
+				"cidr", group.CIDR, "primary", group.PrimaryDeviceID)
+			continue
+		}
+
+		tool := NewRoutingTool(m.api)
+		if _, err := tool.promoteRoute(ctx, group.CIDR, standby.ID, "automatic failover: primary unhealthy"); err != nil {
+			logger.Error("failover monitor: automatic promotion failed",
+				"cidr", group.CIDR, "from", group.PrimaryDeviceID, "to", standby.ID, "error", err)
+			continue
+		}
+
+		event := tailscale.FailoverEvent{
+			CIDR:         group.CIDR,
+			FromDeviceID: group.PrimaryDeviceID,
+			ToDeviceID:   standby.ID,
+			Reason:       "primary unhealthy (stale LastSeen)",
+			Time:         time.Now(),
+		}
+		logger.Info("failover monitor: promoted standby router", "event", event)
+		if m.onEvent != nil {
+			m.onEvent(event)
+		}
+	}
+}
+
+// deviceHealthy reports whether d was seen recently enough to be trusted
+// as a primary subnet router.
+func deviceHealthy(d tailscale.Device) bool {
+	return time.Since(d.LastSeen) < unhealthyAfter
+}
+
+// healthyStandby returns the first device in group's DeviceIDs, other than
+// the current primary, that is healthy.
+func healthyStandby(group tailscale.HARouteGroup, byID map[string]tailscale.Device) (tailscale.Device, bool) {
+	for _, id := range group.DeviceIDs {
+		if id == group.PrimaryDeviceID {
+			continue
+		}
+		if d, ok := byID[id]; ok && deviceHealthy(d) {
+			return d, true
+		}
+	}
+	return tailscale.Device{}, false
+}
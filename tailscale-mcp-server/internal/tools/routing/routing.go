@@ -0,0 +1,204 @@
+// Package routing manages highly-available subnet routers: CIDRs
+// advertised by more than one device, where exactly one device should be
+// enabled as primary at a time.
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// RoutingInput defines the input schema for the routing tool.
+type RoutingInput struct {
+	Action   string `json:"action" description:"One of: list_ha_routes, promote_route, simulate_failover"`
+	CIDR     string `json:"cidr,omitempty" description:"The advertised route to act on (promote_route, simulate_failover)"`
+	DeviceID string `json:"deviceId,omitempty" description:"The device to promote to primary for cidr (promote_route)"`
+}
+
+// RoutingTool manages HA subnet router groups: listing redundant routers
+// for a CIDR and shifting primary duty between them.
+type RoutingTool struct {
+	api *tailscale.APIClient
+}
+
+// NewRoutingTool creates a new RoutingTool.
+func NewRoutingTool(api *tailscale.APIClient) *RoutingTool {
+	return &RoutingTool{api: api}
+}
+
+// Name returns the name of the tool.
+func (t *RoutingTool) Name() string {
+	return "routing"
+}
+
+// Description returns a description of the tool.
+func (t *RoutingTool) Description() string {
+	return "Lists and manages highly-available subnet router groups (CIDRs advertised by more than one device), promoting a standby router to primary by enabling its routes and disabling the previous primary's."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *RoutingTool) InputSchema() any {
+	return RoutingInput{}
+}
+
+// Execute runs the tool.
+func (t *RoutingTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input RoutingInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	switch input.Action {
+	case "list_ha_routes":
+		return t.listHARoutes(ctx)
+	case "promote_route":
+		return t.promoteRoute(ctx, input.CIDR, input.DeviceID, "manual promotion")
+	case "simulate_failover":
+		return t.simulateFailover(ctx, input.CIDR)
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+}
+
+func (t *RoutingTool) haRouteGroups(ctx context.Context) (tailscale.DeviceListResponse, []tailscale.HARouteGroup, error) {
+	resp := t.api.ListDevices(ctx)
+	if !resp.Success {
+		return tailscale.DeviceListResponse{}, nil, fmt.Errorf("failed to list devices: %s", resp.Error)
+	}
+	return resp.Data, resp.Data.HARouteGroups(), nil
+}
+
+func (t *RoutingTool) listHARoutes(ctx context.Context) (string, error) {
+	_, groups, err := t.haRouteGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal HA route groups: %w", err)
+	}
+	return string(out), nil
+}
+
+// promoteRoute enables cidr on deviceID and disables it on every other
+// device that currently has it enabled, making deviceID the sole primary.
+func (t *RoutingTool) promoteRoute(ctx context.Context, cidr, deviceID, reason string) (string, error) {
+	if cidr == "" {
+		return "", fmt.Errorf("cidr is required")
+	}
+	if deviceID == "" {
+		return "", fmt.Errorf("deviceId is required")
+	}
+
+	devices, groups, err := t.haRouteGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var group *tailscale.HARouteGroup
+	for i := range groups {
+		if groups[i].CIDR == cidr {
+			group = &groups[i]
+			break
+		}
+	}
+	if group == nil {
+		return "", fmt.Errorf("no HA route group advertises %s", cidr)
+	}
+
+	var promoted bool
+	for _, device := range devices.Devices {
+		if !containsString(device.AdvertisedRoutes, cidr) {
+			continue
+		}
+
+		wantEnabled := device.ID == deviceID
+		hasEnabled := containsString(device.EnabledRoutes, cidr)
+		if wantEnabled == hasEnabled {
+			if wantEnabled {
+				promoted = true
+			}
+			continue
+		}
+
+		routes := setRouteEnabled(device.EnabledRoutes, cidr, wantEnabled)
+		if resp := t.api.SetDeviceEnabledRoutes(ctx, device.ID, routes); !resp.Success {
+			return "", fmt.Errorf("failed to update enabled routes for %s: %s", device.ID, resp.Error)
+		}
+		if wantEnabled {
+			promoted = true
+		}
+	}
+
+	if !promoted {
+		return "", fmt.Errorf("device %s does not advertise %s", deviceID, cidr)
+	}
+
+	return fmt.Sprintf("Promoted %s to primary for %s (%s).\n", deviceID, cidr, reason), nil
+}
+
+// simulateFailover reports, without changing any routes (it only lists
+// devices), what promote_route would do if the current primary for cidr
+// were unhealthy: the next advertising device in the group that isn't
+// already primary.
+func (t *RoutingTool) simulateFailover(ctx context.Context, cidr string) (string, error) {
+	if cidr == "" {
+		return "", fmt.Errorf("cidr is required")
+	}
+
+	_, groups, err := t.haRouteGroups(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, group := range groups {
+		if group.CIDR != cidr {
+			continue
+		}
+
+		var candidate string
+		for _, id := range group.DeviceIDs {
+			if id != group.PrimaryDeviceID {
+				candidate = id
+				break
+			}
+		}
+		if candidate == "" {
+			return "", fmt.Errorf("no standby router available for %s", cidr)
+		}
+
+		return fmt.Sprintf("If %s became unhealthy, %s would be promoted to primary for %s.\n",
+			group.PrimaryDeviceID, candidate, cidr), nil
+	}
+
+	return "", fmt.Errorf("no HA route group advertises %s", cidr)
+}
+
+func setRouteEnabled(enabled []string, cidr string, want bool) []string {
+	var out []string
+	for _, r := range enabled {
+		if r != cidr {
+			out = append(out, r)
+		}
+	}
+	if want {
+		out = append(out, cidr)
+	}
+	return out
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+var _ toolapi.Tool = (*RoutingTool)(nil)
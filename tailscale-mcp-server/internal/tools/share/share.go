@@ -0,0 +1,136 @@
+// Package share exposes Taildrop file transfer and TailFS share management
+// through the MCP tool layer, the natural counterpart to the existing
+// peer/exit-node tools for agent-driven file-transfer workflows.
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// ShareInput defines the input schema for the share tool.
+type ShareInput struct {
+	Action   string `json:"action" description:"One of: send_file, receive_files, share_add, share_remove, share_rename, list_shares, set_file_server_addr"`
+	Target   string `json:"target,omitempty" description:"Destination node for send_file"`
+	Path     string `json:"path,omitempty" description:"Local file path (send_file) or directory to share (share_add)"`
+	Dir      string `json:"dir,omitempty" description:"Destination directory for receive_files"`
+	Name     string `json:"name,omitempty" description:"Share name (share_add, share_remove, share_rename)"`
+	NewName  string `json:"newName,omitempty" description:"New share name (share_rename)"`
+	ReadOnly bool   `json:"readOnly,omitempty" description:"Publish the share read-only (share_add)"`
+	Addr     string `json:"addr,omitempty" description:"File server address (set_file_server_addr)"`
+}
+
+// ShareTool wraps TailscaleCLI's Taildrop/TailFS operations.
+type ShareTool struct {
+	cli *tailscale.TailscaleCLI
+}
+
+// NewShareTool creates a new ShareTool.
+func NewShareTool(cli *tailscale.TailscaleCLI) *ShareTool {
+	return &ShareTool{cli: cli}
+}
+
+// Name returns the name of the tool.
+func (t *ShareTool) Name() string {
+	return "share"
+}
+
+// Description returns a description of the tool.
+func (t *ShareTool) Description() string {
+	return "Sends and receives files over Taildrop, and manages TailFS/Taildrive shares " +
+		"(add, remove, rename, list, set-file-server-addr)."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *ShareTool) InputSchema() any {
+	return ShareInput{}
+}
+
+// Execute runs the tool.
+func (t *ShareTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input ShareInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	switch input.Action {
+	case "send_file":
+		if input.Target == "" || input.Path == "" {
+			return "", fmt.Errorf("target and path are required for send_file")
+		}
+		if err := t.cli.SendFile(ctx, input.Target, input.Path); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("sent %q to %q", input.Path, input.Target), nil
+
+	case "receive_files":
+		if input.Dir == "" {
+			return "", fmt.Errorf("dir is required for receive_files")
+		}
+		files, err := t.cli.ReceiveFiles(ctx, input.Dir)
+		if err != nil {
+			return "", err
+		}
+		return marshalIndent(files)
+
+	case "share_add":
+		if input.Name == "" || input.Path == "" {
+			return "", fmt.Errorf("name and path are required for share_add")
+		}
+		if err := t.cli.ShareAdd(ctx, input.Name, input.Path, input.ReadOnly); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("shared %q as %q", input.Path, input.Name), nil
+
+	case "share_remove":
+		if input.Name == "" {
+			return "", fmt.Errorf("name is required for share_remove")
+		}
+		if err := t.cli.ShareRemove(ctx, input.Name); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("removed share %q", input.Name), nil
+
+	case "share_rename":
+		if input.Name == "" || input.NewName == "" {
+			return "", fmt.Errorf("name and newName are required for share_rename")
+		}
+		if err := t.cli.ShareRename(ctx, input.Name, input.NewName); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("renamed share %q to %q", input.Name, input.NewName), nil
+
+	case "list_shares":
+		shares, err := t.cli.ShareList(ctx)
+		if err != nil {
+			return "", err
+		}
+		return marshalIndent(shares)
+
+	case "set_file_server_addr":
+		if input.Addr == "" {
+			return "", fmt.Errorf("addr is required for set_file_server_addr")
+		}
+		if err := t.cli.SetFileServerAddr(ctx, input.Addr); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("set file server address to %q", input.Addr), nil
+
+	default:
+		return "", fmt.Errorf("unsupported action: %s", input.Action)
+	}
+}
+
+func marshalIndent(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(data), nil
+}
+
+var _ toolapi.Tool = (*ShareTool)(nil)
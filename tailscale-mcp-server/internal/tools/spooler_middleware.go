@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/audit"
+)
+
+// AuditSpoolerMiddleware spools a Record to spooler for every invocation,
+// attributing it to the request ID and client identity attached to ctx via
+// audit.WithRequestID/audit.WithClientInfo (the dispatcher sets these from
+// the jsonrpc2 request and the Conn's remembered InitializeRequest.ClientInfo
+// respectively). It's a separate, independent audit trail from the
+// LoggerAuditSink NewToolRegistry wires up by default: this one is meant for
+// NewSpooler's rotating JSON files, not the structured logger.
+func AuditSpoolerMiddleware(spooler *audit.Spooler) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, name string, args json.RawMessage) (string, error) {
+			start := time.Now()
+			result, err := next(ctx, name, args)
+
+			clientName, clientVersion := audit.ClientInfoFromContext(ctx)
+			rec := audit.Record{
+				Timestamp:     start,
+				RequestID:     audit.RequestIDFromContext(ctx),
+				Tool:          name,
+				Args:          args,
+				ResultSize:    len(result),
+				ClientName:    clientName,
+				ClientVersion: clientVersion,
+			}
+			if err != nil {
+				rec.Error = err.Error()
+			}
+			spooler.Record(rec)
+
+			return result, err
+		}
+	}
+}
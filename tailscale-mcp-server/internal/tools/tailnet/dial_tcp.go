@@ -0,0 +1,80 @@
+// Package tailnet provides MCP tools that only make sense when the server
+// embeds its own tsnet node, i.e. when it is itself a peer on the tailnet
+// rather than merely observing one through the CLI or LocalAPI.
+package tailnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tsnetnode"
+)
+
+const defaultDialTimeout = 10 * time.Second
+
+// DialTCPInput defines the input schema for the tailnet_dial_tcp tool.
+type DialTCPInput struct {
+	Address        string `json:"address" description:"Peer address to dial, as host:port (e.g. 100.64.0.5:22)"`
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty" description:"Dial timeout in seconds (default 10)"`
+}
+
+// DialTCPTool opens a TCP connection to a tailnet peer through the
+// embedded tsnet node, confirming reachability without routing through the
+// host's own network stack.
+type DialTCPTool struct {
+	Node *tsnetnode.Node
+}
+
+// NewDialTCPTool creates a new DialTCPTool.
+func NewDialTCPTool(node *tsnetnode.Node) *DialTCPTool {
+	return &DialTCPTool{Node: node}
+}
+
+// Name returns the name of the tool.
+func (t *DialTCPTool) Name() string {
+	return "tailnet_dial_tcp"
+}
+
+// Description returns a description of the tool.
+func (t *DialTCPTool) Description() string {
+	return "Opens a TCP connection to a tailnet peer through the embedded tsnet node and reports whether it succeeded."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *DialTCPTool) InputSchema() any {
+	return DialTCPInput{}
+}
+
+// Execute runs the tool.
+func (t *DialTCPTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input DialTCPInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	if input.Address == "" {
+		return "", fmt.Errorf("address is required")
+	}
+
+	timeout := defaultDialTimeout
+	if input.TimeoutSeconds > 0 {
+		timeout = time.Duration(input.TimeoutSeconds) * time.Second
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := t.Node.Dial(dialCtx, "tcp", input.Address)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial %s: %w", input.Address, err)
+	}
+	defer conn.Close()
+
+	return fmt.Sprintf("connected to %s (local %s) in %s", conn.RemoteAddr(), conn.LocalAddr(), time.Since(start)), nil
+}
+
+var _ toolapi.Tool = (*DialTCPTool)(nil)
@@ -0,0 +1,79 @@
+package tailnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tsnetnode"
+)
+
+// maxHTTPGetBody caps how much of a response body is returned to the
+// caller, to keep tool output bounded.
+const maxHTTPGetBody = 64 * 1024
+
+// HTTPGetInput defines the input schema for the tailnet_http_get tool.
+type HTTPGetInput struct {
+	URL string `json:"url" description:"URL reachable from inside the tailnet, e.g. http://peer:8080/health"`
+}
+
+// HTTPGetTool issues an HTTP GET through the embedded tsnet node's
+// http.Client, so it can reach tailnet-only peers.
+type HTTPGetTool struct {
+	Node *tsnetnode.Node
+}
+
+// NewHTTPGetTool creates a new HTTPGetTool.
+func NewHTTPGetTool(node *tsnetnode.Node) *HTTPGetTool {
+	return &HTTPGetTool{Node: node}
+}
+
+// Name returns the name of the tool.
+func (t *HTTPGetTool) Name() string {
+	return "tailnet_http_get"
+}
+
+// Description returns a description of the tool.
+func (t *HTTPGetTool) Description() string {
+	return "Issues an HTTP GET request to a URL reachable through the embedded tsnet node."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *HTTPGetTool) InputSchema() any {
+	return HTTPGetInput{}
+}
+
+// Execute runs the tool.
+func (t *HTTPGetTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input HTTPGetInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	if input.URL == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, input.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := t.Node.HTTPClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request to %s failed: %w", input.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPGetBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return fmt.Sprintf("%s\n\n%s", resp.Status, body), nil
+}
+
+var _ toolapi.Tool = (*HTTPGetTool)(nil)
@@ -0,0 +1,89 @@
+package tailnet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tsnetnode"
+)
+
+// ServeMCPInput defines the input schema for the tailnet_serve_mcp tool.
+type ServeMCPInput struct {
+	Addr string `json:"addr,omitempty" description:"host:port to listen on within the tailnet (default :443)"`
+}
+
+// ServeMCPTool advertises the MCP endpoint on the tailnet itself, so other
+// authorized peers can reach these tools directly. Reachability is gated
+// entirely by tailnet ACLs: only peers the ACL grants access to this node's
+// listening port can connect at all.
+type ServeMCPTool struct {
+	Node    *tsnetnode.Node
+	Handler http.Handler
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+// NewServeMCPTool creates a new ServeMCPTool. handler serves the same MCP
+// protocol the server already exposes over stdio/HTTP.
+func NewServeMCPTool(node *tsnetnode.Node, handler http.Handler) *ServeMCPTool {
+	return &ServeMCPTool{Node: node, Handler: handler}
+}
+
+// Name returns the name of the tool.
+func (t *ServeMCPTool) Name() string {
+	return "tailnet_serve_mcp"
+}
+
+// Description returns a description of the tool.
+func (t *ServeMCPTool) Description() string {
+	return "Advertises the MCP endpoint on the tailnet via the embedded node, reachable only by peers tailnet ACLs authorize."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *ServeMCPTool) InputSchema() any {
+	return ServeMCPInput{}
+}
+
+// Execute runs the tool.
+func (t *ServeMCPTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input ServeMCPInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.listener != nil {
+		return fmt.Sprintf("already serving MCP on the tailnet at %s", t.listener.Addr()), nil
+	}
+
+	addr := input.Addr
+	if addr == "" {
+		addr = ":443"
+	}
+
+	ln, err := t.Node.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on tailnet: %w", err)
+	}
+
+	t.listener = ln
+	go func() {
+		if err := http.Serve(ln, t.Handler); err != nil && !errors.Is(err, net.ErrClosed) {
+			logger.Error("tailnet MCP listener stopped", "error", err)
+		}
+	}()
+
+	return fmt.Sprintf("serving MCP endpoint on the tailnet at %s", ln.Addr()), nil
+}
+
+var _ toolapi.Tool = (*ServeMCPTool)(nil)
@@ -0,0 +1,77 @@
+package tailnet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tsnetnode"
+)
+
+// WhoIsInput defines the input schema for the tailnet_whois tool.
+type WhoIsInput struct {
+	Addr string `json:"addr" description:"Tailnet IP or host:port to resolve to a node/user identity"`
+}
+
+// WhoIsTool resolves a tailnet IP to node and user identity via the
+// embedded node's own LocalAPI.
+type WhoIsTool struct {
+	Node *tsnetnode.Node
+}
+
+// NewWhoIsTool creates a new WhoIsTool.
+func NewWhoIsTool(node *tsnetnode.Node) *WhoIsTool {
+	return &WhoIsTool{Node: node}
+}
+
+// Name returns the name of the tool.
+func (t *WhoIsTool) Name() string {
+	return "tailnet_whois"
+}
+
+// Description returns a description of the tool.
+func (t *WhoIsTool) Description() string {
+	return "Resolves a tailnet IP or host:port to the owning node and user via the embedded node's LocalAPI."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *WhoIsTool) InputSchema() any {
+	return WhoIsInput{}
+}
+
+// Execute runs the tool.
+func (t *WhoIsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input WhoIsInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	if input.Addr == "" {
+		return "", fmt.Errorf("addr is required")
+	}
+
+	lc, err := t.Node.LocalClient()
+	if err != nil {
+		return "", fmt.Errorf("failed to get embedded node's LocalAPI client: %w", err)
+	}
+
+	who, err := lc.WhoIs(ctx, input.Addr)
+	if err != nil {
+		return "", fmt.Errorf("whois failed for %s: %w", input.Addr, err)
+	}
+
+	nodeName := ""
+	if who.Node != nil {
+		nodeName = who.Node.ComputedName
+	}
+
+	loginName := ""
+	if who.UserProfile != nil {
+		loginName = who.UserProfile.LoginName
+	}
+
+	return fmt.Sprintf("%s belongs to node %q, user %q", input.Addr, nodeName, loginName), nil
+}
+
+var _ toolapi.Tool = (*WhoIsTool)(nil)
@@ -0,0 +1,112 @@
+// Package tailnetsettings exposes the tailnet-wide posture configuration
+// (device/user approval, key rotation, and related knobs) that applies
+// across the whole tailnet rather than to one device - closing the gap
+// where the server could enumerate and edit individual devices but had no
+// way to enforce tailnet-wide policy like "require admin approval" or
+// "rotate device keys every 90 days."
+package tailnetsettings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
+)
+
+// SettingsInput defines the input schema for the tailnet_settings tool.
+// The patch fields are only sent to UpdateTailnetSettings when action is
+// "update"; get ignores them.
+type SettingsInput struct {
+	Action                                  string  `json:"action" description:"One of: get, update"`
+	DevicesApprovalOn                       *bool   `json:"devicesApprovalOn,omitempty" description:"update: require admin approval for new devices"`
+	DevicesAutoUpdatesOn                    *bool   `json:"devicesAutoUpdatesOn,omitempty" description:"update: enable automatic Tailscale client updates tailnet-wide"`
+	DevicesKeyDurationDays                  *int    `json:"devicesKeyDurationDays,omitempty" description:"update: days before a device key expires"`
+	UsersApprovalOn                         *bool   `json:"usersApprovalOn,omitempty" description:"update: require admin approval for new users"`
+	UsersRoleAllowedToJoinExternalTailnets  *string `json:"usersRoleAllowedToJoinExternalTailnets,omitempty" description:"update: minimum role allowed to join external tailnets (e.g. none, member, admin)"`
+	PostureIdentityCollectionOn             *bool   `json:"postureIdentityCollectionOn,omitempty" description:"update: collect device posture identity information"`
+	RegionalRoutingOn                       *bool   `json:"regionalRoutingOn,omitempty" description:"update: enable regional routing"`
+	NetworkFlowLoggingOn                    *bool   `json:"networkFlowLoggingOn,omitempty" description:"update: enable network flow logging"`
+}
+
+// SettingsTool gets and updates the tailnet's posture configuration.
+type SettingsTool struct {
+	api *tailscale.APIClient
+}
+
+// NewSettingsTool creates a new SettingsTool.
+func NewSettingsTool(api *tailscale.APIClient) *SettingsTool {
+	return &SettingsTool{api: api}
+}
+
+// Name returns the name of the tool.
+func (t *SettingsTool) Name() string {
+	return "tailnet_settings"
+}
+
+// Description returns a description of the tool.
+func (t *SettingsTool) Description() string {
+	return "Gets and updates tailnet-wide posture settings: device/user approval, auto-updates, key expiry duration, posture identity collection, regional routing, and network flow logging."
+}
+
+// InputSchema returns the input schema for the tool.
+func (t *SettingsTool) InputSchema() any {
+	return SettingsInput{}
+}
+
+// Execute runs the tool.
+func (t *SettingsTool) Execute(ctx context.Context, args json.RawMessage) (string, error) {
+	var input SettingsInput
+	if err := json.Unmarshal(args, &input); err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %w", err)
+	}
+
+	switch input.Action {
+	case "get":
+		return t.getSettings(ctx)
+	case "update":
+		return t.updateSettings(ctx, input)
+	default:
+		return "", fmt.Errorf("unknown action %q", input.Action)
+	}
+}
+
+func (t *SettingsTool) getSettings(ctx context.Context) (string, error) {
+	resp := t.api.GetTailnetSettings(ctx)
+	if !resp.Success {
+		return "", fmt.Errorf("failed to get tailnet settings: %s", resp.Error)
+	}
+
+	out, err := json.MarshalIndent(resp.Data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tailnet settings: %w", err)
+	}
+	return string(out), nil
+}
+
+func (t *SettingsTool) updateSettings(ctx context.Context, input SettingsInput) (string, error) {
+	patch := tailscale.TailnetSettingsPatch{
+		DevicesApprovalOn:                      input.DevicesApprovalOn,
+		DevicesAutoUpdatesOn:                   input.DevicesAutoUpdatesOn,
+		DevicesKeyDurationDays:                 input.DevicesKeyDurationDays,
+		UsersApprovalOn:                        input.UsersApprovalOn,
+		UsersRoleAllowedToJoinExternalTailnets: input.UsersRoleAllowedToJoinExternalTailnets,
+		PostureIdentityCollectionOn:            input.PostureIdentityCollectionOn,
+		RegionalRoutingOn:                      input.RegionalRoutingOn,
+		NetworkFlowLoggingOn:                   input.NetworkFlowLoggingOn,
+	}
+
+	resp := t.api.UpdateTailnetSettings(ctx, patch)
+	if !resp.Success {
+		return "", fmt.Errorf("failed to update tailnet settings: %s", resp.Error)
+	}
+
+	out, err := json.MarshalIndent(resp.Data, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tailnet settings: %w", err)
+	}
+	return string(out), nil
+}
+
+var _ toolapi.Tool = (*SettingsTool)(nil)
@@ -0,0 +1,20 @@
+// Package toolapi defines the Tool interface every MCP tool implements. It
+// exists as its own leaf package, separate from internal/tools, so that
+// per-tool packages (internal/tools/device, internal/tools/dns, etc.) can
+// satisfy it without importing internal/tools itself, which in turn imports
+// every one of those packages to register them — importing internal/tools
+// from a tool package would be a cycle.
+package toolapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Tool defines the interface for all tools in the system.
+type Tool interface {
+	Name() string
+	Description() string
+	InputSchema() any
+	Execute(ctx context.Context, args json.RawMessage) (string, error)
+}
@@ -1,14 +1,9 @@
 package tools
 
-import (
-	"context"
-	"encoding/json"
-)
+import "github.com/hexsleeves/tailscale-mcp-server/internal/tools/toolapi"
 
-// Tool defines the interface for all tools in the system.
-type Tool interface {
-	Name() string
-	Description() string
-	InputSchema() any
-	Execute(ctx context.Context, args json.RawMessage) (string, error)
-}
+// Tool defines the interface for all tools in the system. It's an alias for
+// toolapi.Tool so existing code that refers to tools.Tool keeps working;
+// the interface itself lives in toolapi to avoid an import cycle between
+// internal/tools and the per-tool packages it registers.
+type Tool = toolapi.Tool
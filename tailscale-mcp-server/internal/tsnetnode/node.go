@@ -0,0 +1,130 @@
+// Package tsnetnode embeds a Tailscale node into the server process itself
+// via tsnet, so the MCP server can act as a peer on the tailnet rather than
+// only observing it through the local CLI or LocalAPI.
+package tsnetnode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"tailscale.com/client/tailscale"
+	"tailscale.com/tsnet"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+)
+
+// Options configures the embedded tsnet node.
+type Options struct {
+	// Hostname is the name this node advertises on the tailnet. Required.
+	Hostname string
+
+	// AuthKey, if set, is used to authenticate non-interactively. If empty,
+	// tsnet will print an interactive login URL to its logs on first run.
+	AuthKey string
+
+	// StateDir is where tsnet persists node state between restarts. If
+	// empty, tsnet picks a default under the user's state directory.
+	StateDir string
+
+	// ControlURL overrides the default Tailscale control plane, e.g. to
+	// point at a Headscale instance or a fake control server in tests.
+	ControlURL string
+
+	// Ephemeral marks the node for automatic removal from the tailnet once
+	// it disconnects; useful for test and CI nodes.
+	Ephemeral bool
+}
+
+// Node wraps a tsnet.Server with the lifecycle management (start once,
+// close once) the rest of the server expects.
+type Node struct {
+	mu      sync.Mutex
+	srv     *tsnet.Server
+	started bool
+}
+
+// New creates a Node from opts without starting it; call Start to bring the
+// node up on the tailnet.
+func New(opts Options) (*Node, error) {
+	if opts.Hostname == "" {
+		return nil, fmt.Errorf("tsnetnode: hostname is required")
+	}
+
+	srv := &tsnet.Server{
+		Hostname:   opts.Hostname,
+		AuthKey:    opts.AuthKey,
+		Dir:        opts.StateDir,
+		ControlURL: opts.ControlURL,
+		Ephemeral:  opts.Ephemeral,
+		Logf:       logger.Sugar().Debugf,
+	}
+
+	return &Node{srv: srv}, nil
+}
+
+// Start brings the node up on the tailnet. It is safe to call more than
+// once; subsequent calls are no-ops once the node is started.
+func (n *Node) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.started {
+		return nil
+	}
+
+	if err := n.srv.Start(); err != nil {
+		return fmt.Errorf("tsnetnode: failed to start tsnet server: %w", err)
+	}
+
+	if _, err := n.srv.Up(ctx); err != nil {
+		_ = n.srv.Close()
+		return fmt.Errorf("tsnetnode: node did not come up: %w", err)
+	}
+
+	n.started = true
+	logger.Info("tsnet node started", "hostname", n.srv.Hostname)
+	return nil
+}
+
+// Dial opens a connection to a tailnet peer, exactly like tsnet.Server.Dial.
+func (n *Node) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	return n.srv.Dial(ctx, network, addr)
+}
+
+// HTTPClient returns an *http.Client whose transport routes through this
+// node, so requests reach tailnet peers without touching the host's
+// default network stack.
+func (n *Node) HTTPClient() *http.Client {
+	return n.srv.HTTPClient()
+}
+
+// LocalClient returns a LocalAPI client scoped to this embedded node,
+// distinct from the host machine's own tailscaled LocalAPI.
+func (n *Node) LocalClient() (*tailscale.LocalClient, error) {
+	return n.srv.LocalClient()
+}
+
+// Listen opens a listener on the tailnet for this node, e.g. to advertise
+// the MCP endpoint to other authorized peers.
+func (n *Node) Listen(network, addr string) (net.Listener, error) {
+	return n.srv.Listen(network, addr)
+}
+
+// Close shuts down the node and releases its resources. Safe to call more
+// than once.
+func (n *Node) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !n.started {
+		return nil
+	}
+
+	logger.Info("shutting down tsnet node", "hostname", n.srv.Hostname)
+	err := n.srv.Close()
+	n.started = false
+	return err
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// authKeysData is authKeysTemplate's input.
+type authKeysData struct {
+	Mode      Mode
+	CSRFField template.HTML
+	Keys      []tailscale.AuthKey
+}
+
+func (s *Server) handleListAuthKeys(w http.ResponseWriter, r *http.Request) {
+	resp := s.api.ListAuthKeys(r.Context())
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+
+	data := authKeysData{
+		Mode:      s.mode,
+		CSRFField: csrf.TemplateField(r),
+		Keys:      resp.Data.Keys,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := authKeysTemplate.Execute(w, data); err != nil {
+		logger.Error("web: failed to render auth keys page", "error", err)
+	}
+}
+
+func (s *Server) handleCreateAuthKey(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := tailscale.AuthKeyRequest{
+		Description: r.FormValue("description"),
+		Capabilities: tailscale.AuthKeyCapabilities{
+			Devices: tailscale.AuthKeyDeviceCapabilities{
+				Create: tailscale.AuthKeyDeviceCreateCapabilities{
+					Reusable:      r.FormValue("reusable") == "true",
+					Ephemeral:     r.FormValue("ephemeral") == "true",
+					Preauthorized: r.FormValue("preauthorized") == "true",
+					Tags:          strings.Fields(r.FormValue("tags")),
+				},
+			},
+		},
+	}
+
+	resp := s.api.CreateAuthKey(r.Context(), req)
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/authkeys", http.StatusSeeOther)
+}
+
+func (s *Server) handleRevokeAuthKey(w http.ResponseWriter, r *http.Request) {
+	resp := s.api.RevokeAuthKey(r.Context(), mux.Vars(r)["id"])
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/authkeys", http.StatusSeeOther)
+}
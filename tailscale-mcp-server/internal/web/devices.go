@@ -0,0 +1,92 @@
+package web
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// dashboardData is dashboardTemplate's input.
+type dashboardData struct {
+	Mode       Mode
+	CSRFField  template.HTML
+	Devices    []tailscale.Device
+	Total      int
+	Online     int
+	Authorized int
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	resp := s.api.ListDevices(r.Context())
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+
+	data := dashboardData{
+		Mode:       s.mode,
+		CSRFField:  csrf.TemplateField(r),
+		Devices:    resp.Data.Devices,
+		Total:      resp.Data.Count(),
+		Online:     len(resp.Data.OnlineDevices()),
+		Authorized: len(resp.Data.AuthorizedDevices()),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		logger.Error("web: failed to render dashboard", "error", err)
+	}
+}
+
+func (s *Server) handleAuthorizeDevice(w http.ResponseWriter, r *http.Request) {
+	s.setDeviceAuthorized(w, r, true)
+}
+
+func (s *Server) handleDeauthorizeDevice(w http.ResponseWriter, r *http.Request) {
+	s.setDeviceAuthorized(w, r, false)
+}
+
+func (s *Server) setDeviceAuthorized(w http.ResponseWriter, r *http.Request, authorized bool) {
+	resp := s.api.SetDeviceAuthorized(r.Context(), mux.Vars(r)["id"], authorized)
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) handleSetDeviceTags(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tags := strings.Fields(r.FormValue("tags"))
+	resp := s.api.SetDeviceTags(r.Context(), mux.Vars(r)["id"], tags)
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) handleSetKeyExpiry(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	disabled := r.FormValue("disabled") == "true"
+	resp := s.api.SetDeviceKeyExpiryDisabled(r.Context(), mux.Vars(r)["id"], disabled)
+	if !resp.Success {
+		http.Error(w, resp.Error, http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
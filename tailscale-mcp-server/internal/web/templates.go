@@ -0,0 +1,81 @@
+package web
+
+import "html/template"
+
+// dashboardTemplate renders the device list, along with authorize/
+// deauthorize/tag/key-expiry forms when the server is running in Manage
+// mode (the template itself checks .Mode so ReadOnly never even renders
+// the forms, belt-and-suspenders alongside requireManage on the server).
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Tailscale MCP - Devices</title></head>
+<body>
+<h1>Devices</h1>
+<p>{{.Total}} total, {{.Online}} online, {{.Authorized}} authorized</p>
+<p><a href="/authkeys">Auth keys</a></p>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>ID</th><th>IP</th><th>Online</th><th>Authorized</th><th>Tags</th>{{if eq .Mode "manage"}}<th>Actions</th>{{end}}</tr>
+{{range .Devices}}
+<tr>
+  <td>{{.Name}}</td>
+  <td>{{.ID}}</td>
+  <td>{{range .Addresses}}{{.}} {{end}}</td>
+  <td>{{.IsOnline}}</td>
+  <td>{{.Authorized}}</td>
+  <td>{{range .Tags}}{{.}} {{end}}</td>
+  {{if eq $.Mode "manage"}}
+  <td>
+    {{if .Authorized}}
+    <form method="post" action="/devices/{{.ID}}/deauthorize" style="display:inline">{{$.CSRFField}}<button type="submit">Deauthorize</button></form>
+    {{else}}
+    <form method="post" action="/devices/{{.ID}}/authorize" style="display:inline">{{$.CSRFField}}<button type="submit">Authorize</button></form>
+    {{end}}
+    <form method="post" action="/devices/{{.ID}}/tags" style="display:inline">{{$.CSRFField}}<input name="tags" placeholder="tag:foo tag:bar"><button type="submit">Set tags</button></form>
+    <form method="post" action="/devices/{{.ID}}/key-expiry" style="display:inline">{{$.CSRFField}}<input type="hidden" name="disabled" value="true"><button type="submit">Disable key expiry</button></form>
+  </td>
+  {{end}}
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// authKeysTemplate renders the auth key list and, in Manage mode, a
+// creation form and per-key revoke buttons.
+var authKeysTemplate = template.Must(template.New("authkeys").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Tailscale MCP - Auth keys</title></head>
+<body>
+<h1>Auth keys</h1>
+<p><a href="/">Devices</a></p>
+<table border="1" cellpadding="4">
+<tr><th>Description</th><th>ID</th><th>Created</th><th>Expires</th><th>Revoked</th>{{if eq .Mode "manage"}}<th>Actions</th>{{end}}</tr>
+{{range .Keys}}
+<tr>
+  <td>{{.Description}}</td>
+  <td>{{.ID}}</td>
+  <td>{{.Created}}</td>
+  <td>{{.Expires}}</td>
+  <td>{{.Revoked}}</td>
+  {{if eq $.Mode "manage"}}
+  <td>{{if not .Revoked}}<form method="post" action="/authkeys/{{.ID}}/revoke">{{$.CSRFField}}<button type="submit">Revoke</button></form>{{end}}</td>
+  {{end}}
+</tr>
+{{end}}
+</table>
+{{if eq .Mode "manage"}}
+<h2>Create auth key</h2>
+<form method="post" action="/authkeys">
+  {{.CSRFField}}
+  Description: <input name="description">
+  Reusable: <input type="checkbox" name="reusable" value="true">
+  Ephemeral: <input type="checkbox" name="ephemeral" value="true">
+  Preauthorized: <input type="checkbox" name="preauthorized" value="true">
+  Tags: <input name="tags" placeholder="tag:foo tag:bar">
+  <button type="submit">Create</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
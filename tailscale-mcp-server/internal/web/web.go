@@ -0,0 +1,227 @@
+// Package web serves a minimal browser UI for interactive device and
+// auth-key management, backed by the same tailscale.APIClient the MCP
+// tools use. It has no tool-call semantics of its own; it's an
+// alternative, human-facing front end onto the same Tailscale API surface,
+// loosely following upstream Tailscale's own local web client (CSRF
+// tokens, a lightweight session cookie, read-only vs full management
+// modes).
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/logger"
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// Mode selects which routes a Server exposes.
+type Mode string
+
+const (
+	// ReadOnly serves only the dashboard and auth-key listing; every
+	// mutating route 403s.
+	ReadOnly Mode = "read-only"
+
+	// Manage additionally serves device authorize/deauthorize/tags/key-expiry
+	// and auth-key create/revoke routes.
+	Manage Mode = "manage"
+)
+
+// DefaultBind is the address Server listens on when Options.Bind is empty,
+// matching upstream Tailscale's own local web client's default port.
+const DefaultBind = "127.0.0.1:5252"
+
+// sessionCookieName names the lightweight session cookie Server sets on a
+// caller's first request. The cookie carries no server-side state of its
+// own, and nothing in this package reads its value back; gorilla/csrf keeps
+// its token in a separate cookie of its own.
+const sessionCookieName = "ts_mcp_web_session"
+
+// PeerIdentity is a caller identity resolved by an Authorizer, typically
+// from a tsnet node's LocalClient().WhoIs when the web UI is served over a
+// tsnet listener instead of loopback.
+type PeerIdentity struct {
+	LoginName string
+	NodeName  string
+}
+
+// Authorizer resolves the caller of r to a PeerIdentity, returning ok=false
+// if the caller could not be identified and the request should be denied.
+// A nil Authorizer (the loopback-bind default) admits every request without
+// attempting identification.
+type Authorizer func(r *http.Request) (identity PeerIdentity, ok bool)
+
+type identityContextKey struct{}
+
+// identityFromContext returns the PeerIdentity an Authorizer resolved for
+// this request, if any.
+func identityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	id, ok := ctx.Value(identityContextKey{}).(PeerIdentity)
+	return id, ok
+}
+
+// Options configures a Server.
+type Options struct {
+	// Bind is the address to listen on; defaults to DefaultBind. Ignored
+	// by Serve, which listens on the net.Listener passed to it instead
+	// (e.g. a tsnet node's tailnet-only socket).
+	Bind string
+
+	// Mode defaults to ReadOnly.
+	Mode Mode
+
+	// Authorizer, if set, gates every request on caller identity before
+	// Mode is even consulted. Intended for tsnet-backed deployments where
+	// the listener itself isn't restricted to localhost.
+	Authorizer Authorizer
+}
+
+// Server is the local web UI: a standalone http.Server with its own router,
+// CSRF protection, and session cookie, independent of the MCP transports.
+type Server struct {
+	api        *tailscale.APIClient
+	mode       Mode
+	authorizer Authorizer
+
+	httpServer *http.Server
+	router     *mux.Router
+}
+
+// NewServer creates a Server. It does not start listening; call Start or
+// Serve.
+func NewServer(api *tailscale.APIClient, opts Options) (*Server, error) {
+	if api == nil {
+		return nil, fmt.Errorf("web: api client is required")
+	}
+	if opts.Bind == "" {
+		opts.Bind = DefaultBind
+	}
+	if opts.Mode == "" {
+		opts.Mode = ReadOnly
+	}
+
+	csrfKey := make([]byte, 32)
+	if _, err := rand.Read(csrfKey); err != nil {
+		return nil, fmt.Errorf("web: generating CSRF key: %w", err)
+	}
+
+	s := &Server{
+		api:        api,
+		mode:       opts.Mode,
+		authorizer: opts.Authorizer,
+		router:     mux.NewRouter(),
+	}
+	s.setupRoutes()
+
+	// Secure(false) since the default bind is loopback-only plaintext HTTP;
+	// a deployment fronting this with TLS (or serving it over tsnet, which
+	// is itself WireGuard-encrypted) can be considered secure enough either
+	// way, so this is left permissive rather than requiring HTTPS.
+	protect := csrf.Protect(csrfKey, csrf.Secure(false), csrf.Path("/"))
+
+	s.httpServer = &http.Server{
+		Addr:         opts.Bind,
+		Handler:      s.sessionMiddleware(protect(s.router)),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	return s, nil
+}
+
+func (s *Server) setupRoutes() {
+	s.router.HandleFunc("/", s.handleDashboard).Methods(http.MethodGet)
+	s.router.HandleFunc("/devices/{id}/authorize", s.requireManage(s.handleAuthorizeDevice)).Methods(http.MethodPost)
+	s.router.HandleFunc("/devices/{id}/deauthorize", s.requireManage(s.handleDeauthorizeDevice)).Methods(http.MethodPost)
+	s.router.HandleFunc("/devices/{id}/tags", s.requireManage(s.handleSetDeviceTags)).Methods(http.MethodPost)
+	s.router.HandleFunc("/devices/{id}/key-expiry", s.requireManage(s.handleSetKeyExpiry)).Methods(http.MethodPost)
+	s.router.HandleFunc("/authkeys", s.handleListAuthKeys).Methods(http.MethodGet)
+	s.router.HandleFunc("/authkeys", s.requireManage(s.handleCreateAuthKey)).Methods(http.MethodPost)
+	s.router.HandleFunc("/authkeys/{id}/revoke", s.requireManage(s.handleRevokeAuthKey)).Methods(http.MethodPost)
+}
+
+// requireManage wraps h so it 403s unless the Server is running in Manage
+// mode.
+func (s *Server) requireManage(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.mode != Manage {
+			http.Error(w, "forbidden: server is running in read-only mode", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// sessionMiddleware resolves the caller's identity via s.authorizer (if
+// set), denying the request if identification fails, and ensures every
+// caller carries the lightweight session cookie.
+func (s *Server) sessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.authorizer != nil {
+			identity, ok := s.authorizer(r)
+			if !ok {
+				http.Error(w, "forbidden: could not identify tailnet peer", http.StatusForbidden)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity))
+		}
+
+		if _, err := r.Cookie(sessionCookieName); err != nil {
+			var raw [16]byte
+			if _, err := rand.Read(raw[:]); err == nil {
+				http.SetCookie(w, &http.Cookie{
+					Name:     sessionCookieName,
+					Value:    hex.EncodeToString(raw[:]),
+					Path:     "/",
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Start listens on Options.Bind until ctx is canceled.
+func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("web: failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve runs the web UI on ln instead of a listener Start creates itself,
+// e.g. a tsnet node's tailnet-only socket, until ctx is canceled.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	logger.Info("Starting web UI", "addr", ln.Addr(), "mode", s.mode)
+
+	errChan := make(chan error, 1)
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Web UI shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	case err := <-errChan:
+		logger.Error("Web UI error", "error", err)
+		return err
+	}
+}
@@ -0,0 +1,123 @@
+// Package webhooks verifies and parses inbound Tailscale webhook
+// deliveries (POST /tailnet/{tailnet}/webhooks event notifications), per
+// https://tailscale.com/api#description/webhooks. It has no knowledge of
+// MCP or the running server; internal/server wires a verified Event into a
+// notification, the same separation internal/tailscale (the API client)
+// keeps from internal/tools (what calls it).
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hexsleeves/tailscale-mcp-server/internal/tailscale"
+)
+
+// SignatureHeader is the HTTP header Tailscale signs every webhook delivery
+// with.
+const SignatureHeader = "Tailscale-Webhook-Signature"
+
+// maxTimestampSkew bounds how old a delivery's timestamp may be before
+// VerifySignature rejects it as a replay, per Tailscale's documented
+// webhook verification steps.
+const maxTimestampSkew = 5 * time.Minute
+
+// VerifySignature checks header (the SignatureHeader value of an inbound
+// request) against secret and body, per Tailscale's webhook spec: header is
+// "t=<unix-timestamp>,v1=<hex-hmac-sha256>", and the signed message is
+// "{timestamp}.{raw_body}". It rejects a missing/malformed header, a
+// signature mismatch, and a timestamp more than 5 minutes old (in either
+// direction), so a captured delivery can't be replayed later.
+func VerifySignature(secret, header string, body []byte) error {
+	if secret == "" {
+		return fmt.Errorf("no webhook secret configured")
+	}
+
+	ts, sig, err := parseSignatureHeader(header)
+	if err != nil {
+		return err
+	}
+
+	age := time.Since(time.Unix(ts, 0))
+	if age < 0 {
+		age = -age
+	}
+	if age > maxTimestampSkew {
+		return fmt.Errorf("webhook timestamp %d outside %s skew window", ts, maxTimestampSkew)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, body)
+	want := mac.Sum(nil)
+
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	if !hmac.Equal(want, got) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// parseSignatureHeader splits a "t=<ts>,v1=<hex>" SignatureHeader value
+// into its timestamp and v1 signature.
+func parseSignatureHeader(header string) (int64, string, error) {
+	var ts int64
+	var sig string
+	var haveTS, haveSig bool
+
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			parsed, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return 0, "", fmt.Errorf("parsing timestamp: %w", err)
+			}
+			ts, haveTS = parsed, true
+		case "v1":
+			sig, haveSig = kv[1], true
+		}
+	}
+
+	if !haveTS || !haveSig {
+		return 0, "", fmt.Errorf("malformed %s header", SignatureHeader)
+	}
+	return ts, sig, nil
+}
+
+// Event is one verified tailnet event off a webhook delivery.
+type Event struct {
+	Version   int                    `json:"version"`
+	Type      tailscale.Subscription `json:"type"`
+	Tailnet   string                 `json:"tailnet,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Message   string                 `json:"message,omitempty"`
+	Data      json.RawMessage        `json:"data,omitempty"`
+}
+
+// Payload is the JSON body of a webhook delivery: a batch of one or more
+// Events.
+type Payload struct {
+	Data []Event `json:"data"`
+}
+
+// ParsePayload decodes a webhook delivery's body. Callers should only parse
+// a body that has already passed VerifySignature.
+func ParsePayload(body []byte) (Payload, error) {
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return Payload{}, fmt.Errorf("decoding webhook payload: %w", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,109 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signFor builds a SignatureHeader value the same way Tailscale signs a
+// real delivery, so tests can construct valid deliveries without depending
+// on VerifySignature itself.
+func signFor(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, body)
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestVerifySignatureAccepts(t *testing.T) {
+	body := []byte(`{"data":[{"version":1}]}`)
+	header := signFor("shh", time.Now().Unix(), body)
+
+	assert.NoError(t, VerifySignature("shh", header, body))
+}
+
+func TestVerifySignatureNoSecretConfigured(t *testing.T) {
+	body := []byte(`{}`)
+	header := signFor("shh", time.Now().Unix(), body)
+
+	err := VerifySignature("", header, body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no webhook secret configured")
+}
+
+func TestVerifySignatureWrongSecret(t *testing.T) {
+	body := []byte(`{}`)
+	header := signFor("shh", time.Now().Unix(), body)
+
+	assert.Error(t, VerifySignature("a-different-secret", header, body))
+}
+
+func TestVerifySignatureBodyTampered(t *testing.T) {
+	body := []byte(`{"data":[{"version":1}]}`)
+	header := signFor("shh", time.Now().Unix(), body)
+
+	tampered := []byte(`{"data":[{"version":2}]}`)
+	assert.Error(t, VerifySignature("shh", header, tampered))
+}
+
+func TestVerifySignatureMalformedHeader(t *testing.T) {
+	body := []byte(`{}`)
+
+	for _, header := range []string{
+		"",
+		"garbage",
+		"t=not-a-number,v1=deadbeef",
+		"v1=deadbeef",
+		"t=123",
+	} {
+		err := VerifySignature("shh", header, body)
+		assert.Error(t, err, "header %q should be rejected", header)
+	}
+}
+
+func TestVerifySignatureInvalidHexSignature(t *testing.T) {
+	body := []byte(`{}`)
+	header := fmt.Sprintf("t=%d,v1=not-hex", time.Now().Unix())
+
+	assert.Error(t, VerifySignature("shh", header, body))
+}
+
+func TestVerifySignatureRejectsStaleTimestamp(t *testing.T) {
+	body := []byte(`{}`)
+	stale := time.Now().Add(-10 * time.Minute).Unix()
+	header := signFor("shh", stale, body)
+
+	err := VerifySignature("shh", header, body)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "skew window")
+}
+
+func TestVerifySignatureRejectsFutureTimestamp(t *testing.T) {
+	body := []byte(`{}`)
+	future := time.Now().Add(10 * time.Minute).Unix()
+	header := signFor("shh", future, body)
+
+	assert.Error(t, VerifySignature("shh", header, body))
+}
+
+func TestParsePayload(t *testing.T) {
+	body := []byte(`{"data":[{"version":1,"type":"nodeCreated","tailnet":"example.ts.net","message":"hi"}]}`)
+
+	payload, err := ParsePayload(body)
+	require.NoError(t, err)
+	require.Len(t, payload.Data, 1)
+	assert.Equal(t, 1, payload.Data[0].Version)
+	assert.Equal(t, "example.ts.net", payload.Data[0].Tailnet)
+	assert.Equal(t, "hi", payload.Data[0].Message)
+}
+
+func TestParsePayloadInvalidJSON(t *testing.T) {
+	_, err := ParsePayload([]byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Package schema holds the Go shapes this module parses `tailscale status
+// --json` and tailscaled's LocalAPI `/localapi/v0/status` response into.
+// Both transports return the same JSON shape (LocalAPI is in fact what the
+// CLI itself calls under the hood), so internal/tailscale's cliBackend and
+// localapiBackend share these types instead of each declaring their own.
+package schema
+
+import "encoding/json"
+
+// TailscaleStatus corresponds to the top-level object returned by
+//
+//	tailscale status --json
+//
+// and tailscaled's LocalAPI `/localapi/v0/status` endpoint. Only the fields
+// internal/tailscale and its tools actually read are included; see
+// ipnstate.Status in the upstream tailscale.com client for the full shape.
+type TailscaleStatus struct {
+	Version        string          `json:"Version,omitempty"`
+	BackendState   string          `json:"BackendState"`
+	AuthURL        string          `json:"AuthURL,omitempty"`
+	Self           *Peer           `json:"Self,omitempty"`
+	Peer           map[string]Peer `json:"Peer,omitempty"`
+	Health         []string        `json:"Health,omitempty"`
+	MagicDNSSuffix string          `json:"MagicDNSSuffix,omitempty"`
+	CurrentTailnet *CurrentTailnet `json:"CurrentTailnet,omitempty"`
+	User           map[string]User `json:"User,omitempty"`
+	ClientVersion  *ClientVersion  `json:"ClientVersion,omitempty"`
+}
+
+// Peer is one entry of TailscaleStatus.Peer (or Self), covering the fields
+// internal/tailscale reads off a node: identity, reachability, and whether
+// it's acting as the exit node.
+type Peer struct {
+	ID           string   `json:"ID"`
+	HostName     string   `json:"HostName"`
+	DNSName      string   `json:"DNSName,omitempty"`
+	TailscaleIPs []string `json:"TailscaleIPs,omitempty"`
+	Online       bool     `json:"Online"`
+	ExitNode     bool     `json:"ExitNode"`
+}
+
+// CurrentTailnet holds metadata about the tailnet the node belongs to.
+type CurrentTailnet struct {
+	Name            string `json:"Name"`
+	MagicDNSSuffix  string `json:"MagicDNSSuffix,omitempty"`
+	MagicDNSEnabled bool   `json:"MagicDNSEnabled"`
+}
+
+// User maps a numeric UserID to account information.
+type User struct {
+	ID          int64  `json:"ID"`
+	LoginName   string `json:"LoginName"`
+	DisplayName string `json:"DisplayName,omitempty"`
+}
+
+// ClientVersion tells whether the local client is current.
+type ClientVersion struct {
+	RunningLatest bool `json:"RunningLatest"`
+}
+
+// ParseSchema unmarshals raw JSON into a T, returning the zero value of T
+// and the decode error on failure.
+func ParseSchema[T any](raw string) (T, error) {
+	var dst T
+	if err := json.Unmarshal([]byte(raw), &dst); err != nil {
+		var zero T
+		return zero, err
+	}
+	return dst, nil
+}